@@ -2,85 +2,343 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/auth"
 	"fit-journal/internal/config"
+	exercise "fit-journal/internal/entities/exercise"
+	metric "fit-journal/internal/entities/metric"
 	user "fit-journal/internal/entities/user"
-	userDB "fit-journal/internal/entities/user/db"
 	workout "fit-journal/internal/entities/workout"
-	"fit-journal/internal/entities/workout/db"
+	"fit-journal/internal/erasure"
+	"fit-journal/internal/export"
+	"fit-journal/internal/health"
+	"fit-journal/internal/imports"
+	"fit-journal/internal/middleware"
+	"fit-journal/internal/servertls"
+	"fit-journal/pkg/client/mongodb"
 	"fit-journal/pkg/client/postgresql"
+	"fit-journal/pkg/client/sqlite"
 	"fit-journal/pkg/logging"
+	"fit-journal/pkg/metrics"
+	"fit-journal/pkg/tracing"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
 func main() {
+	// Код выхода задаётся после остановки сервера; os.Exit вызывается последним, чтобы отработали остальные defer
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
 	logger := logging.GetLogger()
+
+	args, err := config.ParseArgs(os.Args[1:])
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if args.Command == config.CommandPrint {
+		exitCode = printConfig(args)
+		return
+	}
+
 	logger.Info("Create router")
 	router := httprouter.New() // Необходимо инициализировать роутер
 
-	// Получение конфигурации
+	// Получение конфигурации: файл, окружение и флаги; некорректная конфигурация завершает процесс
 	cfg := config.GetConfig()
 
-	// Инициализация PostgreSQL клиента
-	logger.Info("Initialize PostgreSQL client")
-	ctx := context.Background()
-	pgClient, err := postgresql.NewClient(ctx, 3, cfg.Storage)
+	// Настраиваем логирование по конфигурации, до этого логгер пишет текст в stdout
+	if err := logging.Init(cfg.Logging.Options()); err != nil {
+		logger.Fatalf("Failed to initialize logging: %v", err)
+	}
+	logger = logging.GetLogger()
+
+	// Подробности ошибок (developer_message) отдаются клиентам только в режиме отладки
+	apperror.SetDebug(*cfg.IsDebug)
+
+	// Трассировка OpenTelemetry; при exporter: none спаны не записываются
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing.Options())
 	if err != nil {
-		logger.Fatalf("Failed to initialize PostgreSQL client: %v", err)
+		logger.Fatalf("Failed to initialize tracing: %v", err)
 	}
-	defer pgClient.Close()
+	defer shutdownTracing(context.Background())
+
+	// Контекст отменяется по SIGINT/SIGTERM и останавливает сервер и фоновые задачи
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Проверки живости и готовности для оркестратора
+	checker := health.NewChecker()
 
-	// Регистрируем репозиторий для пользователя
-	logger.Info("Initialize user repository")
-	userRepo := userDB.NewRepository(pgClient, logger)
+	// Хранилище: PostgreSQL, файл SQLite, MongoDB или память процесса (--storage=memory) для разработки
+	var repos repositories
+	switch strings.ToLower(cfg.Storage.Driver) {
+	case config.StorageMemory:
+		logger.Warn("Using in-memory storage, data will be lost on shutdown")
+		repos = memoryRepositories()
+	case config.StorageSQLite:
+		logger.Infof("Initialize SQLite database %s", cfg.Storage.Path)
+		sqliteDB, err := sqlite.NewClient(ctx, cfg.Storage.Path)
+		if err != nil {
+			logger.Fatalf("Failed to initialize SQLite database: %v", err)
+		}
+		defer sqliteDB.Close()
+		repos = sqliteRepositories(sqliteDB)
+
+		checker.Add("database", cfg.Health.DatabaseTimeout, sqliteDB.PingContext)
+		checker.Add("migrations", cfg.Health.SchemaTimeout, func(ctx context.Context) error {
+			return sqlite.CheckSchema(ctx, sqliteDB)
+		})
+	case config.StorageMongoDB:
+		logger.Info("Initialize MongoDB client")
+		mc := cfg.Storage.MongoDB
+		mongoDB, err := mongodb.NewClient(ctx, mc.Host, mc.Port, mc.Username, mc.Password, mc.Database, mc.AuthDB)
+		if err != nil {
+			logger.Fatalf("Failed to initialize MongoDB client: %v", err)
+		}
+		defer mongoDB.Client().Disconnect(context.Background())
+		repos = mongoRepositories(mongoDB)
+
+		checker.Add("database", cfg.Health.DatabaseTimeout, func(ctx context.Context) error {
+			return mongoDB.Client().Ping(ctx, nil)
+		})
+	default:
+		logger.Info("Initialize PostgreSQL client")
+		pool, err := postgresql.NewClient(ctx, 3, cfg.Storage)
+		if err != nil {
+			logger.Fatalf("Failed to initialize PostgreSQL client: %v", err)
+		}
+		defer pool.Close()
+		metrics.Registry.MustRegister(postgresql.NewPoolCollector(pool))
+		repos = postgresRepositories(postgresql.WithTracing(pool), cfg.Storage.TxMaxAttempts)
+
+		checker.Add("database", cfg.Health.DatabaseTimeout, pool.Ping)
+		checker.Add("migrations", cfg.Health.SchemaTimeout, func(ctx context.Context) error {
+			return postgresql.CheckSchema(ctx, pool)
+		})
+	}
+
+	// Фоновые задачи останавливаются вместе с ctx. Этот defer зарегистрирован после закрытия хранилища
+	// и выполняется раньше него: хранилище закрывается только после завершения фоновых задач.
+	var background sync.WaitGroup
+	defer func() {
+		stop()
+		background.Wait()
+	}()
+
+	// Выпуск и проверка токенов для всех обработчиков
+	authService := auth.NewService(auth.Options{
+		Secret:     []byte(cfg.JWTSecret),
+		TokenTTL:   cfg.Auth.TokenTTL,
+		BcryptCost: cfg.Auth.BcryptCost,
+	})
+
+	userRepo := repos.users
+	workoutRepo := repos.workouts
+	metricRepo := repos.metrics
+	importJobs := imports.NewJobStore(cfg.Imports)
+	importJobs.Start(ctx, &background, logger)
+
+	// Журнал аудита изменений пользователей, тренировок и метрик
+	auditRepo := repos.audit
+	auditRecorder := audit.NewRecorder(auditRepo)
+	audit.StartPurge(ctx, &background, auditRepo, logger, cfg.Audit.Retention, cfg.Audit.PurgeInterval)
+	audit.NewHandler(auditRepo, func(ctx context.Context, username string) (int64, error) {
+		u, err := userRepo.FindOne(ctx, username)
+		return u.ID, err
+	}, cfg.Admins, authService).Register(router)
+
+	// Полное удаление аккаунтов после срока ожидания
+	erasureService := erasure.NewService(repos.erasure, workoutRepo, metricRepo, auditRepo, logger, cfg.Erasure)
+	erasureService.OnErase(func(_ context.Context, userID int64) { importJobs.DeleteByUser(userID) })
+	erasureService.Start(ctx, &background)
+	erasure.NewHandler(erasureService, userRepo, authService).Register(router)
 
 	// Регистрируем хендлеры для пользователя
 	logger.Info("Register user handler")
-	userHandler := user.NewHandler(logger, userRepo)
+	userHandler := user.NewHandler(userRepo, repos.uow, erasureService, auditRecorder, authService)
 	userHandler.Register(router)
 
-	workoutRepo := db.NewRepository(pgClient, logger)
-	workoutHandler := workout.NewHandler(logger, workoutRepo, userRepo)
+	workoutHandler := workout.NewHandler(workoutRepo, userRepo, repos.uow, cfg.Trash.Retention, auditRecorder, authService)
 	workoutHandler.Register(router)
+	workout.StartTrashPurge(ctx, &background, workoutRepo, repos.uow, logger, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
+
+	exerciseRepo := repos.exercises
+	exerciseHandler := exercise.NewHandler(exerciseRepo, authService)
+	exerciseHandler.Register(router)
+
+	metricHandler := metric.NewHandler(metricRepo, userRepo, auditRecorder, authService)
+	metricHandler.Register(router)
+
+	importHandler := imports.NewHandler(workoutRepo, userRepo, exerciseRepo, metricRepo, repos.uow, importJobs, auditRecorder, authService)
+	importHandler.Register(router)
+
+	exportHandler := export.NewHandler(userRepo, workoutRepo, metricRepo, authService)
+	exportHandler.Register(router)
+
+	// Метрики Prometheus; при проверке клиентских сертификатов они доступны только сервисам
+	metricsHandler := metrics.Handler()
+	if cfg.TLS.Enabled && cfg.TLS.ClientAuth != servertls.ClientAuthNone {
+		metricsHandler = servertls.RequireService(metricsHandler)
+	}
+	router.Handler(http.MethodGet, "/metrics", metricsHandler)
+
+	checker.Add("config", time.Second, func(context.Context) error {
+		return cfg.Validate()
+	})
+	checker.Register(router)
+
+	// Неизвестные маршруты и методы отвечают так же, как остальные ошибки
+	router.NotFound = apperror.StatusHandler(http.StatusNotFound, "Resource not found")
+	router.MethodNotAllowed = apperror.StatusHandler(http.StatusMethodNotAllowed, "Method not allowed")
 
 	// Запускаем сервер
-	start(router, cfg)
+	if err := start(ctx, router, cfg, checker); err != nil {
+		logger.Errorf("Server error: %v", err)
+		exitCode = 1
+	}
+}
+
+// printConfig выводит итоговую конфигурацию (config print) и возвращает код выхода: 1, если она некорректна
+func printConfig(args config.Args) int {
+	cfg, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := config.Print(os.Stdout, cfg, args.Redacted); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+	return 0
 }
 
-func start(router *httprouter.Router, cfg *config.Config) {
+func start(ctx context.Context, router *httprouter.Router, cfg *config.Config, checker *health.Checker) error {
 	logger := logging.GetLogger()
 
 	var listener net.Listener
-	var listenerErr error
-
 	if cfg.Listen.Type == "sock" {
 		appDir, err := filepath.Abs(filepath.Dir(os.Args[0]))
 		if err != nil {
-			logger.Fatal(err)
+			return err
 		}
 		socketPath := path.Join(appDir, "app.sock")
-		listener, listenerErr = net.Listen("unix", socketPath)
+		if err := removeStaleSocket(socketPath); err != nil {
+			return err
+		}
+		if listener, err = net.Listen("unix", socketPath); err != nil {
+			return err
+		}
+		defer os.Remove(socketPath)
 		logger.Infof("Listening on socket: %s", socketPath)
 	} else {
-		listener, listenerErr = net.Listen("tcp", fmt.Sprintf("%s:%s", cfg.Listen.BindIP, cfg.Listen.Port))
+		var err error
+		if listener, err = net.Listen("tcp", fmt.Sprintf("%s:%s", cfg.Listen.BindIP, cfg.Listen.Port)); err != nil {
+			return err
+		}
 		logger.Infof("Start server %s:%s", cfg.Listen.BindIP, cfg.Listen.Port)
 	}
 
-	if listenerErr != nil {
-		logger.Fatal(listenerErr)
+	server := http.Server{
+		Handler: middleware.Chain(router,
+			middleware.RequestID,
+			middleware.Tracing(router),
+			middleware.AccessLog(router),
+			middleware.Metrics(router),
+			middleware.Recover,
+			servertls.Identity(cfg.TLS.AllowedServices),
+			// Загрузки импорта ограничены в обработчиках imports
+			middleware.MaxBodySize(cfg.Server.MaxBodyBytes, imports.UploadURLs...),
+		),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 
-	server := http.Server{
-		Handler:      router,
-		WriteTimeout: 15 * time.Second,
-		ReadTimeout:  15 * time.Second,
+	// TLS: сертификаты перечитываются при изменении файлов и по SIGHUP, HTTP/2 включается через ALPN
+	serve := server.Serve
+	if cfg.TLS.Enabled {
+		reloader, err := servertls.NewReloader(servertls.Options{
+			CertFile:     cfg.TLS.CertFile,
+			KeyFile:      cfg.TLS.KeyFile,
+			ClientCAFile: cfg.TLS.ClientCAFile,
+			ClientAuth:   cfg.TLS.ClientAuth,
+		}, logger)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+		reloader.Watch(ctx, cfg.TLS.ReloadInterval, hup)
+
+		server.TLSConfig = reloader.TLSConfig()
+		serve = func(l net.Listener) error { return server.ServeTLS(l, "", "") }
+		logger.Infof("TLS enabled, client auth: %s", cfg.TLS.ClientAuth)
 	}
 
-	logger.Fatal(server.Serve(listener))
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	// /readyz сразу начинает отвечать 503, новые запросы ещё принимаются, пока балансировщик это не заметит
+	logger.Info("Shutting down server")
+	checker.SetShuttingDown()
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Warnf("Requests did not finish within %s, closing connections: %v", cfg.Server.ShutdownTimeout, err)
+		server.Close()
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	logger.Info("Server stopped")
+	return nil
+}
+
+// removeStaleSocket удаляет сокет, оставшийся от аварийно завершённого процесса.
+// Если к сокету можно подключиться, значит, другой экземпляр ещё работает.
+func removeStaleSocket(socketPath string) error {
+	if _, err := os.Stat(socketPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	conn, err := net.DialTimeout("unix", socketPath, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is in use by another process", socketPath)
+	}
+	return os.Remove(socketPath)
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fit-journal/internal/audit"
+	auditDB "fit-journal/internal/audit/db"
+	auditMemory "fit-journal/internal/audit/memory"
+	auditMongo "fit-journal/internal/audit/mongodb"
+	auditSQLite "fit-journal/internal/audit/sqlite"
+	exercise "fit-journal/internal/entities/exercise"
+	exerciseDB "fit-journal/internal/entities/exercise/db"
+	exerciseMemory "fit-journal/internal/entities/exercise/memory"
+	exerciseMongo "fit-journal/internal/entities/exercise/mongodb"
+	exerciseSQLite "fit-journal/internal/entities/exercise/sqlite"
+	metric "fit-journal/internal/entities/metric"
+	metricDB "fit-journal/internal/entities/metric/db"
+	metricMemory "fit-journal/internal/entities/metric/memory"
+	metricMongo "fit-journal/internal/entities/metric/mongodb"
+	metricSQLite "fit-journal/internal/entities/metric/sqlite"
+	user "fit-journal/internal/entities/user"
+	userDB "fit-journal/internal/entities/user/db"
+	userMemory "fit-journal/internal/entities/user/memory"
+	userMongo "fit-journal/internal/entities/user/mongodb"
+	userSQLite "fit-journal/internal/entities/user/sqlite"
+	workout "fit-journal/internal/entities/workout"
+	"fit-journal/internal/entities/workout/db"
+	workoutMemory "fit-journal/internal/entities/workout/memory"
+	workoutMongo "fit-journal/internal/entities/workout/mongodb"
+	workoutSQLite "fit-journal/internal/entities/workout/sqlite"
+	"fit-journal/internal/erasure"
+	erasureDB "fit-journal/internal/erasure/db"
+	erasureMemory "fit-journal/internal/erasure/memory"
+	erasureMongo "fit-journal/internal/erasure/mongodb"
+	erasureSQLite "fit-journal/internal/erasure/sqlite"
+	"fit-journal/pkg/client/postgresql"
+	"fit-journal/pkg/client/sqlite"
+	"fit-journal/pkg/uow"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// repositories хранилища данных приложения, выбираются по storage.driver
+type repositories struct {
+	users     user.Repository
+	workouts  workout.Repository
+	metrics   metric.Repository
+	exercises exercise.Repository
+	erasure   erasure.Repository
+	audit     audit.Repository
+	uow       uow.UnitOfWork // Единица работы над этими же репозиториями
+}
+
+// postgresRepositories репозитории поверх PostgreSQL; txAttempts число попыток единицы работы при конфликтах
+func postgresRepositories(client postgresql.Client, txAttempts int) repositories {
+	unitOfWork := postgresql.NewUnitOfWork(client, txAttempts)
+	client = postgresql.WithContextTx(client)
+	return repositories{
+		users:     userDB.NewRepository(client),
+		workouts:  db.NewRepository(client),
+		metrics:   metricDB.NewRepository(client),
+		exercises: exerciseDB.NewRepository(client),
+		erasure:   erasureDB.NewRepository(client),
+		audit:     auditDB.NewRepository(client),
+		uow:       unitOfWork,
+	}
+}
+
+// sqliteRepositories репозитории поверх файла SQLite (--storage=sqlite)
+func sqliteRepositories(client sqlite.Client) repositories {
+	unitOfWork := sqlite.NewUnitOfWork(client)
+	client = sqlite.WithContextTx(client)
+	return repositories{
+		users:     userSQLite.NewRepository(client),
+		workouts:  workoutSQLite.NewRepository(client),
+		metrics:   metricSQLite.NewRepository(client),
+		exercises: exerciseSQLite.NewRepository(client),
+		erasure:   erasureSQLite.NewRepository(client),
+		audit:     auditSQLite.NewRepository(client),
+		uow:       unitOfWork,
+	}
+}
+
+// mongoRepositories репозитории поверх MongoDB (--storage=mongodb)
+func mongoRepositories(db *mongo.Database) repositories {
+	return repositories{
+		users:     userMongo.NewRepository(db),
+		workouts:  workoutMongo.NewRepository(db),
+		metrics:   metricMongo.NewRepository(db),
+		exercises: exerciseMongo.NewRepository(db),
+		erasure:   erasureMongo.NewRepository(db),
+		audit:     auditMongo.NewRepository(db),
+		uow:       uow.Serial(),
+	}
+}
+
+// memoryRepositories репозитории в памяти процесса (--storage=memory); данные теряются при остановке
+func memoryRepositories() repositories {
+	users := userMemory.NewRepository()
+	workouts := workoutMemory.NewRepository()
+	metrics := metricMemory.NewRepository()
+	auditLog := auditMemory.NewRepository()
+	return repositories{
+		users:     users,
+		workouts:  workouts,
+		metrics:   metrics,
+		exercises: exerciseMemory.NewRepository(),
+		erasure:   erasureMemory.NewRepository(users, workouts, metrics, auditLog),
+		audit:     auditLog,
+		uow:       uow.Serial(),
+	}
+}
@@ -1,12 +1,89 @@
 package apperror
 
-import "encoding/json"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
+// Доменные ошибки. Репозитории и сервисы возвращают их (или ошибки, созданные NotFound, Conflict
+// и Validation), а Write выбирает по ним статус ответа, проверяя цепочку через errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// domainError доменная ошибка со своим текстом
+type domainError struct {
+	kind    error
+	message string
+}
+
+func (e *domainError) Error() string { return e.message }
+
+func (e *domainError) Unwrap() error { return e.kind }
+
+// NotFound создаёт ошибку, которая errors.Is ErrNotFound
+func NotFound(format string, args ...interface{}) error {
+	return &domainError{kind: ErrNotFound, message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict создаёт ошибку, которая errors.Is ErrConflict
+func Conflict(format string, args ...interface{}) error {
+	return &domainError{kind: ErrConflict, message: fmt.Sprintf(format, args...)}
+}
+
+// Validation создаёт ошибку, которая errors.Is ErrValidation
+func Validation(format string, args ...interface{}) error {
+	return &domainError{kind: ErrValidation, message: fmt.Sprintf(format, args...)}
+}
+
+// FieldError нарушение в отдельном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors нарушения в полях запроса; errors.Is сопоставляет их с ErrValidation,
+// а Write выводит их в массиве errors ответа
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, f := range e {
+		parts[i] = f.Field + " " + f.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e ValidationErrors) Unwrap() error { return ErrValidation }
+
+// domainStatus возвращает статус ответа для доменной ошибки в цепочке err. Превышение лимита
+// тела запроса (http.MaxBytesReader) тоже относится к ним: его возвращает декодер JSON в обработчике.
+func domainStatus(err error) (int, bool) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge, true
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, true
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict, true
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest, true
+	}
+	return 0, false
+}
+
+// AppError ошибка обработчика со статусом ответа. Write выводит её в формате Problem: Message
+// становится detail, DeveloperMessage выводится только в режиме отладки, см. SetDebug.
 type AppError struct {
-	Err              error  `json:"-"`
-	Message          string `json:"message,omitempty"`
-	DeveloperMessage string `json:"developer_message,omitempty"`
-	StatusCode       int    `json:"-"`
+	Err              error
+	Message          string
+	DeveloperMessage string
+	StatusCode       int
 }
 
 func (e *AppError) Error() string {
@@ -15,14 +92,6 @@ func (e *AppError) Error() string {
 
 func (e *AppError) Unwrap() error { return e.Err }
 
-func (e *AppError) Marshal() []byte {
-	marshal, err := json.Marshal(e)
-	if err != nil {
-		return nil
-	}
-	return marshal
-}
-
 // NewAppError создает ошибку с автоматическим использованием стандартного статус-кода
 func NewAppError(err error, message, developerMessage string, statusCode int) *AppError {
 	// Если статус-код не передан (или 0), используем стандартный 500 Internal Server Error
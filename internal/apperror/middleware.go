@@ -2,42 +2,60 @@ package apperror
 
 import (
 	"errors"
+	"fit-journal/internal/requestid"
+	"fit-journal/pkg/logging"
 	"net/http"
 )
 
-// Определяем ошибку ErrNotFound
-var ErrNotFound = NewAppError(errors.New("not found"), "Resource not found", "The requested resource could not be found", http.StatusNotFound)
-
 // AppHandler — это тип обработчиков, которые возвращают ошибку
 type AppHandler func(w http.ResponseWriter, r *http.Request) error
 
 // Middleware оборачивает обработчик с возвратом ошибки в http.HandlerFunc
 func Middleware(h AppHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var appErr *AppError
-		err := h(w, r) // вызываем исходный обработчик
-
-		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-
-			// Если ошибка является кастомной (AppError)
-			if errors.As(err, &appErr) {
-				// Обработка 404 Not Found
-				if errors.Is(err, ErrNotFound) {
-					w.WriteHeader(http.StatusNotFound)
-					w.Write(ErrNotFound.Marshal())
-					return
-				}
+		if err := h(w, r); err != nil { // вызываем исходный обработчик
+			Write(w, r, err)
+		}
+	}
+}
 
-				// Обработка остальных кастомных ошибок
-				w.WriteHeader(appErr.StatusCode)
-				w.Write(appErr.Marshal())
-				return
-			}
+// Write пишет ошибку в ответ в формате Problem (application/problem+json) с идентификатором запроса
+// в instance. Статус определяется доменной ошибкой в цепочке err (ErrNotFound, ErrConflict,
+// ErrValidation), а без неё берётся из AppError.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	var appErr *AppError
+	status, isDomain := domainStatus(err)
+	switch {
+	case errors.As(err, &appErr):
+		// Копия, чтобы не менять общие значения ошибок
+		resp := *appErr
+		appErr = &resp
+		if isDomain {
+			appErr.StatusCode = status
+		}
+	case isDomain:
+		appErr = NewAppError(err, err.Error(), "", status)
+	default:
+		appErr = systemError(err)
+	}
 
-			// Для системных ошибок возвращаем 500 Internal Server Error
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write(systemError(err).Marshal())
+	// Серверные ошибки пишем в лог запроса
+	if appErr.StatusCode >= http.StatusInternalServerError {
+		msg := appErr.DeveloperMessage
+		if msg == "" {
+			msg = appErr.Message
 		}
+		logging.FromContext(r.Context()).GetLoggerWithField("status", appErr.StatusCode).WithError(err).Error(msg)
 	}
+
+	problem := newProblem(appErr, err)
+	problem.Instance = requestid.FromContext(r.Context())
+	writeProblem(w, problem)
+}
+
+// StatusHandler отвечает ошибкой со статусом status и сообщением message, например для router.NotFound
+func StatusHandler(status int, message string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, NewAppError(nil, message, "", status))
+	})
 }
@@ -0,0 +1,79 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+)
+
+// ProblemContentType тип ответа с ошибкой по RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase префикс type: URN не требует, чтобы по адресу была документация
+const problemTypeBase = "urn:fit-journal:problem:"
+
+// Problem тело ответа с ошибкой по RFC 7807
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"` // Идентификатор запроса, который можно указать в обращении в поддержку
+	Errors   []FieldError `json:"errors,omitempty"`
+	// DeveloperMessage подробности для разработчика, выводятся только в режиме отладки
+	DeveloperMessage string `json:"developer_message,omitempty"`
+}
+
+// problemTypes значения type для статусов ответа; остальные статусы получают about:blank
+var problemTypes = map[int]string{
+	http.StatusBadRequest:            "bad-request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not-found",
+	http.StatusMethodNotAllowed:      "method-not-allowed",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "payload-too-large",
+	http.StatusTooManyRequests:       "too-many-requests",
+	http.StatusInternalServerError:   "internal",
+}
+
+// debug включает developer_message в ответах, см. SetDebug
+var debug atomic.Bool
+
+// SetDebug включает вывод developer_message в ответах с ошибками (is_debug)
+func SetDebug(enabled bool) {
+	debug.Store(enabled)
+}
+
+// newProblem собирает Problem из ошибки обработчика; статус уже выбран в Write
+func newProblem(appErr *AppError, err error) Problem {
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(appErr.StatusCode),
+		Status: appErr.StatusCode,
+		Detail: appErr.Message,
+	}
+	if name, ok := problemTypes[appErr.StatusCode]; ok {
+		p.Type = problemTypeBase + name
+	}
+	var fields ValidationErrors
+	if errors.As(err, &fields) {
+		p.Type = problemTypeBase + "validation"
+		p.Errors = fields
+	} else if errors.Is(err, ErrValidation) {
+		p.Type = problemTypeBase + "validation"
+	}
+	if debug.Load() {
+		p.DeveloperMessage = appErr.DeveloperMessage
+	}
+	return p
+}
+
+// writeProblem пишет Problem в ответ
+func writeProblem(w http.ResponseWriter, p Problem) {
+	body, _ := json.Marshal(p)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	w.Write(body)
+}
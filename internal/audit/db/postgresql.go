@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"fit-journal/internal/audit"
+	"fit-journal/pkg/client/postgresql"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"strings"
+	"time"
+)
+
+type Repository struct {
+	client postgresql.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// Create добавляет запись в журнал
+func (r *Repository) Create(ctx context.Context, entry audit.Entry) error {
+	q := `
+		INSERT INTO audit_log
+			(at, actor, owner_id, resource, resource_id, action, changes, request_id)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	_, err := r.client.Exec(ctx, q, entry.At.UnixMilli(), entry.Actor, entry.OwnerID, entry.Resource, entry.ResourceID,
+		string(entry.Action), entry.Changes, entry.RequestID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s",
+				pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return newErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Find возвращает записи журнала по фильтру, новые первыми
+func (r *Repository) Find(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	conditions := make([]string, 0, 4)
+	args := make([]interface{}, 0, 5)
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.OwnerID != 0 {
+		add("owner_id = $%d", filter.OwnerID)
+	}
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.Resource != "" {
+		add("resource = $%d", filter.Resource)
+	}
+	if !filter.Since.IsZero() {
+		add("at >= $%d", filter.Since.UnixMilli())
+	}
+
+	q := `SELECT id, at, actor, owner_id, resource, resource_id, action, changes, request_id FROM audit_log`
+	if len(conditions) > 0 {
+		q += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	q += ` ORDER BY at DESC, id DESC`
+	// Нулевой Limit не ограничивает выборку
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		q += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]audit.Entry, 0)
+	for rows.Next() {
+		var e audit.Entry
+		var at int64
+		if err := rows.Scan(&e.ID, &at, &e.Actor, &e.OwnerID, &e.Resource, &e.ResourceID, &e.Action, &e.Changes, &e.RequestID); err != nil {
+			return nil, err
+		}
+		e.At = time.UnixMilli(at)
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// PurgeBefore удаляет записи старше before
+func (r *Repository) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	q := `
+		DELETE FROM audit_log WHERE at < $1
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	tag, err := r.client.Exec(ctx, q, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client postgresql.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
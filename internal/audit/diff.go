@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// redacted значение поля, которое нельзя сохранять в журнале
+var redacted = json.RawMessage(`"[redacted]"`)
+
+// RedactedChange отмечает изменение поля без сохранения его значений, например пароля
+func RedactedChange(field string) Change {
+	return Change{Field: field, Before: redacted, After: redacted}
+}
+
+// Snapshot фиксирует состояние ресурса в JSON до его изменения на месте
+func Snapshot(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// fields разбирает JSON-объект ресурса на поля верхнего уровня
+func fields(v interface{}) map[string]json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, ok := v.(json.RawMessage)
+	if !ok {
+		data = Snapshot(v)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// Diff сравнивает JSON-представления ресурса до и после изменения по полям верхнего уровня.
+// before или after равны nil при создании и удалении соответственно.
+func Diff(before, after interface{}) []Change {
+	b, a := fields(before), fields(after)
+
+	names := make([]string, 0, len(b)+len(a))
+	for name := range b {
+		names = append(names, name)
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]Change, 0)
+	for _, name := range names {
+		if bytes.Equal(b[name], a[name]) {
+			continue
+		}
+		changes = append(changes, Change{Field: name, Before: b[name], After: a[name]})
+	}
+	return changes
+}
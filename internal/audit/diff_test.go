@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type resource struct {
+	Name   string   `json:"name"`
+	Weight float64  `json:"weight,omitempty"`
+	Tags   []string `json:"tags"`
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after interface{}
+		want          []Change
+	}{
+		{"create", nil, resource{Name: "Squat", Tags: []string{}}, []Change{
+			{Field: "name", After: json.RawMessage(`"Squat"`)},
+			{Field: "tags", After: json.RawMessage(`[]`)},
+		}},
+		{"delete", resource{Name: "Squat"}, nil, []Change{
+			{Field: "name", Before: json.RawMessage(`"Squat"`)},
+			{Field: "tags", Before: json.RawMessage(`null`)},
+		}},
+		{"unchanged", resource{Name: "Squat"}, resource{Name: "Squat"}, []Change{}},
+		// Поле, пропущенное из-за omitempty, считается отсутствующим
+		{"field appears", resource{Name: "Squat"}, resource{Name: "Squat", Weight: 100}, []Change{
+			{Field: "weight", After: json.RawMessage(`100`)},
+		}},
+		// Вложенные значения сравниваются целиком
+		{"nested change", resource{Tags: []string{"legs"}}, resource{Tags: []string{"legs", "heavy"}}, []Change{
+			{Field: "tags", Before: json.RawMessage(`["legs"]`), After: json.RawMessage(`["legs","heavy"]`)},
+		}},
+		// Снимок до изменения на месте сравнивается с изменённым значением
+		{"snapshot", Snapshot(resource{Name: "Squat"}), resource{Name: "Front squat"}, []Change{
+			{Field: "name", Before: json.RawMessage(`"Squat"`), After: json.RawMessage(`"Front squat"`)},
+		}},
+		{"not an object", json.RawMessage(`[1]`), nil, []Change{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff = %s, want %s", Snapshot(got), Snapshot(tt.want))
+			}
+		})
+	}
+}
+
+func TestRedactedChange(t *testing.T) {
+	c := RedactedChange("password")
+	if c.Field != "password" || string(c.Before) != `"[redacted]"` || string(c.After) != `"[redacted]"` {
+		t.Errorf("RedactedChange = %s", Snapshot(c))
+	}
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/auth"
+	"fit-journal/internal/handlers"
+	"fit-journal/pkg/logging"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	auditURL      = "/audit"
+	adminAuditURL = "/admin/audit"
+
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// UserResolver возвращает ID пользователя по имени
+type UserResolver func(ctx context.Context, username string) (int64, error)
+
+type handler struct {
+	repository Repository
+	resolve    UserResolver
+	admins     map[string]bool
+	auth       *auth.Service
+}
+
+func NewHandler(repo Repository, resolve UserResolver, admins []string, authService *auth.Service) handlers.Handler {
+	h := &handler{
+		repository: repo,
+		resolve:    resolve,
+		admins:     make(map[string]bool, len(admins)),
+		auth:       authService,
+	}
+	for _, name := range admins {
+		h.admins[name] = true
+	}
+	return h
+}
+
+func (h *handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, auditURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.GetOwnEntries)))
+	router.HandlerFunc(http.MethodGet, adminAuditURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.GetEntries)))
+}
+
+// parseFilter читает параметры resource, since (RFC 3339) и limit
+func parseFilter(r *http.Request) (Filter, error) {
+	query := r.URL.Query()
+	filter := Filter{Resource: query.Get("resource"), Limit: defaultLimit}
+
+	if value := query.Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return Filter{}, apperror.NewAppError(err, "Invalid since parameter", "since must be an RFC 3339 timestamp", http.StatusBadRequest)
+		}
+		filter.Since = since
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return Filter{}, apperror.NewAppError(err, "Invalid limit parameter", "limit must be a positive integer", http.StatusBadRequest)
+		}
+		filter.Limit = min(limit, maxLimit)
+	}
+	return filter, nil
+}
+
+func (h *handler) writeEntries(w http.ResponseWriter, r *http.Request, filter Filter) error {
+	entries, err := h.repository.Find(r.Context(), filter)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to fetch audit log", "", http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(entries)
+}
+
+// GetOwnEntries возвращает изменения данных текущего пользователя, новые первыми
+func (h *handler) GetOwnEntries(w http.ResponseWriter, r *http.Request) error {
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		return apperror.NewAppError(nil, "Invalid or missing username", "", http.StatusUnauthorized)
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	if filter.OwnerID, err = h.resolve(r.Context(), username); err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "User not found", "", http.StatusInternalServerError)
+	}
+
+	return h.writeEntries(w, r, filter)
+}
+
+// GetEntries возвращает журнал всех пользователей. Доступно только администраторам из конфигурации.
+// Дополнительные параметры: owner_id и actor.
+func (h *handler) GetEntries(w http.ResponseWriter, r *http.Request) error {
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		return apperror.NewAppError(nil, "Invalid or missing username", "", http.StatusUnauthorized)
+	}
+	if !h.admins[username] {
+		return apperror.NewAppError(nil, "Forbidden", "audit log of other users is available to admins only", http.StatusForbidden)
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+	filter.Actor = r.URL.Query().Get("actor")
+	if value := r.URL.Query().Get("owner_id"); value != "" {
+		if filter.OwnerID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return apperror.NewAppError(err, "Invalid owner_id parameter", "", http.StatusBadRequest)
+		}
+	}
+
+	return h.writeEntries(w, r, filter)
+}
@@ -0,0 +1,93 @@
+// Package memory хранит журнал аудита в памяти процесса для режима --storage=memory
+package memory
+
+import (
+	"context"
+	"fit-journal/internal/audit"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Repository struct {
+	mu      sync.RWMutex
+	entries []audit.Entry
+	nextID  int64
+}
+
+// NewRepository создает пустой журнал
+func NewRepository() *Repository {
+	return &Repository{}
+}
+
+// Create добавляет запись в журнал. Время хранится с точностью до миллисекунд, как в PostgreSQL.
+func (r *Repository) Create(_ context.Context, entry audit.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	entry.ID = r.nextID
+	entry.At = time.UnixMilli(entry.At.UnixMilli())
+	entry.Changes = append([]audit.Change(nil), entry.Changes...)
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// Find возвращает записи журнала по фильтру, новые первыми
+func (r *Repository) Find(_ context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]audit.Entry, 0)
+	for _, e := range r.entries {
+		switch {
+		case filter.OwnerID != 0 && e.OwnerID != filter.OwnerID,
+			filter.Actor != "" && e.Actor != filter.Actor,
+			filter.Resource != "" && e.Resource != filter.Resource,
+			!filter.Since.IsZero() && e.At.Before(time.UnixMilli(filter.Since.UnixMilli())):
+			continue
+		}
+		e.Changes = append([]audit.Change(nil), e.Changes...)
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].At.Equal(entries[j].At) {
+			return entries[i].At.After(entries[j].At)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// PurgeBefore удаляет записи старше before
+func (r *Repository) PurgeBefore(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.entries[:0]
+	for _, e := range r.entries {
+		if e.At.UnixMilli() >= before.UnixMilli() {
+			kept = append(kept, e)
+		}
+	}
+	purged := int64(len(r.entries) - len(kept))
+	r.entries = kept
+	return purged, nil
+}
+
+// DeleteByOwner удаляет записи о данных пользователя при полном удалении аккаунта
+func (r *Repository) DeleteByOwner(ownerID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.entries[:0]
+	for _, e := range r.entries {
+		if e.OwnerID != ownerID {
+			kept = append(kept, e)
+		}
+	}
+	r.entries = kept
+}
@@ -0,0 +1,58 @@
+// Package audit ведёт журнал изменений данных пользователей.
+//
+// Журнал только пополняется: записи не изменяются и удаляются лишь по истечении срока хранения
+// (config.AuditConfig.Retention) или вместе с аккаунтом владельца при полном удалении.
+// Каждая запись содержит автора изменения, владельца данных, ресурс, действие,
+// отличия по полям до и после изменения и идентификатор запроса (см. пакет requestid).
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Action вид изменения
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionRestore Action = "restore"
+)
+
+// Ресурсы, изменения которых попадают в журнал
+const (
+	ResourceUser    = "user"
+	ResourceWorkout = "workout"
+	ResourceMetric  = "metric"
+)
+
+// Change изменение одного поля ресурса. Before отсутствует при создании, After при удалении.
+type Change struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Entry запись журнала аудита
+type Entry struct {
+	ID         int64     `json:"id"`
+	At         time.Time `json:"at"`
+	Actor      string    `json:"actor"`    // Имя пользователя, выполнившего запрос
+	OwnerID    int64     `json:"owner_id"` // Владелец изменённых данных
+	Resource   string    `json:"resource"`
+	ResourceID int64     `json:"resource_id"`
+	Action     Action    `json:"action"`
+	Changes    []Change  `json:"changes"`
+	RequestID  string    `json:"request_id,omitempty"`
+}
+
+// Filter условия выборки записей журнала. Нулевые значения не ограничивают выборку.
+type Filter struct {
+	OwnerID  int64
+	Actor    string
+	Resource string
+	Since    time.Time
+	Limit    int
+}
@@ -0,0 +1,121 @@
+package mongodb
+
+import (
+	"context"
+	"fit-journal/internal/audit"
+	"fit-journal/pkg/client/mongodb"
+	"fit-journal/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type Repository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// document запись журнала в коллекции audit_log; at в миллисекундах Unix
+type document struct {
+	ID         int64          `bson:"_id"`
+	At         int64          `bson:"at"`
+	Actor      string         `bson:"actor"`
+	OwnerID    int64          `bson:"owner_id"`
+	Resource   string         `bson:"resource"`
+	ResourceID int64          `bson:"resource_id"`
+	Action     audit.Action   `bson:"action"`
+	Changes    []audit.Change `bson:"changes"`
+	RequestID  string         `bson:"request_id"`
+}
+
+// Create добавляет запись в журнал
+func (r *Repository) Create(ctx context.Context, entry audit.Entry) error {
+	id, err := mongodb.NextID(ctx, r.db, r.collection.Name())
+	if err != nil {
+		return err
+	}
+	doc := document{
+		ID:         id,
+		At:         entry.At.UnixMilli(),
+		Actor:      entry.Actor,
+		OwnerID:    entry.OwnerID,
+		Resource:   entry.Resource,
+		ResourceID: entry.ResourceID,
+		Action:     entry.Action,
+		Changes:    entry.Changes,
+		RequestID:  entry.RequestID,
+	}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Find возвращает записи журнала по фильтру, новые первыми
+func (r *Repository) Find(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	query := bson.M{}
+	if filter.OwnerID != 0 {
+		query["owner_id"] = filter.OwnerID
+	}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	if filter.Resource != "" {
+		query["resource"] = filter.Resource
+	}
+	if !filter.Since.IsZero() {
+		query["at"] = bson.M{"$gte": filter.Since.UnixMilli()}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(filter.Limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := make([]audit.Entry, 0)
+	for cursor.Next(ctx) {
+		var d document
+		if err := cursor.Decode(&d); err != nil {
+			return nil, err
+		}
+		entries = append(entries, audit.Entry{
+			ID:         d.ID,
+			At:         time.UnixMilli(d.At),
+			Actor:      d.Actor,
+			OwnerID:    d.OwnerID,
+			Resource:   d.Resource,
+			ResourceID: d.ResourceID,
+			Action:     d.Action,
+			Changes:    d.Changes,
+			RequestID:  d.RequestID,
+		})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// PurgeBefore удаляет записи старше before
+func (r *Repository) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{"at": bson.M{"$lt": before.UnixMilli()}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(db *mongo.Database) *Repository {
+	return &Repository{
+		db:         db,
+		collection: db.Collection("audit_log"),
+	}
+}
@@ -0,0 +1,12 @@
+package mongodb
+
+import (
+	"fit-journal/internal/entities/storagetest"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunAudit(t, func(t *testing.T) storagetest.Repositories {
+		return storagetest.Repositories{Audit: NewRepository(storagetest.MongoDB(t))}
+	})
+}
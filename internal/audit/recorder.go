@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"fit-journal/internal/requestid"
+	"fit-journal/pkg/logging"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Recorder пишет изменения в журнал аудита. Нулевой *Recorder ничего не записывает.
+type Recorder struct {
+	repository Repository
+	now        func() time.Time
+}
+
+func NewRecorder(repo Repository) *Recorder {
+	return &Recorder{
+		repository: repo,
+		now:        time.Now,
+	}
+}
+
+// Record записывает изменение ресурса, вычисляя отличия между before и after (см. Diff)
+func (rec *Recorder) Record(r *http.Request, action Action, resource string, resourceID, ownerID int64, before, after interface{}) {
+	rec.RecordChanges(r, action, resource, resourceID, ownerID, Diff(before, after))
+}
+
+// RecordChanges записывает изменение ресурса с уже вычисленными отличиями.
+// Ошибка записи журнала не прерывает запрос и только логируется.
+func (rec *Recorder) RecordChanges(r *http.Request, action Action, resource string, resourceID, ownerID int64, changes []Change) {
+	if rec == nil {
+		return
+	}
+
+	// Без аутентификации (например, при регистрации) автор неизвестен
+	actor, _ := r.Context().Value("username").(string)
+	if actor == "" {
+		actor = "anonymous"
+	}
+	entry := Entry{
+		At:         rec.now(),
+		Actor:      actor,
+		OwnerID:    ownerID,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Action:     action,
+		Changes:    changes,
+		RequestID:  requestid.FromContext(r.Context()),
+	}
+
+	// Запрос клиента мог уже завершиться, запись не должна от этого зависеть;
+	// логгер запроса из контекста при этом сохраняется
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 5*time.Second)
+	defer cancel()
+	if err := rec.repository.Create(ctx, entry); err != nil {
+		logging.FromContext(ctx).Errorf("Failed to write audit entry %s %s/%d: %v", action, resource, resourceID, err)
+	}
+}
+
+// StartPurge периодически удаляет записи старше retention до отмены контекста; горутина учитывается в wg
+func StartPurge(ctx context.Context, wg *sync.WaitGroup, repo Repository, logger *logging.Logger, retention, interval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := repo.PurgeBefore(ctx, time.Now().Add(-retention)); err != nil {
+				logger.Errorf("Failed to purge audit log: %v", err)
+			} else if n > 0 {
+				logger.Infof("Purged %d audit entries", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/requestid"
+	"fit-journal/pkg/logging"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRepository запоминает записи журнала; err возвращается из Create
+type fakeRepository struct {
+	mu      sync.Mutex
+	entries []Entry
+	purged  []time.Time
+	err     error
+}
+
+func (r *fakeRepository) Create(_ context.Context, entry Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeRepository) Find(context.Context, Filter) ([]Entry, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) PurgeBefore(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purged = append(r.purged, before)
+	return 0, nil
+}
+
+func TestRecorderRecord(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		username  string // Пусто для запросов без аутентификации
+		requestID string
+		wantActor string
+	}{
+		{"authenticated", "alice", "req-1", "alice"},
+		{"anonymous", "", "", "anonymous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{}
+			rec := NewRecorder(repo)
+			rec.now = func() time.Time { return now }
+
+			r := httptest.NewRequest("PUT", "/workouts/7", nil)
+			ctx := requestid.WithContext(r.Context(), tt.requestID)
+			if tt.username != "" {
+				ctx = context.WithValue(ctx, "username", tt.username)
+			}
+			rec.Record(r.WithContext(ctx), ActionUpdate, ResourceWorkout, 7, 3, resource{Name: "Squat"}, resource{Name: "Deadlift"})
+
+			want := Entry{
+				At:         now,
+				Actor:      tt.wantActor,
+				OwnerID:    3,
+				Resource:   ResourceWorkout,
+				ResourceID: 7,
+				Action:     ActionUpdate,
+				Changes:    Diff(resource{Name: "Squat"}, resource{Name: "Deadlift"}),
+				RequestID:  tt.requestID,
+			}
+			if len(repo.entries) != 1 || !reflect.DeepEqual(repo.entries[0], want) {
+				t.Errorf("recorded %+v, want %+v", repo.entries, want)
+			}
+		})
+	}
+}
+
+func TestRecorderIgnoresFailures(t *testing.T) {
+	r := httptest.NewRequest("DELETE", "/metrics/1", nil)
+
+	// Нулевой Recorder ничего не пишет
+	var disabled *Recorder
+	disabled.Record(r, ActionDelete, ResourceMetric, 1, 1, resource{}, nil)
+
+	// Ошибка журнала не должна прерывать запрос
+	repo := &fakeRepository{err: errors.New("disk full")}
+	NewRecorder(repo).Record(r, ActionDelete, ResourceMetric, 1, 1, resource{}, nil)
+	if len(repo.entries) != 0 {
+		t.Errorf("unexpected entries %+v", repo.entries)
+	}
+}
+
+func TestStartPurge(t *testing.T) {
+	repo := &fakeRepository{}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	start := time.Now()
+	StartPurge(ctx, &wg, repo, logging.GetLogger(), time.Hour, time.Hour)
+
+	// Первая очистка выполняется сразу при запуске
+	deadline := time.After(time.Second)
+	for {
+		repo.mu.Lock()
+		purged := append([]time.Time(nil), repo.purged...)
+		repo.mu.Unlock()
+		if len(purged) > 0 {
+			if cutoff := start.Add(-time.Hour); purged[0].Before(cutoff.Add(-time.Second)) || purged[0].After(time.Now().Add(-time.Hour)) {
+				t.Errorf("purged before %s, want about %s", purged[0], cutoff)
+			}
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatal("StartPurge did not purge on start")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
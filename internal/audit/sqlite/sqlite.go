@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fit-journal/internal/audit"
+	"fit-journal/pkg/client/sqlite"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Repository struct {
+	client sqlite.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// Create добавляет запись в журнал
+func (r *Repository) Create(ctx context.Context, entry audit.Entry) error {
+	q := `
+		INSERT INTO audit_log
+			(at, actor, owner_id, resource, resource_id, action, changes, request_id)
+		VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.ExecContext(ctx, q, entry.At.UnixMilli(), entry.Actor, entry.OwnerID, entry.Resource, entry.ResourceID,
+		string(entry.Action), string(changes), entry.RequestID)
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// Find возвращает записи журнала по фильтру, новые первыми
+func (r *Repository) Find(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	conditions := make([]string, 0, 4)
+	args := make([]interface{}, 0, 5)
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, condition)
+	}
+	if filter.OwnerID != 0 {
+		add("owner_id = ?", filter.OwnerID)
+	}
+	if filter.Actor != "" {
+		add("actor = ?", filter.Actor)
+	}
+	if filter.Resource != "" {
+		add("resource = ?", filter.Resource)
+	}
+	if !filter.Since.IsZero() {
+		add("at >= ?", filter.Since.UnixMilli())
+	}
+
+	q := `SELECT id, at, actor, owner_id, resource, resource_id, action, changes, request_id FROM audit_log`
+	if len(conditions) > 0 {
+		q += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	q += ` ORDER BY at DESC, id DESC`
+	// Нулевой Limit не ограничивает выборку
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		q += ` LIMIT ?`
+	}
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]audit.Entry, 0)
+	for rows.Next() {
+		var e audit.Entry
+		var at int64
+		var changes string
+		if err := rows.Scan(&e.ID, &at, &e.Actor, &e.OwnerID, &e.Resource, &e.ResourceID, &e.Action, &changes, &e.RequestID); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &e.Changes); err != nil {
+			return nil, err
+		}
+		e.At = time.UnixMilli(at)
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// PurgeBefore удаляет записи старше before
+func (r *Repository) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	q := `
+		DELETE FROM audit_log WHERE at < ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	res, err := r.client.ExecContext(ctx, q, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client sqlite.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
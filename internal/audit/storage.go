@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, entry Entry) error
+	Find(ctx context.Context, filter Filter) ([]Entry, error)
+	// PurgeBefore удаляет записи старше before
+	PurgeBefore(ctx context.Context, before time.Time) (int64, error)
+}
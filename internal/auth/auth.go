@@ -1,59 +1,91 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fit-journal/internal/apperror"
-	"fit-journal/internal/config"
+	"fit-journal/pkg/logging"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
-	"golang.org/x/net/context"
 	"net/http"
 	"strings"
 	"time"
 )
 
-var JWTSecret = []byte(config.GetConfig().JWTSecret)
+const (
+	// DefaultTokenTTL время жизни токена, если в Options не задано иное
+	DefaultTokenTTL = 5 * time.Minute
+	// DefaultBcryptCost стоимость bcrypt, если в Options не задано иное
+	DefaultBcryptCost = 14
+)
+
+// Options параметры сервиса авторизации
+type Options struct {
+	Secret     []byte           // Ключ подписи HS256
+	TokenTTL   time.Duration    // Время жизни токена
+	BcryptCost int              // Стоимость хэширования паролей; в тестах можно ставить bcrypt.MinCost
+	Now        func() time.Time // Часы для выпуска и проверки токенов, по умолчанию time.Now
+}
+
+// Service выпускает и проверяет JWT и хэширует пароли. Создаётся в main и передаётся обработчикам.
+type Service struct {
+	secret     []byte
+	tokenTTL   time.Duration
+	bcryptCost int
+	now        func() time.Time
+}
+
+// NewService создаёт сервис авторизации; нулевые значения Options заменяются значениями по умолчанию
+func NewService(opts Options) *Service {
+	s := &Service{
+		secret:     opts.Secret,
+		tokenTTL:   opts.TokenTTL,
+		bcryptCost: opts.BcryptCost,
+		now:        opts.Now,
+	}
+	if s.tokenTTL <= 0 {
+		s.tokenTTL = DefaultTokenTTL
+	}
+	if s.bcryptCost == 0 {
+		s.bcryptCost = DefaultBcryptCost
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+	return s
+}
 
 // Функция для хэширования пароля
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+func (s *Service) HashPassword(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
 	return string(bytes), err
 }
 
 // Функция для проверки пароля с хэшем
-func CheckPasswordHash(password, hash string) bool {
+func (s *Service) CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
 
-// Функция для регистрации пользователя
-func RegisterUser(username, password string) (string, error) {
-	hashedPassword, err := HashPassword(password)
-	if err != nil {
-		return "", err
-	}
-
-	fmt.Printf("User %s registered with password hash: %s\n", username, hashedPassword)
-	return hashedPassword, nil
-}
-
 type Claims struct {
 	Username string `json:"username"`
+	UserID   int64  `json:"user_id,omitempty"` // Для полей логов; в токенах, выпущенных до его появления, отсутствует
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(username string) (string, error) {
-	expirationTime := time.Now().Add(5 * time.Minute)
+func (s *Service) GenerateJWT(username string, userID int64) (string, error) {
+	expirationTime := s.now().Add(s.tokenTTL)
 	claims := &Claims{
 		Username: username,
+		UserID:   userID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(JWTSecret)
+	tokenString, err := token.SignedString(s.secret)
 	if err != nil {
 		return "", err
 	}
@@ -62,7 +94,7 @@ func GenerateJWT(username string) (string, error) {
 }
 
 // Проверка токена
-func ValidateJWT(tokenString string) (string, error) {
+func (s *Service) ValidateJWT(tokenString string) (Claims, error) {
 	claims := &Claims{}
 
 	// Парсинг токена и проверка подписи
@@ -71,30 +103,30 @@ func ValidateJWT(tokenString string) (string, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return JWTSecret, nil // Возвращаем секретный ключ
-	})
+		return s.secret, nil // Возвращаем секретный ключ
+	}, jwt.WithTimeFunc(s.now))
 
 	if err != nil {
-		return "", err
+		return Claims{}, err
 	}
 
 	// Проверка валидности токена
 	if !token.Valid {
-		return "", errors.New("invalid token")
+		return Claims{}, errors.New("invalid token")
 	}
 
-	return claims.Username, nil // Возвращаем имя пользователя из токена
+	return *claims, nil
 }
 
 // Логин с проверкой пароля и генерацией JWT токена
-func Login(username, password, hash string) (string, error) {
+func (s *Service) Login(username string, userID int64, password, hash string) (string, error) {
 	// Проверяем пароль
-	if !CheckPasswordHash(password, hash) {
+	if !s.CheckPasswordHash(password, hash) {
 		return "", fmt.Errorf("invalid password")
 	}
 
 	// Генерируем JWT токен
-	token, err := GenerateJWT(username)
+	token, err := s.GenerateJWT(username, userID)
 	if err != nil {
 		return "", err
 	}
@@ -102,32 +134,34 @@ func Login(username, password, hash string) (string, error) {
 	return token, nil
 }
 
-// TokenAuthMiddleware проверяет наличие и валидность Bearer токена в заголовках
-func TokenAuthMiddleware(next apperror.AppHandler) apperror.AppHandler {
+// TokenAuthMiddleware проверяет наличие и валидность Bearer токена в заголовках; отказ возвращается
+// ошибкой со статусом 401, которую выводит apperror.Middleware
+func (s *Service) TokenAuthMiddleware(next apperror.AppHandler) apperror.AppHandler {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		// Извлекаем токен из заголовка
 		tokenString := r.Header.Get("Authorization")
 		if tokenString == "" {
-			http.Error(w, "Authorization header is missing", http.StatusUnauthorized)
-			return nil
+			return apperror.NewAppError(nil, "Authorization header is missing", "", http.StatusUnauthorized)
 		}
 
 		if strings.HasPrefix(tokenString, "Bearer ") {
 			tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 		} else {
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
-			return nil
+			return apperror.NewAppError(nil, "Invalid token format", "expected Authorization: Bearer <token>", http.StatusUnauthorized)
 		}
 
 		// Проверяем токен
-		username, err := ValidateJWT(tokenString)
+		claims, err := s.ValidateJWT(tokenString)
 		if err != nil {
-			http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
-			return nil
+			return apperror.NewAppError(err, "Invalid token", err.Error(), http.StatusUnauthorized)
 		}
 
-		// Добавляем никнейм в контекст
-		ctx := context.WithValue(r.Context(), "username", username)
+		// Добавляем никнейм в контекст, а пользователя в поля логов запроса и access-лога
+		ctx := context.WithValue(r.Context(), "username", claims.Username)
+		logging.AddField(ctx, "user", claims.Username)
+		if claims.UserID != 0 {
+			logging.AddField(ctx, "user_id", claims.UserID)
+		}
 		// Передаем контекст с данными дальше
 		return next(w, r.WithContext(ctx))
 	}
@@ -2,42 +2,204 @@ package config
 
 import (
 	"fit-journal/pkg/logging"
-	"github.com/ilyakaznacheev/cleanenv"
-	"sync"
+	"fit-journal/pkg/tracing"
+	"net"
+	"net/url"
+	"time"
 )
 
+// Config конфигурация приложения. Значения берутся по возрастанию приоритета: env-default,
+// файл конфигурации, переменные окружения (для секретов также *_FILE), флаги командной строки.
 type Config struct {
-	IsDebug *bool `yaml:"is_debug" env-required:"true"`
+	IsDebug *bool `yaml:"is_debug"` // Обязателен; IS_DEBUG разбирается в Load: cleanenv не поддерживает указатели
 	Listen  struct {
-		Type   string `yaml:"type" env-default:"port"`
-		BindIP string `yaml:"bind_ip" env-default:"127.0.0.1"`
-		Port   string `yaml:"port" env-default:"8080"`
-	} `yaml:"listen"`
-	Storage   StorageConfig `yaml:"storage"`
-	JWTSecret string        `yaml:"jwt_secret" env-default:"secret"`
+		Type   string `yaml:"type" env:"TYPE" env-default:"port"`
+		BindIP string `yaml:"bind_ip" env:"BIND_IP" env-default:"127.0.0.1"`
+		Port   string `yaml:"port" env:"PORT" env-default:"8080"`
+	} `yaml:"listen" env-prefix:"LISTEN_"`
+	Server    ServerConfig  `yaml:"server" env-prefix:"SERVER_"`
+	TLS       TLSConfig     `yaml:"tls" env-prefix:"TLS_"`
+	Storage   StorageConfig `yaml:"storage" env-prefix:"STORAGE_"`
+	Logging   LoggingConfig `yaml:"logging" env-prefix:"LOG_"`
+	Tracing   TracingConfig `yaml:"tracing" env-prefix:"TRACING_"`
+	JWTSecret string        `yaml:"jwt_secret" env:"JWT_SECRET" env-default:"secret" secret:"true"`
+	Auth      AuthConfig    `yaml:"auth" env-prefix:"AUTH_"`
+	Erasure   ErasureConfig `yaml:"erasure" env-prefix:"ERASURE_"`
+	Trash     TrashConfig   `yaml:"trash" env-prefix:"TRASH_"`
+	Audit     AuditConfig   `yaml:"audit" env-prefix:"AUDIT_"`
+	Imports   ImportsConfig `yaml:"imports" env-prefix:"IMPORTS_"`
+	Admins    []string      `yaml:"admins" env:"ADMINS" env-separator:","` // Пользователи с доступом к журналу аудита всех пользователей
+	Health    HealthConfig  `yaml:"health" env-prefix:"HEALTH_"`
 }
 
+// ServerConfig таймауты HTTP-сервера и ограничения запросов
+type ServerConfig struct {
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT" env-default:"15s"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"READ_HEADER_TIMEOUT" env-default:"5s"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" env-default:"15s"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" env-default:"60s"`
+	// ShutdownDelay пауза между переводом /readyz в 503 и остановкой приёма запросов,
+	// чтобы балансировщик успел убрать экземпляр
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY" env-default:"0s"`
+	// ShutdownTimeout сколько ждать завершения начатых запросов, после чего соединения закрываются
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+	// MaxBodyBytes ограничение тела запроса; загрузка файлов импорта ограничивается отдельно
+	MaxBodyBytes int64 `yaml:"max_body_bytes" env:"MAX_BODY_BYTES" env-default:"1048576"`
+}
+
+// TLSConfig параметры TLS и проверки клиентских сертификатов, см. servertls
+type TLSConfig struct {
+	Enabled      bool   `yaml:"enabled" env:"ENABLED" env-default:"false"`
+	CertFile     string `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile      string `yaml:"key_file" env:"KEY_FILE"`
+	ClientCAFile string `yaml:"client_ca_file" env:"CLIENT_CA_FILE"`              // CA клиентских сертификатов
+	ClientAuth   string `yaml:"client_auth" env:"CLIENT_AUTH" env-default:"none"` // none, optional или require
+	// AllowedServices CommonName клиентских сертификатов, которым разрешён доступ; пустой список разрешает всех.
+	// Если client_auth не none, /metrics доступен только клиентам с сертификатом.
+	AllowedServices []string      `yaml:"allowed_services" env:"ALLOWED_SERVICES" env-separator:","`
+	ReloadInterval  time.Duration `yaml:"reload_interval" env:"RELOAD_INTERVAL" env-default:"1m"` // Как часто проверять изменение файлов
+}
+
+// AuthConfig параметры выпуска токенов и хэширования паролей, см. auth.Options
+type AuthConfig struct {
+	TokenTTL   time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" env-default:"5m"` // Время жизни JWT
+	BcryptCost int           `yaml:"bcrypt_cost" env:"BCRYPT_COST" env-default:"14"`
+}
+
+// HealthConfig таймауты проверок готовности /readyz
+type HealthConfig struct {
+	DatabaseTimeout time.Duration `yaml:"database_timeout" env:"DATABASE_TIMEOUT" env-default:"2s"` // Ping базы через пул
+	SchemaTimeout   time.Duration `yaml:"schema_timeout" env:"SCHEMA_TIMEOUT" env-default:"2s"`     // Проверка применённых миграций
+}
+
+// AuditConfig параметры журнала аудита
+type AuditConfig struct {
+	Retention     time.Duration `yaml:"retention" env:"RETENTION" env-default:"8760h"`         // Сколько хранить записи журнала
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"PURGE_INTERVAL" env-default:"24h"` // Как часто удалять устаревшие записи
+}
+
+// TrashConfig параметры корзины удалённых тренировок, упражнений и подходов
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention" env:"RETENTION" env-default:"720h"`         // Сколько хранить удалённое до безвозвратной очистки
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"PURGE_INTERVAL" env-default:"1h"` // Как часто запускать очистку
+}
+
+// ImportsConfig параметры задач импорта CSV, которые хранятся в памяти процесса
+type ImportsConfig struct {
+	JobTTL         time.Duration `yaml:"job_ttl" env:"JOB_TTL" env-default:"24h"`              // Сколько хранить завершённые задачи
+	ReviewTimeout  time.Duration `yaml:"review_timeout" env:"REVIEW_TIMEOUT" env-default:"1h"` // Сколько задача ждёт подтверждения сопоставления
+	MaxJobsPerUser int           `yaml:"max_jobs_per_user" env:"MAX_JOBS_PER_USER" env-default:"10"`
+	EvictInterval  time.Duration `yaml:"evict_interval" env:"EVICT_INTERVAL" env-default:"10m"` // Как часто удалять устаревшие задачи
+}
+
+// LoggingConfig параметры логирования, см. logging.Options
+type LoggingConfig struct {
+	Format        string   `yaml:"format" env:"FORMAT" env-default:"json"`                       // json или text
+	Level         string   `yaml:"level" env:"LEVEL" env-default:"info"`                         // trace, debug, info, warn, error
+	Outputs       []string `yaml:"outputs" env:"OUTPUTS" env-default:"stdout" env-separator:","` // stdout, stderr, file
+	FilePath      string   `yaml:"file_path" env:"FILE_PATH" env-default:"logs/all.log"`         // Файл для вывода file
+	ErrorFilePath string   `yaml:"error_file_path" env:"ERROR_FILE_PATH"`                        // Отдельный файл для ошибок, если задан
+	Rotation      struct {
+		MaxSizeMB int           `yaml:"max_size_mb" env:"MAX_SIZE_MB" env-default:"100"`
+		Interval  time.Duration `yaml:"interval" env:"INTERVAL" env-default:"24h"`
+		MaxAge    time.Duration `yaml:"max_age" env:"MAX_AGE" env-default:"168h"`
+		MaxFiles  int           `yaml:"max_files" env:"MAX_FILES" env-default:"10"`
+		Compress  bool          `yaml:"compress" env:"COMPRESS" env-default:"true"`
+	} `yaml:"rotation" env-prefix:"ROTATION_"`
+}
+
+// Options преобразует конфигурацию в настройки пакета logging
+func (c LoggingConfig) Options() logging.Options {
+	return logging.Options{
+		Format:        c.Format,
+		Level:         c.Level,
+		Outputs:       c.Outputs,
+		FilePath:      c.FilePath,
+		ErrorFilePath: c.ErrorFilePath,
+		Rotation: logging.RotationOptions{
+			MaxSizeMB: c.Rotation.MaxSizeMB,
+			Interval:  c.Rotation.Interval,
+			MaxAge:    c.Rotation.MaxAge,
+			MaxFiles:  c.Rotation.MaxFiles,
+			Compress:  c.Rotation.Compress,
+		},
+	}
+}
+
+// TracingConfig параметры трассировки OpenTelemetry, см. tracing.Options
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"EXPORTER" env-default:"none"`               // none, otlp, stdout, file
+	Endpoint    string  `yaml:"endpoint" env:"ENDPOINT" env-default:"localhost:4318"`     // OTLP/HTTP коллектор
+	Insecure    bool    `yaml:"insecure" env:"INSECURE" env-default:"true"`               // OTLP без TLS
+	FilePath    string  `yaml:"file_path" env:"FILE_PATH" env-default:"logs/traces.json"` // Файл для экспортёра file
+	ServiceName string  `yaml:"service_name" env:"SERVICE_NAME" env-default:"fit-journal"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" env-default:"1"` // Доля записываемых трейсов
+}
+
+// Options преобразует конфигурацию в настройки пакета tracing
+func (c TracingConfig) Options() tracing.Options {
+	return tracing.Options{
+		Exporter:    c.Exporter,
+		Endpoint:    c.Endpoint,
+		Insecure:    c.Insecure,
+		FilePath:    c.FilePath,
+		ServiceName: c.ServiceName,
+		SampleRatio: c.SampleRatio,
+	}
+}
+
+// ErasureConfig параметры полного удаления аккаунтов
+type ErasureConfig struct {
+	GracePeriod   time.Duration `yaml:"grace_period" env:"GRACE_PERIOD" env-default:"720h"`   // Срок, в течение которого запрос можно отменить
+	CheckInterval time.Duration `yaml:"check_interval" env:"CHECK_INTERVAL" env-default:"1h"` // Как часто искать просроченные запросы
+	ExportDir     string        `yaml:"export_dir" env:"EXPORT_DIR" env-default:"exports"`    // Каталог для архивов с выгрузкой
+}
+
+// Драйверы хранилища
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite" // Один файл базы, для установки на одного пользователя без PostgreSQL
+	StorageMongoDB  = "mongodb"
+	StorageMemory   = "memory" // Данные в памяти процесса, теряются при остановке; для разработки и тестов
+)
+
+// StorageConfig выбор хранилища и параметры подключения к PostgreSQL. URL (или переменная DATABASE_URL),
+// если задан, заменяет остальные поля PostgreSQL. Path используется только для SQLite, MongoDB только для mongodb.
 type StorageConfig struct {
-	Host     string `json:"host"`
-	Port     string `json:"port"`
-	Database string `json:"database"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-}
-
-var instance *Config
-var once sync.Once
-
-func GetConfig() *Config {
-	once.Do(func() {
-		logger := logging.GetLogger()
-		logger.Info("read application configuration")
-		instance = &Config{}
-		if err := cleanenv.ReadConfig("config.yml", instance); err != nil {
-			help, _ := cleanenv.GetDescription(instance, nil)
-			logger.Info(help)
-			logger.Fatal(err)
-		}
-	})
-	return instance
+	Driver   string `yaml:"driver" env:"DRIVER" env-default:"postgres"`        // postgres, sqlite, mongodb или memory
+	Path     string `yaml:"path" env:"PATH" env-default:"data/fit-journal.db"` // Файл базы SQLite
+	URL      string `yaml:"url" env:"URL" secret:"true"`
+	Host     string `yaml:"host" env:"HOST"`
+	Port     string `yaml:"port" env:"PORT" env-default:"5432"`
+	Database string `yaml:"database" env:"DATABASE"`
+	Username string `yaml:"username" env:"USERNAME"`
+	Password string `yaml:"password" env:"PASSWORD" secret:"true"`
+	// TxMaxAttempts сколько раз выполнять единицу работы при конфликте сериализации PostgreSQL
+	TxMaxAttempts int           `yaml:"tx_max_attempts" env:"TX_MAX_ATTEMPTS" env-default:"3"`
+	MongoDB       MongoDBConfig `yaml:"mongodb" env-prefix:"MONGODB_"`
+}
+
+// MongoDBConfig параметры подключения к MongoDB. AuthDB база, в которой проверяются учётные данные,
+// по умолчанию Database.
+type MongoDBConfig struct {
+	Host     string `yaml:"host" env:"HOST"`
+	Port     string `yaml:"port" env:"PORT" env-default:"27017"`
+	Database string `yaml:"database" env:"DATABASE" env-default:"fit-journal"`
+	Username string `yaml:"username" env:"USERNAME"`
+	Password string `yaml:"password" env:"PASSWORD" secret:"true"`
+	AuthDB   string `yaml:"auth_db" env:"AUTH_DB"`
+}
+
+// DSN возвращает строку подключения для pgxpool
+func (s StorageConfig) DSN() string {
+	if s.URL != "" {
+		return s.URL
+	}
+	u := url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(s.Username, s.Password),
+		Host:   net.JoinHostPort(s.Host, s.Port),
+		Path:   "/" + s.Database,
+	}
+	return u.String()
 }
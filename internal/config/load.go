@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fit-journal/pkg/logging"
+	"flag"
+	"fmt"
+	"github.com/ilyakaznacheev/cleanenv"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPath файл конфигурации по умолчанию; если его нет, конфигурация берётся только из окружения
+	DefaultPath = "config.yml"
+	// pathEnv переменная окружения с путём к файлу конфигурации
+	pathEnv = "CONFIG_PATH"
+	// isDebugEnv переменная для is_debug
+	isDebugEnv = "IS_DEBUG"
+	// databaseURLEnv общепринятая переменная со строкой подключения, заменяет storage.url
+	databaseURLEnv = "DATABASE_URL"
+	// fileSuffix суффикс переменной, значение которой читается из файла: JWT_SECRET_FILE=/run/secrets/jwt
+	fileSuffix = "_FILE"
+)
+
+// Command подкоманды командной строки
+const (
+	CommandServe = ""      // Запуск сервера
+	CommandPrint = "print" // config print: вывести итоговую конфигурацию
+)
+
+// Args разобранные аргументы командной строки
+type Args struct {
+	Command  string
+	Redacted bool // config print --redacted скрывает секреты
+
+	Path      string // -config
+	Storage   string // -storage
+	BindIP    string // -bind-ip
+	Port      string // -port
+	LogLevel  string // -log-level
+	LogFormat string // -log-format
+}
+
+// ParseArgs разбирает аргументы: `[флаги]` для запуска сервера или `config print [--redacted] [флаги]`
+func ParseArgs(args []string) (Args, error) {
+	var a Args
+	if len(args) > 0 && args[0] == "config" {
+		if len(args) < 2 || args[1] != "print" {
+			return a, errors.New("usage: config print [--redacted] [flags]")
+		}
+		a.Command, args = CommandPrint, args[2:]
+	}
+
+	fs := flag.NewFlagSet("fit-journal", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&a.Path, "config", "", "path to the configuration file (default "+DefaultPath+", env "+pathEnv+")")
+	fs.StringVar(&a.Storage, "storage", "", "override storage.driver (postgres, sqlite, mongodb or memory)")
+	fs.StringVar(&a.BindIP, "bind-ip", "", "override listen.bind_ip")
+	fs.StringVar(&a.Port, "port", "", "override listen.port")
+	fs.StringVar(&a.LogLevel, "log-level", "", "override logging.level")
+	fs.StringVar(&a.LogFormat, "log-format", "", "override logging.format")
+	if a.Command == CommandPrint {
+		fs.BoolVar(&a.Redacted, "redacted", false, "replace secrets with ***")
+	}
+	if err := fs.Parse(args); err != nil {
+		return a, err
+	}
+	if fs.NArg() > 0 {
+		return a, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	return a, nil
+}
+
+// Load собирает конфигурацию из файла, окружения и флагов. Значения не проверяются, см. Validate.
+func Load(a Args) (*Config, error) {
+	secretsOnce.Do(func() { secretsErr = readSecretFiles() })
+	if secretsErr != nil {
+		return nil, secretsErr
+	}
+
+	path, explicit := a.Path, a.Path != ""
+	if !explicit {
+		path, explicit = os.LookupEnv(pathEnv)
+	}
+	if !explicit {
+		path = DefaultPath
+	}
+
+	cfg := &Config{}
+	if err := readFile(path, cfg); err != nil {
+		if !errors.Is(err, os.ErrNotExist) || explicit {
+			return nil, err
+		}
+	}
+	if err := cleanenv.ReadEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	if v, ok := os.LookupEnv(isDebugEnv); ok {
+		debug, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", isDebugEnv, err)
+		}
+		cfg.IsDebug = &debug
+	}
+	if v, ok := os.LookupEnv(databaseURLEnv); ok {
+		cfg.Storage.URL = v
+	}
+	for _, o := range []struct {
+		flag   string
+		target *string
+	}{
+		{a.Storage, &cfg.Storage.Driver},
+		{a.BindIP, &cfg.Listen.BindIP},
+		{a.Port, &cfg.Listen.Port},
+		{a.LogLevel, &cfg.Logging.Level},
+		{a.LogFormat, &cfg.Logging.Format},
+	} {
+		if o.flag != "" {
+			*o.target = o.flag
+		}
+	}
+
+	return cfg, nil
+}
+
+// readFile читает YAML строго: неизвестные ключи считаются ошибкой, чтобы опечатки не терялись молча
+func readFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+var (
+	// secretsOnce переменные *_FILE читаются один раз: после чтения NAME уже выставлена в окружении
+	secretsOnce sync.Once
+	secretsErr  error
+)
+
+// readSecretFiles для каждой переменной конфигурации NAME, у которой задана NAME_FILE, а сама NAME нет,
+// выставляет NAME из содержимого файла (без завершающего перевода строки)
+func readSecretFiles() error {
+	var errs []error
+	for _, name := range append(envNames(reflect.TypeOf(Config{}), ""), databaseURLEnv) {
+		file, ok := os.LookupEnv(name + fileSuffix)
+		if !ok {
+			continue
+		}
+		if _, set := os.LookupEnv(name); set {
+			errs = append(errs, fmt.Errorf("both %s and %s%s are set", name, name, fileSuffix))
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", name, fileSuffix, err))
+			continue
+		}
+		os.Setenv(name, strings.TrimRight(string(data), "\r\n"))
+	}
+	return errors.Join(errs...)
+}
+
+// envNames возвращает имена переменных окружения всех полей с учётом env-prefix вложенных структур
+func envNames(t reflect.Type, prefix string) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Time{}) {
+			names = append(names, envNames(f.Type, prefix+f.Tag.Get("env-prefix"))...)
+			continue
+		}
+		if env := f.Tag.Get("env"); env != "" {
+			for _, name := range strings.Split(env, ",") {
+				names = append(names, prefix+name)
+			}
+		}
+	}
+	return names
+}
+
+var instance *Config
+var once sync.Once
+
+// GetConfig читает конфигурацию по аргументам процесса один раз и завершает процесс, если она некорректна
+func GetConfig() *Config {
+	once.Do(func() {
+		logger := logging.GetLogger()
+		logger.Debug("read application configuration")
+
+		args, err := ParseArgs(os.Args[1:])
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if instance, err = Load(args); err == nil {
+			err = instance.Validate()
+		}
+		if err != nil {
+			help, _ := cleanenv.GetDescription(&Config{}, nil)
+			logger.Info(help)
+			logger.Fatalf("invalid configuration:\n%v", err)
+		}
+	})
+	return instance
+}
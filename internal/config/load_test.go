@@ -0,0 +1,287 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clearEnv убирает из окружения переменные, которые влияют на Load; после теста значения восстанавливаются
+func clearEnv(t *testing.T) {
+	t.Helper()
+	names := append(envNames(reflect.TypeOf(Config{}), ""), pathEnv, isDebugEnv, databaseURLEnv)
+	for _, name := range names {
+		for _, n := range []string{name, name + fileSuffix} {
+			t.Setenv(n, "")
+			os.Unsetenv(n)
+		}
+	}
+	// *_FILE читаются один раз на процесс
+	secretsOnce, secretsErr = sync.Once{}, nil
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testConfigFile = `
+is_debug: false
+listen:
+  port: "9000"
+  bind_ip: 0.0.0.0
+storage:
+  host: db
+  database: journal
+logging:
+  level: warn
+auth:
+  token_ttl: 10m
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  Args
+		check func(t *testing.T, cfg *Config)
+	}{
+		{"defaults and file", nil, Args{}, func(t *testing.T, cfg *Config) {
+			// Значения из файла, остальное из env-default
+			if cfg.Listen.Port != "9000" || cfg.Listen.BindIP != "0.0.0.0" || cfg.Logging.Level != "warn" || cfg.Auth.TokenTTL != 10*time.Minute {
+				t.Errorf("file values not applied: %+v", cfg)
+			}
+			if cfg.Listen.Type != "port" || cfg.Logging.Format != "json" || cfg.Server.ReadTimeout != 15*time.Second || cfg.Storage.Port != "5432" {
+				t.Errorf("defaults not applied: %+v", cfg)
+			}
+			if cfg.IsDebug == nil || *cfg.IsDebug {
+				t.Errorf("is_debug = %v", cfg.IsDebug)
+			}
+		}},
+		{"env overrides file", map[string]string{
+			"LISTEN_PORT":          "9100",
+			"LOG_LEVEL":            "debug",
+			"AUTH_TOKEN_TTL":       "1h",
+			"IS_DEBUG":             "true",
+			"ADMINS":               "alice,bob",
+			"STORAGE_MONGODB_HOST": "mongo",
+		}, Args{}, func(t *testing.T, cfg *Config) {
+			if cfg.Listen.Port != "9100" || cfg.Logging.Level != "debug" || cfg.Auth.TokenTTL != time.Hour {
+				t.Errorf("env values not applied: %+v", cfg)
+			}
+			if cfg.IsDebug == nil || !*cfg.IsDebug {
+				t.Errorf("is_debug = %v, want true", cfg.IsDebug)
+			}
+			if !reflect.DeepEqual(cfg.Admins, []string{"alice", "bob"}) || cfg.Storage.MongoDB.Host != "mongo" {
+				t.Errorf("admins %v, mongodb host %q", cfg.Admins, cfg.Storage.MongoDB.Host)
+			}
+		}},
+		{"flags override env", map[string]string{
+			"LISTEN_PORT":    "9100",
+			"STORAGE_DRIVER": "sqlite",
+		}, Args{Port: "9200", Storage: "memory", LogFormat: "text"}, func(t *testing.T, cfg *Config) {
+			if cfg.Listen.Port != "9200" || cfg.Storage.Driver != "memory" || cfg.Logging.Format != "text" {
+				t.Errorf("flags not applied: %+v", cfg)
+			}
+		}},
+		{"DATABASE_URL overrides storage.url", map[string]string{
+			"STORAGE_URL":  "postgres://env@db/journal",
+			"DATABASE_URL": "postgres://common@db/journal",
+		}, Args{}, func(t *testing.T, cfg *Config) {
+			if cfg.Storage.URL != "postgres://common@db/journal" {
+				t.Errorf("storage.url = %q", cfg.Storage.URL)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			tt.args.Path = writeConfig(t, testConfigFile)
+
+			cfg, err := Load(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadConfigPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagPath bool   // Путь передан флагом -config
+		envPath  bool   // Путь передан в CONFIG_PATH
+		missing  bool   // Файла нет
+		content  string // Содержимое файла
+		wantErr  string
+		wantPort string
+	}{
+		{"flag", true, false, false, testConfigFile, "", "9000"},
+		{"env", false, true, false, testConfigFile, "", "9000"},
+		// Отсутствие явно указанного файла ошибка, файла по умолчанию нет
+		{"missing explicit file", true, false, true, "", "no such file", ""},
+		{"missing env file", false, true, true, "", "no such file", ""},
+		{"unknown key", true, false, false, "listen:\n  prot: 9000\n", "field prot not found", ""},
+		{"empty file", true, false, false, "", "", "8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			path := filepath.Join(t.TempDir(), "absent.yml")
+			if !tt.missing {
+				path = writeConfig(t, tt.content)
+			}
+			var args Args
+			if tt.flagPath {
+				args.Path = path
+			}
+			if tt.envPath {
+				t.Setenv(pathEnv, path)
+			}
+
+			cfg, err := Load(args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Listen.Port != tt.wantPort {
+				t.Errorf("listen.port = %q, want %q", cfg.Listen.Port, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestLoadDefaultPath(t *testing.T) {
+	clearEnv(t)
+	// Без config.yml в рабочем каталоге конфигурация берётся из окружения
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	t.Setenv("LISTEN_PORT", "9300")
+	cfg, err := Load(Args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Listen.Port != "9300" || cfg.IsDebug != nil {
+		t.Errorf("listen.port %q, is_debug %v", cfg.Listen.Port, cfg.IsDebug)
+	}
+}
+
+func TestSecretFiles(t *testing.T) {
+	secret := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "secret")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		env     func(t *testing.T) map[string]string
+		wantErr string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{"file values", func(t *testing.T) map[string]string {
+			return map[string]string{
+				"JWT_SECRET_FILE":       secret(t, "s3cr3t\n"),
+				"STORAGE_PASSWORD_FILE": secret(t, "pa ss\r\n"),
+				"DATABASE_URL_FILE":     secret(t, "postgres://file@db/journal"),
+			}
+		}, "", func(t *testing.T, cfg *Config) {
+			// Завершающий перевод строки отбрасывается, пробелы внутри сохраняются
+			if cfg.JWTSecret != "s3cr3t" || cfg.Storage.Password != "pa ss" || cfg.Storage.URL != "postgres://file@db/journal" {
+				t.Errorf("jwt %q, password %q, url %q", cfg.JWTSecret, cfg.Storage.Password, cfg.Storage.URL)
+			}
+		}},
+		{"file overrides config file", func(t *testing.T) map[string]string {
+			return map[string]string{"LOG_LEVEL_FILE": secret(t, "error")}
+		}, "", func(t *testing.T, cfg *Config) {
+			if cfg.Logging.Level != "error" {
+				t.Errorf("logging.level = %q", cfg.Logging.Level)
+			}
+		}},
+		{"both variable and file", func(t *testing.T) map[string]string {
+			return map[string]string{"JWT_SECRET": "inline", "JWT_SECRET_FILE": secret(t, "file")}
+		}, "both JWT_SECRET and JWT_SECRET_FILE are set", nil},
+		{"missing file", func(t *testing.T) map[string]string {
+			return map[string]string{"STORAGE_PASSWORD_FILE": filepath.Join(t.TempDir(), "absent")}
+		}, "STORAGE_PASSWORD_FILE", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env(t) {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load(Args{Path: writeConfig(t, testConfigFile)})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    Args
+		wantErr bool
+	}{
+		{"serve", nil, Args{}, false},
+		{"serve with flags", []string{"-port", "9000", "-storage", "sqlite"}, Args{Port: "9000", Storage: "sqlite"}, false},
+		{"print", []string{"config", "print", "--redacted", "-config", "prod.yml"}, Args{Command: CommandPrint, Redacted: true, Path: "prod.yml"}, false},
+		// --redacted есть только у config print
+		{"redacted without print", []string{"--redacted"}, Args{}, true},
+		{"unknown subcommand", []string{"config", "dump"}, Args{}, true},
+		{"extra argument", []string{"serve"}, Args{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseArgs error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseArgs = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redactedValue заменяет значения полей с тегом secret:"true"
+const redactedValue = "***"
+
+// Print выводит конфигурацию в YAML в формате файла конфигурации; длительности пишутся строками (15s).
+// При redacted значения секретов заменяются на ***.
+func Print(w io.Writer, cfg *Config, redacted bool) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(toNode(reflect.ValueOf(cfg).Elem(), redacted)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func toNode(v reflect.Value, redacted bool) *yaml.Node {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		return toNode(v.Elem(), redacted)
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: v.Interface().(time.Duration).String()}
+	case v.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			value := toNode(v.Field(i), redacted)
+			if redacted && f.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+				value = &yaml.Node{Kind: yaml.ScalarNode, Value: redactedValue}
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, value)
+		}
+		return node
+	default:
+		node := &yaml.Node{}
+		if err := node.Encode(v.Interface()); err != nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: ""}
+		}
+		return node
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate проверяет значения, которые cleanenv не может проверить сам, и возвращает все найденные ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	oneOf := func(name, value string, allowed ...string) {
+		if !slices.Contains(allowed, strings.ToLower(value)) {
+			add("%s: must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+		}
+	}
+
+	if c.IsDebug == nil {
+		add("is_debug: required")
+	}
+
+	switch c.Listen.Type {
+	case "port":
+		if _, err := strconv.ParseUint(c.Listen.Port, 10, 16); err != nil {
+			add("listen.port: invalid port %q", c.Listen.Port)
+		}
+	case "sock":
+	default:
+		add("listen.type: must be port or sock, got %q", c.Listen.Type)
+	}
+
+	oneOf("storage.driver", c.Storage.Driver, StoragePostgres, StorageSQLite, StorageMongoDB, StorageMemory)
+	switch strings.ToLower(c.Storage.Driver) {
+	case StoragePostgres:
+		if c.Storage.URL != "" {
+			if u, err := url.Parse(c.Storage.URL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+				add("storage.url: must be a postgres:// or postgresql:// URL")
+			}
+		} else if c.Storage.Host == "" || c.Storage.Database == "" {
+			add("storage: host and database are required unless url or DATABASE_URL is set")
+		}
+		if c.Storage.TxMaxAttempts < 1 {
+			add("storage.tx_max_attempts: must be at least 1, got %d", c.Storage.TxMaxAttempts)
+		}
+	case StorageSQLite:
+		if c.Storage.Path == "" {
+			add("storage.path: must not be empty for the sqlite driver")
+		}
+	case StorageMongoDB:
+		if c.Storage.MongoDB.Host == "" || c.Storage.MongoDB.Database == "" {
+			add("storage.mongodb: host and database are required for the mongodb driver")
+		}
+	}
+	if c.JWTSecret == "" {
+		add("jwt_secret: must not be empty")
+	}
+	if c.Auth.BcryptCost < bcrypt.MinCost || c.Auth.BcryptCost > bcrypt.MaxCost {
+		add("auth.bcrypt_cost: must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.Auth.BcryptCost)
+	}
+
+	oneOf("logging.format", c.Logging.Format, "json", "text")
+	oneOf("logging.level", c.Logging.Level, "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic")
+	for _, output := range c.Logging.Outputs {
+		oneOf("logging.outputs", strings.TrimSpace(output), "stdout", "stderr", "file")
+	}
+	oneOf("tracing.exporter", c.Tracing.Exporter, "none", "otlp", "stdout", "file")
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio: must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	if c.TLS.Enabled {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			add("tls: cert_file and key_file are required")
+		}
+		oneOf("tls.client_auth", c.TLS.ClientAuth, "none", "optional", "require")
+		if c.TLS.ClientAuth != "none" && c.TLS.ClientCAFile == "" {
+			add("tls.client_ca_file: required for client_auth %s", c.TLS.ClientAuth)
+		}
+		if c.TLS.ReloadInterval <= 0 {
+			add("tls.reload_interval: must be positive")
+		}
+	}
+
+	if c.Server.MaxBodyBytes <= 0 {
+		add("server.max_body_bytes: must be positive")
+	}
+	if c.Server.ShutdownDelay < 0 {
+		add("server.shutdown_delay: must not be negative")
+	}
+	if c.Imports.MaxJobsPerUser < 1 {
+		add("imports.max_jobs_per_user: must be at least 1, got %d", c.Imports.MaxJobsPerUser)
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"auth.token_ttl", c.Auth.TokenTTL},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"erasure.grace_period", c.Erasure.GracePeriod},
+		{"erasure.check_interval", c.Erasure.CheckInterval},
+		{"trash.retention", c.Trash.Retention},
+		{"trash.purge_interval", c.Trash.PurgeInterval},
+		{"audit.retention", c.Audit.Retention},
+		{"audit.purge_interval", c.Audit.PurgeInterval},
+		{"imports.job_ttl", c.Imports.JobTTL},
+		{"imports.review_timeout", c.Imports.ReviewTimeout},
+		{"imports.evict_interval", c.Imports.EvictInterval},
+		{"health.database_timeout", c.Health.DatabaseTimeout},
+		{"health.schema_timeout", c.Health.SchemaTimeout},
+	} {
+		if d.value <= 0 {
+			add("%s: must be positive", d.name)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig конфигурация по умолчанию с обязательными полями PostgreSQL
+func validConfig(t *testing.T) *Config {
+	t.Helper()
+	clearEnv(t)
+	cfg, err := Load(Args{Path: writeConfig(t, testConfigFile)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string // Подстроки ошибки; пусто для корректной конфигурации
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"missing is_debug", func(c *Config) { c.IsDebug = nil }, []string{"is_debug: required"}},
+		{"bad port", func(c *Config) { c.Listen.Port = "70000" }, []string{`listen.port: invalid port "70000"`}},
+		{"socket ignores port", func(c *Config) { c.Listen.Type, c.Listen.Port = "sock", "" }, nil},
+		{"unknown listen type", func(c *Config) { c.Listen.Type = "pipe" }, []string{"listen.type"}},
+		{"unknown driver", func(c *Config) { c.Storage.Driver = "oracle" }, []string{"storage.driver: must be one of"}},
+		{"driver is case-insensitive", func(c *Config) { c.Storage.Driver = "Memory" }, nil},
+		{"postgres without host", func(c *Config) { c.Storage.Host = "" }, []string{"storage: host and database are required"}},
+		{"postgres url replaces host", func(c *Config) { c.Storage.Host, c.Storage.URL = "", "postgresql://u@db/journal" }, nil},
+		{"non-postgres url", func(c *Config) { c.Storage.URL = "mysql://db/journal" }, []string{"storage.url"}},
+		{"no tx attempts", func(c *Config) { c.Storage.TxMaxAttempts = 0 }, []string{"storage.tx_max_attempts"}},
+		{"sqlite without path", func(c *Config) { c.Storage.Driver, c.Storage.Path = "sqlite", "" }, []string{"storage.path"}},
+		{"mongodb without host", func(c *Config) { c.Storage.Driver = "mongodb" }, []string{"storage.mongodb"}},
+		{"empty jwt secret", func(c *Config) { c.JWTSecret = "" }, []string{"jwt_secret"}},
+		{"bcrypt cost", func(c *Config) { c.Auth.BcryptCost = 2 }, []string{"auth.bcrypt_cost: must be between 4 and 31, got 2"}},
+		{"log level", func(c *Config) { c.Logging.Level = "verbose" }, []string{"logging.level"}},
+		{"log output", func(c *Config) { c.Logging.Outputs = []string{"stdout", " syslog"} }, []string{`logging.outputs: must be one of stdout, stderr, file, got "syslog"`}},
+		{"sample ratio", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, []string{"tracing.sample_ratio"}},
+		{"tls without files", func(c *Config) { c.TLS.Enabled = true }, []string{"tls: cert_file and key_file are required"}},
+		{"mtls without CA", func(c *Config) {
+			c.TLS.Enabled, c.TLS.CertFile, c.TLS.KeyFile, c.TLS.ClientAuth = true, "server.crt", "server.key", "require"
+		}, []string{"tls.client_ca_file: required for client_auth require"}},
+		// Параметры TLS не проверяются, пока TLS выключен
+		{"tls disabled", func(c *Config) { c.TLS.ClientAuth = "bogus" }, nil},
+		{"max body", func(c *Config) { c.Server.MaxBodyBytes = 0 }, []string{"server.max_body_bytes"}},
+		{"negative shutdown delay", func(c *Config) { c.Server.ShutdownDelay = -time.Second }, []string{"server.shutdown_delay"}},
+		{"max jobs", func(c *Config) { c.Imports.MaxJobsPerUser = 0 }, []string{"imports.max_jobs_per_user"}},
+		{"zero duration", func(c *Config) { c.Trash.PurgeInterval = 0 }, []string{"trash.purge_interval: must be positive"}},
+		// Все ошибки возвращаются сразу
+		{"several errors", func(c *Config) {
+			c.IsDebug, c.JWTSecret, c.Audit.Retention = nil, "", -time.Hour
+		}, []string{"is_debug", "jwt_secret", "audit.retention"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate = nil, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate = %v, want %q", err, want)
+				}
+			}
+			if lines := strings.Count(err.Error(), "\n") + 1; lines != len(tt.want) {
+				t.Errorf("got %d errors, want %d: %v", lines, len(tt.want), err)
+			}
+		})
+	}
+}
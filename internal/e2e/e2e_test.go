@@ -0,0 +1,624 @@
+// Package e2e проверяет сценарии API целиком: HTTP-обработчики, аутентификация и репозитории в памяти.
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	auditMemory "fit-journal/internal/audit/memory"
+	"fit-journal/internal/auth"
+	"fit-journal/internal/config"
+	"fit-journal/internal/entities/exercise"
+	exerciseMemory "fit-journal/internal/entities/exercise/memory"
+	"fit-journal/internal/entities/metric"
+	metricMemory "fit-journal/internal/entities/metric/memory"
+	"fit-journal/internal/entities/user"
+	userMemory "fit-journal/internal/entities/user/memory"
+	"fit-journal/internal/entities/workout"
+	workoutMemory "fit-journal/internal/entities/workout/memory"
+	"fit-journal/internal/erasure"
+	erasureMemory "fit-journal/internal/erasure/memory"
+	"fit-journal/internal/export"
+	"fit-journal/internal/imports"
+	"fit-journal/internal/middleware"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/uow"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// client тестовый клиент API с токеном текущего пользователя
+type client struct {
+	t      *testing.T
+	server *httptest.Server
+	token  string
+}
+
+// newClient поднимает API с обработчиками пользователей, тренировок, метрик, выгрузки и импорта
+// поверх репозиториев в памяти
+func newClient(t *testing.T) *client {
+	t.Helper()
+	logger := logging.GetLogger()
+	authService := auth.NewService(auth.Options{Secret: []byte("test-secret"), BcryptCost: bcrypt.MinCost})
+
+	users := userMemory.NewRepository()
+	workouts := workoutMemory.NewRepository()
+	metrics := metricMemory.NewRepository()
+	auditLog := auditMemory.NewRepository()
+	recorder := audit.NewRecorder(auditLog)
+	erasureService := erasure.NewService(erasureMemory.NewRepository(users, workouts, metrics, auditLog), workouts, metrics, auditLog, logger,
+		config.ErasureConfig{GracePeriod: time.Hour, CheckInterval: time.Hour, ExportDir: t.TempDir()})
+
+	router := httprouter.New()
+	user.NewHandler(users, uow.Serial(), erasureService, recorder, authService).Register(router)
+	workout.NewHandler(workouts, users, uow.Serial(), time.Hour, recorder, authService).Register(router)
+	metric.NewHandler(metrics, users, recorder, authService).Register(router)
+	export.NewHandler(users, workouts, metrics, authService).Register(router)
+	jobs := imports.NewJobStore(config.ImportsConfig{JobTTL: time.Hour, ReviewTimeout: time.Hour, MaxJobsPerUser: 10, EvictInterval: time.Hour})
+	imports.NewHandler(workouts, users, exerciseMemory.NewRepository(), metrics, uow.Serial(), jobs, recorder, authService).Register(router)
+
+	server := httptest.NewServer(middleware.Chain(router, middleware.RequestID, middleware.Recover))
+	t.Cleanup(server.Close)
+	return &client{t: t, server: server}
+}
+
+// do выполняет запрос с JSON-телом и декодирует JSON-ответ в out, если он передан. Возвращает код ответа.
+func (c *client) do(method, path string, body, out interface{}) int {
+	c.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.server.URL+path, reader)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.server.Client().Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < http.StatusBadRequest {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			c.t.Fatalf("%s %s: decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// expect выполняет запрос и проверяет код ответа
+func (c *client) expect(status int, method, path string, body, out interface{}) {
+	c.t.Helper()
+	if got := c.do(method, path, body, out); got != status {
+		c.t.Fatalf("%s %s: expected status %d, got %d", method, path, status, got)
+	}
+}
+
+// signUp регистрирует пользователя и входит от его имени
+func (c *client) signUp(username, password string) {
+	c.t.Helper()
+	c.expect(http.StatusCreated, http.MethodPost, "/auth/register", user.CreateUserDTO{Username: username, Password: password, BirthDate: "1990-05-01"}, nil)
+	c.logIn(username, password)
+}
+
+// logIn входит от имени зарегистрированного пользователя
+func (c *client) logIn(username, password string) {
+	c.t.Helper()
+	c.token = ""
+	var login struct {
+		Token string `json:"token"`
+	}
+	c.expect(http.StatusOK, http.MethodPost, "/auth/login", map[string]string{"username": username, "password": password}, &login)
+	if login.Token == "" {
+		c.t.Fatal("login returned an empty token")
+	}
+	c.token = login.Token
+}
+
+func TestRegisterLoginCreateWorkoutAddSet(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	var created workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", workout.CreateWorkoutDTO{StartTime: 1700000000}, &created)
+	if created.ID == 0 || created.StartTime != 1700000000 || created.Kind != workout.KindStrength {
+		t.Fatalf("unexpected workout: %+v", created)
+	}
+
+	var withExercise workout.Workout
+	c.expect(http.StatusOK, http.MethodPut, fmt.Sprintf("/workouts/%d", created.ID),
+		exercise.Exercise{Name: "Squat", Sets: []exercise.ExerciseSet{{Reps: 5, Weight: 100}}}, &withExercise)
+	if len(withExercise.Exercises) != 1 {
+		t.Fatalf("expected one exercise, got %+v", withExercise.Exercises)
+	}
+	exerciseID := withExercise.Exercises[0].ID
+
+	c.expect(http.StatusOK, http.MethodPost, fmt.Sprintf("/workouts/%d/exercises/%d", created.ID, exerciseID),
+		exercise.ExerciseSet{Reps: 3, Weight: 110}, nil)
+
+	var stored workout.Workout
+	c.expect(http.StatusOK, http.MethodGet, fmt.Sprintf("/workouts/%d", created.ID), nil, &stored)
+	sets := stored.Exercises[0].Sets
+	if len(sets) != 2 || sets[1].Reps != 3 || sets[1].Weight != 110 || sets[1].ID == 0 {
+		t.Fatalf("expected the added set to be stored, got %+v", sets)
+	}
+
+	var all []workout.Workout
+	c.expect(http.StatusOK, http.MethodGet, "/workouts", nil, &all)
+	if len(all) != 1 || all[0].ID != created.ID {
+		t.Fatalf("expected the created workout in the list, got %+v", all)
+	}
+}
+
+func TestWorkoutsAreScopedToUser(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", nil, nil)
+
+	c.signUp("bob", "hunter2")
+	var all []workout.Workout
+	c.expect(http.StatusOK, http.MethodGet, "/workouts", nil, &all)
+	if len(all) != 0 {
+		t.Fatalf("bob must not see alice's workouts, got %+v", all)
+	}
+}
+
+func TestRegisterDuplicateUsername(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+	c.expect(http.StatusConflict, http.MethodPost, "/auth/register", user.CreateUserDTO{Username: "alice", Password: "other"}, nil)
+}
+
+func TestLoginFailures(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+	c.token = ""
+
+	// Неизвестное имя не отличается от неверного пароля ни статусом, ни текстом ошибки
+	wrongPassword := c.problem(http.MethodPost, "/auth/login", map[string]string{"username": "alice", "password": "wrong"})
+	unknownUser := c.problem(http.MethodPost, "/auth/login", map[string]string{"username": "nobody", "password": "s3cret"})
+	if wrongPassword.Status != http.StatusUnauthorized || unknownUser.Status != http.StatusUnauthorized ||
+		wrongPassword.Type != unknownUser.Type || wrongPassword.Detail != unknownUser.Detail {
+		t.Errorf("login failures differ: %+v and %+v", wrongPassword, unknownUser)
+	}
+	c.expect(http.StatusBadRequest, http.MethodPost, "/auth/login", map[string]string{"username": "alice"}, nil)
+}
+
+func TestWorkoutRequiresToken(t *testing.T) {
+	c := newClient(t)
+	c.expect(http.StatusUnauthorized, http.MethodPost, "/workouts", nil, nil)
+
+	c.token = "not-a-jwt"
+	c.expect(http.StatusUnauthorized, http.MethodPost, "/workouts", nil, nil)
+}
+
+func TestAddSetToUnknownExercise(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	var created workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", nil, &created)
+	c.expect(http.StatusNotFound, http.MethodPost, fmt.Sprintf("/workouts/%d/exercises/%d", created.ID, 404),
+		exercise.ExerciseSet{Reps: 3, Weight: 110}, nil)
+	c.expect(http.StatusBadRequest, http.MethodPost, fmt.Sprintf("/workouts/%d/exercises/abc", created.ID),
+		exercise.ExerciseSet{Reps: 3, Weight: 110}, nil)
+}
+
+func TestDomainErrorStatuses(t *testing.T) {
+	c := newClient(t)
+	c.signUp("bob", "hunter2")
+	c.signUp("alice", "s3cret")
+
+	// Отсутствующая тренировка отдаёт 404, а не 500, хотя обработчик не проверяет ошибку сам
+	c.expect(http.StatusNotFound, http.MethodGet, "/workouts/999", nil, nil)
+	c.expect(http.StatusNotFound, http.MethodPost, "/workouts/999/exercises/1", exercise.ExerciseSet{Reps: 1}, nil)
+
+	// Занятое имя при обновлении профиля отдаёт 409
+	c.expect(http.StatusConflict, http.MethodPut, "/users", map[string]string{"username": "bob"}, nil)
+}
+
+// problem выполняет запрос и возвращает ответ с ошибкой, проверяя тип содержимого
+func (c *client) problem(method, path string, body interface{}) apperror.Problem {
+	c.t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req, err := http.NewRequest(method, c.server.URL+path, bytes.NewReader(data))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.server.Client().Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != apperror.ProblemContentType {
+		c.t.Fatalf("%s %s: expected Content-Type %s, got %q", method, path, apperror.ProblemContentType, ct)
+	}
+	var p apperror.Problem
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		c.t.Fatalf("%s %s: decode problem: %v", method, path, err)
+	}
+	if p.Status != resp.StatusCode || p.Type == "" || p.Title == "" || p.Instance == "" {
+		c.t.Fatalf("%s %s: incomplete problem %+v for status %d", method, path, p, resp.StatusCode)
+	}
+	if p.DeveloperMessage != "" {
+		c.t.Fatalf("%s %s: developer_message must be hidden outside debug mode, got %q", method, path, p.DeveloperMessage)
+	}
+	return p
+}
+
+func TestProblemResponses(t *testing.T) {
+	c := newClient(t)
+
+	p := c.problem(http.MethodPost, "/auth/register", map[string]string{"birth_date": "1990-05-01"})
+	if p.Status != http.StatusBadRequest || len(p.Errors) != 2 || p.Errors[0].Field != "username" || p.Errors[1].Field != "password" {
+		t.Fatalf("expected violations for username and password, got %+v", p)
+	}
+
+	// Отказ аутентификации выводится тем же форматом
+	p = c.problem(http.MethodGet, "/workouts", nil)
+	if p.Status != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %+v", p)
+	}
+	c.token = "not-a-jwt"
+	if p = c.problem(http.MethodGet, "/workouts", nil); p.Status != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %+v", p)
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	c := newClient(t)
+
+	p := c.problem(http.MethodPost, "/auth/register", map[string]string{
+		"username": "alice", "password": "s3cret", "birth_date": "2999-01-01", "height": "tall",
+	})
+	if p.Status != http.StatusBadRequest || len(p.Errors) != 2 || p.Errors[0].Field != "birth_date" || p.Errors[1].Field != "height" {
+		t.Fatalf("expected violations for birth_date and height, got %+v", p)
+	}
+	p = c.problem(http.MethodPost, "/auth/register", map[string]string{"username": "alice", "password": "s3cret", "role": "admin"})
+	if len(p.Errors) != 1 || p.Errors[0].Field != "role" {
+		t.Fatalf("expected the unknown field to be rejected, got %+v", p)
+	}
+
+	c.signUp("alice", "s3cret")
+	var created workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", nil, &created)
+
+	p = c.problem(http.MethodPut, fmt.Sprintf("/workouts/%d", created.ID), map[string]interface{}{
+		"name": "Squat", "sets": []map[string]interface{}{{"reps": 5, "weight": 100}, {"reps": -1, "weight": 100}},
+	})
+	if len(p.Errors) != 1 || p.Errors[0].Field != "sets[1].reps" {
+		t.Fatalf("expected a violation for sets[1].reps, got %+v", p)
+	}
+	p = c.problem(http.MethodPost, fmt.Sprintf("/workouts/%d/exercises/1", created.ID), map[string]interface{}{"reps": "five"})
+	if len(p.Errors) != 1 || p.Errors[0].Field != "reps" {
+		t.Fatalf("expected a type violation for reps, got %+v", p)
+	}
+
+	// Новый пароль из обновления профиля применяется
+	c.expect(http.StatusNoContent, http.MethodPut, "/users", map[string]string{"password": "n3w-secret"}, nil)
+	c.token = ""
+	c.expect(http.StatusOK, http.MethodPost, "/auth/login", map[string]string{"username": "alice", "password": "n3w-secret"}, nil)
+}
+
+func TestGroupsAreScopedToOwner(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	var created workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", nil, &created)
+	var stored workout.Workout
+	for _, name := range []string{"Bench press", "Pull-up"} {
+		c.expect(http.StatusOK, http.MethodPut, fmt.Sprintf("/workouts/%d", created.ID), exercise.Exercise{Name: name}, &stored)
+	}
+	superset := exercise.CreateGroupDTO{Type: exercise.GroupSuperset, ExerciseIDs: []int64{stored.Exercises[0].ID, stored.Exercises[1].ID}}
+	c.expect(http.StatusCreated, http.MethodPost, fmt.Sprintf("/workouts/%d/groups", created.ID), superset, &stored)
+	groupURL := fmt.Sprintf("/workouts/%d/groups/%d", created.ID, stored.Groups[0].ID)
+
+	// Чужая тренировка неотличима от отсутствующей
+	c.signUp("bob", "hunter2")
+	c.expect(http.StatusNotFound, http.MethodPost, fmt.Sprintf("/workouts/%d/groups", created.ID), superset, nil)
+	mine := "mine"
+	c.expect(http.StatusNotFound, http.MethodPut, groupURL, exercise.UpdateGroupDTO{Name: &mine}, nil)
+	c.expect(http.StatusNotFound, http.MethodDelete, groupURL, nil, nil)
+	c.expect(http.StatusNotFound, http.MethodGet, fmt.Sprintf("/workouts/%d/summary", created.ID), nil, nil)
+
+	c.logIn("alice", "s3cret")
+	c.expect(http.StatusOK, http.MethodGet, fmt.Sprintf("/workouts/%d/summary", created.ID), nil, nil)
+}
+
+func TestCardioIsScopedToOwner(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	run := workout.Cardio{Activity: workout.ActivityRun, DistanceMeters: 5000, MovingTimeSec: 1500}
+	var created workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", workout.CreateWorkoutDTO{Kind: workout.KindCardio, Cardio: &run}, &created)
+
+	c.signUp("bob", "hunter2")
+	c.expect(http.StatusNotFound, http.MethodPut, fmt.Sprintf("/workouts/%d/cardio", created.ID),
+		workout.Cardio{Activity: workout.ActivityRide, DistanceMeters: 1, MovingTimeSec: 1}, nil)
+
+	c.logIn("alice", "s3cret")
+	var stored workout.Workout
+	c.expect(http.StatusOK, http.MethodGet, fmt.Sprintf("/workouts/%d", created.ID), nil, &stored)
+	if stored.Cardio == nil || stored.Cardio.Activity != workout.ActivityRun || stored.Cardio.DistanceMeters != 5000 {
+		t.Fatalf("bob must not change alice's cardio, got %+v", stored.Cardio)
+	}
+}
+
+func TestWorkoutIsScopedToOwner(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	var created, trashed, stored workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", nil, &created)
+	workoutURL := fmt.Sprintf("/workouts/%d", created.ID)
+	c.expect(http.StatusOK, http.MethodPut, workoutURL,
+		exercise.Exercise{Name: "Squat", Sets: []exercise.ExerciseSet{{Reps: 5, Weight: 100}}}, &stored)
+	exerciseURL := fmt.Sprintf("%s/exercises/%d", workoutURL, stored.Exercises[0].ID)
+	setURL := fmt.Sprintf("%s/sets/%d", exerciseURL, stored.Exercises[0].Sets[0].ID)
+
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", nil, &trashed)
+	trashedURL := fmt.Sprintf("/workouts/%d", trashed.ID)
+	c.expect(http.StatusNoContent, http.MethodDelete, trashedURL, nil, nil)
+
+	// Чужая тренировка для bob не существует: ни прочитать, ни изменить, ни удалить
+	c.signUp("bob", "hunter2")
+	c.expect(http.StatusNotFound, http.MethodGet, workoutURL, nil, nil)
+	c.expect(http.StatusNotFound, http.MethodGet, trashedURL+"?include_trashed=true", nil, nil)
+	c.expect(http.StatusNotFound, http.MethodPut, workoutURL, exercise.Exercise{Name: "Lunge"}, nil)
+	c.expect(http.StatusNotFound, http.MethodPost, exerciseURL, exercise.ExerciseSet{Reps: 1, Weight: 1}, nil)
+	c.expect(http.StatusNotFound, http.MethodDelete, setURL, nil, nil)
+	c.expect(http.StatusNotFound, http.MethodDelete, exerciseURL, nil, nil)
+	c.expect(http.StatusNotFound, http.MethodDelete, workoutURL, nil, nil)
+
+	c.logIn("alice", "s3cret")
+	c.expect(http.StatusOK, http.MethodGet, workoutURL, nil, &stored)
+	if len(stored.Exercises) != 1 || stored.Exercises[0].Name != "Squat" || len(stored.Exercises[0].Sets) != 1 {
+		t.Fatalf("bob must not change alice's workout, got %+v", stored)
+	}
+	c.expect(http.StatusOK, http.MethodGet, trashedURL+"?include_trashed=true", nil, nil)
+}
+
+func TestCardioExcludesExercises(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	var created, stored workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", nil, &created)
+	workoutURL := fmt.Sprintf("/workouts/%d", created.ID)
+	c.expect(http.StatusOK, http.MethodPut, workoutURL, exercise.Exercise{Name: "Squat"}, &stored)
+
+	run := workout.Cardio{Activity: workout.ActivityRun, DistanceMeters: 5000, MovingTimeSec: 1500}
+	c.expect(http.StatusConflict, http.MethodPut, workoutURL+"/cardio", run, nil)
+
+	// Упражнение из корзины не мешает смене вида, но вернуть его в кардио-тренировку нельзя
+	exerciseURL := fmt.Sprintf("%s/exercises/%d", workoutURL, stored.Exercises[0].ID)
+	c.expect(http.StatusNoContent, http.MethodDelete, exerciseURL, nil, nil)
+	c.expect(http.StatusOK, http.MethodPut, workoutURL+"/cardio", run, &stored)
+	if stored.Kind != workout.KindCardio || len(stored.Exercises) != 0 {
+		t.Fatalf("expected a cardio workout without exercises, got %+v", stored)
+	}
+	c.expect(http.StatusConflict, http.MethodPost, "/trash"+exerciseURL+"/restore", nil, nil)
+	c.expect(http.StatusConflict, http.MethodPut, workoutURL, exercise.Exercise{Name: "Lunge"}, nil)
+}
+
+func TestWeeklyStatsRange(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	c.expect(http.StatusOK, http.MethodGet, "/stats/weekly?weeks=52", nil, nil)
+	for _, weeks := range []string{"0", "53", "100000", "abc"} {
+		p := c.problem(http.MethodGet, "/stats/weekly?weeks="+weeks, nil)
+		if p.Status != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "weeks" {
+			t.Errorf("weeks=%s: expected a violation for weeks, got %+v", weeks, p)
+		}
+	}
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	var created, stored workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", nil, &created)
+	for _, name := range []string{"Squat", "Lunge"} {
+		c.expect(http.StatusOK, http.MethodPut, fmt.Sprintf("/workouts/%d", created.ID),
+			exercise.Exercise{Name: name, Sets: []exercise.ExerciseSet{{Reps: 5, Weight: 60}, {Reps: 5, Weight: 70}}}, &stored)
+	}
+	workoutURL := fmt.Sprintf("/workouts/%d", created.ID)
+	squat, lunge := stored.Exercises[0], stored.Exercises[1]
+	setID := squat.Sets[1].ID
+
+	c.expect(http.StatusNoContent, http.MethodDelete, fmt.Sprintf("%s/exercises/%d/sets/%d", workoutURL, squat.ID, setID), nil, nil)
+	c.expect(http.StatusNoContent, http.MethodDelete, fmt.Sprintf("%s/exercises/%d", workoutURL, lunge.ID), nil, nil)
+	c.expect(http.StatusOK, http.MethodGet, workoutURL, nil, &stored)
+	if len(stored.Exercises) != 1 || len(stored.Exercises[0].Sets) != 1 {
+		t.Fatalf("trashed items must be hidden, got %+v", stored.Exercises)
+	}
+
+	var trash workout.Trash
+	c.expect(http.StatusOK, http.MethodGet, "/trash", nil, &trash)
+	if len(trash.Exercises) != 1 || trash.Exercises[0].Exercise.ID != lunge.ID || len(trash.Sets) != 1 || trash.Sets[0].Set.ID != setID {
+		t.Fatalf("unexpected trash %+v", trash)
+	}
+
+	// Чужую корзину не видно и восстановить из неё ничего нельзя
+	c.signUp("bob", "hunter2")
+	c.expect(http.StatusOK, http.MethodGet, "/trash", nil, &trash)
+	if len(trash.Exercises) != 0 || len(trash.Sets) != 0 {
+		t.Fatalf("bob must not see alice's trash, got %+v", trash)
+	}
+	c.expect(http.StatusNotFound, http.MethodPost, fmt.Sprintf("/trash%s/exercises/%d/restore", workoutURL, lunge.ID), nil, nil)
+
+	c.logIn("alice", "s3cret")
+	c.expect(http.StatusOK, http.MethodPost, fmt.Sprintf("/trash%s/exercises/%d/restore", workoutURL, lunge.ID), nil, &stored)
+	c.expect(http.StatusOK, http.MethodPost, fmt.Sprintf("/trash%s/exercises/%d/sets/%d/restore", workoutURL, squat.ID, setID), nil, &stored)
+	if len(stored.Exercises) != 2 || len(stored.Exercises[0].Sets) != 2 {
+		t.Fatalf("expected restored exercise and set, got %+v", stored.Exercises)
+	}
+	// Восстановить можно только то, что лежит в корзине
+	c.expect(http.StatusNotFound, http.MethodPost, fmt.Sprintf("/trash%s/exercises/%d/restore", workoutURL, lunge.ID), nil, nil)
+
+	c.expect(http.StatusNoContent, http.MethodDelete, workoutURL, nil, nil)
+	c.expect(http.StatusNotFound, http.MethodGet, workoutURL, nil, nil)
+	c.expect(http.StatusOK, http.MethodGet, "/trash", nil, &trash)
+	if len(trash.Workouts) != 1 || trash.Workouts[0].ID != created.ID || trash.Workouts[0].PurgeAt <= 0 {
+		t.Fatalf("expected the workout in the trash, got %+v", trash.Workouts)
+	}
+	c.expect(http.StatusOK, http.MethodPost, fmt.Sprintf("/trash%s/restore", workoutURL), nil, nil)
+	c.expect(http.StatusOK, http.MethodGet, workoutURL, nil, &stored)
+	if stored.DeletedAt != nil || len(stored.Exercises) != 2 {
+		t.Fatalf("unexpected restored workout %+v", stored)
+	}
+}
+
+// raw выполняет запрос с телом body как есть и возвращает код и тело ответа
+func (c *client) raw(method, path string, body []byte) (int, []byte) {
+	c.t.Helper()
+	req, err := http.NewRequest(method, c.server.URL+path, bytes.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.server.Client().Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return resp.StatusCode, data
+}
+
+// dataset тренировки и метрики текущего пользователя без идентификаторов, назначаемых при создании
+func (c *client) dataset() ([]workout.Workout, []metric.Metric) {
+	c.t.Helper()
+	var workouts []workout.Workout
+	c.expect(http.StatusOK, http.MethodGet, "/workouts", nil, &workouts)
+	for i := range workouts {
+		workouts[i].ID, workouts[i].UserID = 0, 0
+	}
+	var metrics []metric.Metric
+	c.expect(http.StatusOK, http.MethodGet, "/users/metrics", nil, &metrics)
+	for i := range metrics {
+		metrics[i].ID, metrics[i].UserID = 0, 0
+	}
+	return workouts, metrics
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+
+	var strength, stored workout.Workout
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", workout.CreateWorkoutDTO{StartTime: 1700000000}, &strength)
+	for _, name := range []string{"Bench press", "Pull-up"} {
+		c.expect(http.StatusOK, http.MethodPut, fmt.Sprintf("/workouts/%d", strength.ID),
+			exercise.Exercise{Name: name, Sets: []exercise.ExerciseSet{{Reps: 5, Weight: 80}, {Reps: 5, Weight: 85}}}, &stored)
+	}
+	superset := exercise.CreateGroupDTO{Type: exercise.GroupSuperset, ExerciseIDs: []int64{stored.Exercises[0].ID, stored.Exercises[1].ID}}
+	c.expect(http.StatusCreated, http.MethodPost, fmt.Sprintf("/workouts/%d/groups", strength.ID), superset, nil)
+	// Подход из корзины в выгрузку не попадает
+	c.expect(http.StatusNoContent, http.MethodDelete,
+		fmt.Sprintf("/workouts/%d/exercises/%d/sets/%d", strength.ID, stored.Exercises[0].ID, stored.Exercises[0].Sets[1].ID), nil, nil)
+	run := workout.Cardio{Activity: workout.ActivityRun, DistanceMeters: 5000, MovingTimeSec: 1500, AvgHR: 150}
+	c.expect(http.StatusCreated, http.MethodPost, "/workouts", workout.CreateWorkoutDTO{StartTime: 1700086400, Kind: workout.KindCardio, Cardio: &run}, nil)
+	for _, m := range []metric.CreateMetricDTO{{Day: "2024-03-01", Weight: "80.5"}, {Day: "2024-03-02", CaloriesConsumed: "2500"}} {
+		c.expect(http.StatusCreated, http.MethodPost, "/users/metrics", m, nil)
+	}
+	wantWorkouts, wantMetrics := c.dataset()
+
+	status, jsonExport := c.raw(http.MethodGet, "/export", nil)
+	if status != http.StatusOK {
+		t.Fatalf("GET /export: status %d", status)
+	}
+	status, zipExport := c.raw(http.MethodGet, "/export?format=zip", nil)
+	if status != http.StatusOK {
+		t.Fatalf("GET /export?format=zip: status %d", status)
+	}
+
+	// Выгрузка в любом формате восстанавливается у другого пользователя в то же содержимое
+	for username, body := range map[string][]byte{"bob": jsonExport, "carol": zipExport} {
+		c.signUp(username, "hunter2")
+		var result imports.ExportImportResult
+		status, data := c.raw(http.MethodPost, "/imports/export", body)
+		if status != http.StatusOK || json.Unmarshal(data, &result) != nil {
+			t.Fatalf("%s: POST /imports/export: status %d, body %s", username, status, data)
+		}
+		if result != (imports.ExportImportResult{WorkoutsCreated: 2, MetricsCreated: 2}) {
+			t.Errorf("%s: import result = %+v", username, result)
+		}
+		gotWorkouts, gotMetrics := c.dataset()
+		if !reflect.DeepEqual(gotWorkouts, wantWorkouts) || !reflect.DeepEqual(gotMetrics, wantMetrics) {
+			t.Errorf("%s: imported dataset differs:\n%+v %+v\nwant\n%+v %+v", username, gotWorkouts, gotMetrics, wantWorkouts, wantMetrics)
+		}
+
+		// Повторный импорт ничего не дублирует
+		status, data = c.raw(http.MethodPost, "/imports/export", body)
+		if status != http.StatusOK || json.Unmarshal(data, &result) != nil ||
+			result != (imports.ExportImportResult{WorkoutsSkipped: 2, MetricsSkipped: 2}) {
+			t.Errorf("%s: repeated import: status %d, body %s", username, status, data)
+		}
+	}
+}
+
+func TestExportCSVSections(t *testing.T) {
+	c := newClient(t)
+	c.signUp("alice", "s3cret")
+	c.expect(http.StatusCreated, http.MethodPost, "/users/metrics", metric.CreateMetricDTO{Day: "2024-03-01", Weight: "80"}, nil)
+
+	tests := []struct {
+		query  string
+		status int
+		header string // Первая строка CSV
+	}{
+		{"format=csv", http.StatusOK, strings.Join(export.SetColumns, ",")},
+		{"format=csv&section=sets", http.StatusOK, strings.Join(export.SetColumns, ",")},
+		{"format=csv&section=metrics", http.StatusOK, strings.Join(export.MetricColumns, ",")},
+		// Профиль есть только в json и zip
+		{"format=csv&section=profile", http.StatusBadRequest, ""},
+		{"format=json&section=metrics", http.StatusBadRequest, ""},
+		{"format=xml", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		status, body := c.raw(http.MethodGet, "/export?"+tt.query, nil)
+		if status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.query, status, tt.status)
+			continue
+		}
+		if header, _, _ := strings.Cut(string(body), "\n"); tt.header != "" && header != tt.header {
+			t.Errorf("%s: header %q, want %q", tt.query, header, tt.header)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package e2e
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fit-journal/internal/audit"
+	auditMemory "fit-journal/internal/audit/memory"
+	"fit-journal/internal/auth"
+	userMemory "fit-journal/internal/entities/user/memory"
+	"fit-journal/internal/entities/workout"
+	workoutMemory "fit-journal/internal/entities/workout/memory"
+	"fit-journal/internal/middleware"
+	"fit-journal/internal/servertls"
+	"fit-journal/pkg/metrics"
+	"fit-journal/pkg/uow"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/crypto/bcrypt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// issue выпускает сертификат; parent == nil означает самоподписанный CA
+func issue(t *testing.T, cn string, parent *tls.Certificate, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	signer, signerKey := tmpl, interface{}(key)
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestServiceIdentity(t *testing.T) {
+	ca := issue(t, "test-ca", nil, x509.ExtKeyUsageAny)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	// Сервер собран как в main при client_auth optional: /metrics только для сервисов, API для всех
+	authService := auth.NewService(auth.Options{Secret: []byte("test-secret"), BcryptCost: bcrypt.MinCost})
+	router := httprouter.New()
+	workout.NewHandler(workoutMemory.NewRepository(), userMemory.NewRepository(), uow.Serial(), time.Hour, audit.NewRecorder(auditMemory.NewRepository()), authService).Register(router)
+	router.Handler(http.MethodGet, "/metrics", servertls.RequireService(metrics.Handler()))
+	server := httptest.NewUnstartedServer(middleware.Chain(router, middleware.RequestID, servertls.Identity([]string{"prometheus"})))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{issue(t, "fit-journal", &ca, x509.ExtKeyUsageServerAuth)},
+		ClientCAs:    pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name         string
+		service      string // CommonName клиентского сертификата; пусто — без сертификата
+		wantMetrics  int
+		wantWorkouts int // 401 означает, что запрос дошёл до проверки токена
+	}{
+		{"allowed service", "prometheus", http.StatusOK, http.StatusUnauthorized},
+		// Сертификат действителен, но сервис не в allowed_services: отказ на любом маршруте
+		{"service not allowed", "scanner", http.StatusForbidden, http.StatusForbidden},
+		{"no client certificate", "", http.StatusForbidden, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientTLS := &tls.Config{RootCAs: pool}
+			if tt.service != "" {
+				clientTLS.Certificates = []tls.Certificate{issue(t, tt.service, &ca, x509.ExtKeyUsageClientAuth)}
+			}
+			c := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+
+			for path, want := range map[string]int{"/metrics": tt.wantMetrics, "/workouts": tt.wantWorkouts} {
+				resp, err := c.Get(server.URL + path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != want {
+					t.Errorf("GET %s: status %d, want %d", path, resp.StatusCode, want)
+				}
+			}
+		})
+	}
+}
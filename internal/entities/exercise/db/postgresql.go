@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/pkg/client/postgresql"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"strings"
+)
+
+type Repository struct {
+	client postgresql.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// Create добавляет упражнение в каталог
+func (r *Repository) Create(ctx context.Context, ex exercise.Exercise) (int64, error) {
+	var id int64
+	q := `
+        INSERT INTO exercises
+            (name, sets, description)
+        VALUES
+            ($1, $2, $3)
+        RETURNING id
+    `
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	sets := ex.Sets
+	if sets == nil {
+		sets = []exercise.ExerciseSet{}
+	}
+
+	if err := r.client.QueryRow(ctx, q, ex.Name, sets, ex.Description).Scan(&id); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s",
+				pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return 0, newErr
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// FindAll возвращает весь каталог упражнений
+func (r *Repository) FindAll(ctx context.Context) ([]exercise.Exercise, error) {
+	q := `
+		SELECT id, name, sets, COALESCE(description, '') FROM exercises ORDER BY name
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exercises := make([]exercise.Exercise, 0)
+
+	for rows.Next() {
+		var e exercise.Exercise
+		if err := rows.Scan(&e.ID, &e.Name, &e.Sets, &e.Description); err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return exercises, nil
+}
+
+// FindOne ищет упражнение каталога по ID
+func (r *Repository) FindOne(ctx context.Context, id string) (exercise.Exercise, error) {
+	q := `
+		SELECT id, name, sets, COALESCE(description, '') FROM exercises WHERE id = $1
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	var e exercise.Exercise
+	if err := r.client.QueryRow(ctx, q, id).Scan(&e.ID, &e.Name, &e.Sets, &e.Description); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return exercise.Exercise{}, apperror.NotFound("exercise %s not found", id)
+		}
+		return exercise.Exercise{}, err
+	}
+
+	return e, nil
+}
+
+// Update обновляет упражнение каталога
+func (r *Repository) Update(ctx context.Context, ex exercise.Exercise) error {
+	q := `
+		UPDATE exercises
+		SET name = $1, sets = $2, description = $3
+		WHERE id = $4
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	sets := ex.Sets
+	if sets == nil {
+		sets = []exercise.ExerciseSet{}
+	}
+
+	_, err := r.client.Exec(ctx, q, ex.Name, sets, ex.Description, ex.ID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return newErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete удаляет упражнение из каталога
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	q := `
+		DELETE FROM exercises
+		WHERE id = $1
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	_, err := r.client.Exec(ctx, q, id)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return newErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client postgresql.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
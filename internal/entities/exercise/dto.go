@@ -1,7 +1,57 @@
 package exercise
 
 type CreateExerciseDTO struct {
-	Name        string        `json:"name"`
+	Name        string        `json:"name" validate:"required,max=200"`
 	Sets        []ExerciseSet `json:"sets"`
 	Description string        `json:"description,omitempty"` // Описание упражнения, если нужно
 }
+
+type CreateGroupDTO struct {
+	Type        GroupType `json:"type" validate:"oneof=superset giant_set circuit emom amrap"`
+	Name        string    `json:"name,omitempty" validate:"max=200"`
+	ExerciseIDs []int64   `json:"exercise_ids"`
+	Rounds      int       `json:"rounds,omitempty" validate:"min=0"`
+	TimeCapSec  int       `json:"time_cap_sec,omitempty" validate:"min=0"`
+	IntervalSec int       `json:"interval_sec,omitempty" validate:"min=0"`
+	RestSec     int       `json:"rest_sec,omitempty" validate:"min=0"`
+}
+
+// UpdateGroupDTO поля группы для частичного обновления. Поля, отсутствующие в запросе, не меняются,
+// переданный ноль или пустая строка очищают параметр.
+type UpdateGroupDTO struct {
+	Type        *GroupType `json:"type,omitempty" validate:"oneof=superset giant_set circuit emom amrap"`
+	Name        *string    `json:"name,omitempty" validate:"max=200"`
+	ExerciseIDs []int64    `json:"exercise_ids,omitempty"`
+	Rounds      *int       `json:"rounds,omitempty" validate:"min=0"`
+	TimeCapSec  *int       `json:"time_cap_sec,omitempty" validate:"min=0"`
+	IntervalSec *int       `json:"interval_sec,omitempty" validate:"min=0"`
+	RestSec     *int       `json:"rest_sec,omitempty" validate:"min=0"`
+}
+
+// Apply возвращает группу с переданными полями. При смене типа параметры прежнего типа
+// (круги, лимит времени, интервал и отдых) сбрасываются, если не переданы заново.
+func (dto UpdateGroupDTO) Apply(g Group) Group {
+	if dto.Type != nil && *dto.Type != g.Type {
+		g.Type = *dto.Type
+		g.Rounds, g.TimeCapSec, g.IntervalSec, g.RestSec = 0, 0, 0, 0
+	}
+	if dto.Name != nil {
+		g.Name = *dto.Name
+	}
+	if dto.ExerciseIDs != nil {
+		g.ExerciseIDs = dto.ExerciseIDs
+	}
+	if dto.Rounds != nil {
+		g.Rounds = *dto.Rounds
+	}
+	if dto.TimeCapSec != nil {
+		g.TimeCapSec = *dto.TimeCapSec
+	}
+	if dto.IntervalSec != nil {
+		g.IntervalSec = *dto.IntervalSec
+	}
+	if dto.RestSec != nil {
+		g.RestSec = *dto.RestSec
+	}
+	return g
+}
@@ -0,0 +1,40 @@
+package exercise
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUpdateGroupDTOApply(t *testing.T) {
+	emom := Group{ID: 1, Type: GroupEMOM, Name: "Finisher", ExerciseIDs: []int64{1}, Rounds: 10, IntervalSec: 60, RestSec: 30}
+
+	tests := []struct {
+		name string
+		body string
+		want Group
+	}{
+		{"empty body keeps group", `{}`, emom},
+		{"null keeps field", `{"name": null, "rest_sec": null}`, emom},
+		// Ноль и пустая строка очищают необязательные параметры
+		{"clear optional fields", `{"name": "", "rest_sec": 0}`,
+			Group{ID: 1, Type: GroupEMOM, ExerciseIDs: []int64{1}, Rounds: 10, IntervalSec: 60}},
+		{"same type keeps params", `{"type": "emom", "rounds": 12}`,
+			Group{ID: 1, Type: GroupEMOM, Name: "Finisher", ExerciseIDs: []int64{1}, Rounds: 12, IntervalSec: 60, RestSec: 30}},
+		// Параметры EMOM не переносятся в AMRAP
+		{"type change resets params", `{"type": "amrap", "time_cap_sec": 600}`,
+			Group{ID: 1, Type: GroupAMRAP, Name: "Finisher", ExerciseIDs: []int64{1}, TimeCapSec: 600}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dto UpdateGroupDTO
+			if err := json.Unmarshal([]byte(tt.body), &dto); err != nil {
+				t.Fatal(err)
+			}
+			if got := dto.Apply(emom); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
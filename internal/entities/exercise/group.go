@@ -0,0 +1,97 @@
+package exercise
+
+import "fit-journal/internal/apperror"
+
+// GroupType тип группы упражнений внутри тренировки
+type GroupType string
+
+const (
+	GroupSuperset GroupType = "superset"  // Суперсет: два упражнения подряд без отдыха
+	GroupGiantSet GroupType = "giant_set" // Гигантский сет: три и более упражнения подряд
+	GroupCircuit  GroupType = "circuit"   // Круговая тренировка
+	GroupEMOM     GroupType = "emom"      // Every Minute On the Minute: работа в начале каждого интервала
+	GroupAMRAP    GroupType = "amrap"     // As Many Rounds As Possible: максимум кругов за лимит времени
+)
+
+// Group объединяет несколько упражнений тренировки в один блок
+type Group struct {
+	ID          int64     `json:"id"`
+	Type        GroupType `json:"type"`
+	Name        string    `json:"name,omitempty"`
+	ExerciseIDs []int64   `json:"exercise_ids"`           // Порядок выполнения упражнений внутри группы
+	Rounds      int       `json:"rounds,omitempty"`       // Запланированное количество кругов
+	TimeCapSec  int       `json:"time_cap_sec,omitempty"` // Лимит времени блока (AMRAP)
+	IntervalSec int       `json:"interval_sec,omitempty"` // Длина интервала (EMOM)
+	RestSec     int       `json:"rest_sec,omitempty"`     // Отдых между кругами
+}
+
+// Validate проверяет параметры группы в зависимости от её типа; ошибки errors.Is apperror.ErrValidation
+func (g Group) Validate() error {
+	if g.Rounds < 0 || g.TimeCapSec < 0 || g.IntervalSec < 0 || g.RestSec < 0 {
+		return apperror.Validation("group parameters must not be negative")
+	}
+
+	seen := make(map[int64]struct{}, len(g.ExerciseIDs))
+	for _, id := range g.ExerciseIDs {
+		if _, ok := seen[id]; ok {
+			return apperror.Validation("exercise %d is listed twice in the group", id)
+		}
+		seen[id] = struct{}{}
+	}
+
+	count := len(g.ExerciseIDs)
+	switch g.Type {
+	case GroupSuperset:
+		if count != 2 {
+			return apperror.Validation("superset must contain exactly 2 exercises")
+		}
+	case GroupGiantSet:
+		if count < 3 {
+			return apperror.Validation("giant set must contain at least 3 exercises")
+		}
+	case GroupCircuit:
+		if count < 2 {
+			return apperror.Validation("circuit must contain at least 2 exercises")
+		}
+	case GroupEMOM:
+		if count < 1 {
+			return apperror.Validation("emom block must contain at least 1 exercise")
+		}
+		if g.IntervalSec == 0 || g.Rounds == 0 {
+			return apperror.Validation("emom block requires interval_sec and rounds")
+		}
+	case GroupAMRAP:
+		if count < 1 {
+			return apperror.Validation("amrap block must contain at least 1 exercise")
+		}
+		if g.TimeCapSec == 0 {
+			return apperror.Validation("amrap block requires time_cap_sec")
+		}
+	default:
+		return apperror.Validation("unknown group type %q", g.Type)
+	}
+
+	return nil
+}
+
+// MinExercises возвращает минимальное количество упражнений, при котором группа остаётся валидной
+func (t GroupType) MinExercises() int {
+	switch t {
+	case GroupSuperset, GroupCircuit:
+		return 2
+	case GroupGiantSet:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// Contains проверяет, входит ли упражнение в группу
+func (g Group) Contains(exerciseID int64) bool {
+	for _, id := range g.ExerciseIDs {
+		if id == exerciseID {
+			return true
+		}
+	}
+	return false
+}
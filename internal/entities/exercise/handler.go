@@ -0,0 +1,71 @@
+package exercise
+
+import (
+	"encoding/json"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/auth"
+	"fit-journal/internal/handlers"
+	"fit-journal/pkg/logging"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+const (
+	exercisesURL = "/exercises"
+)
+
+type handler struct {
+	repository Repository
+	auth       *auth.Service
+}
+
+func NewHandler(repo Repository, authService *auth.Service) handlers.Handler {
+	return &handler{
+		repository: repo,
+		auth:       authService,
+	}
+}
+
+func (h *handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, exercisesURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.GetCatalog)))
+	router.HandlerFunc(http.MethodPost, exercisesURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.CreateCatalogExercise)))
+}
+
+// GetCatalog возвращает каталог упражнений
+func (h *handler) GetCatalog(w http.ResponseWriter, r *http.Request) error {
+	exercises, err := h.repository.FindAll(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to fetch exercise catalog", "", http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(exercises)
+}
+
+// CreateCatalogExercise добавляет упражнение в каталог
+func (h *handler) CreateCatalogExercise(w http.ResponseWriter, r *http.Request) error {
+	var reqBody CreateExerciseDTO
+	if err := handlers.Decode(r, &reqBody); err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Invalid exercise data", "", http.StatusBadRequest)
+	}
+
+	ex := Exercise{
+		Name:        reqBody.Name,
+		Sets:        []ExerciseSet{},
+		Description: reqBody.Description,
+	}
+
+	id, err := h.repository.Create(r.Context(), ex)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to save exercise", "", http.StatusInternalServerError)
+	}
+	ex.ID = id
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(ex)
+}
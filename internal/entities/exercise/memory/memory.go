@@ -0,0 +1,107 @@
+// Package memory хранит каталог упражнений в памяти процесса: для тестов и режима --storage=memory.
+// Поведение повторяет репозиторий PostgreSQL, см. storagetest.
+package memory
+
+import (
+	"context"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/exercise"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type Repository struct {
+	mu        sync.RWMutex
+	exercises map[int64]exercise.Exercise
+	nextID    int64
+}
+
+// NewRepository создает пустой каталог
+func NewRepository() *Repository {
+	return &Repository{exercises: make(map[int64]exercise.Exercise)}
+}
+
+// stored оставляет поля, которые сохраняет каталог: название, подходы и описание
+func stored(id int64, ex exercise.Exercise) exercise.Exercise {
+	sets := make([]exercise.ExerciseSet, len(ex.Sets))
+	copy(sets, ex.Sets)
+	for i := range sets {
+		sets[i].DeletedAt = nil
+		if ex.Sets[i].DeletedAt != nil {
+			deletedAt := *ex.Sets[i].DeletedAt
+			sets[i].DeletedAt = &deletedAt
+		}
+	}
+	return exercise.Exercise{ID: id, Name: ex.Name, Sets: sets, Description: ex.Description}
+}
+
+// Create добавляет упражнение в каталог
+func (r *Repository) Create(_ context.Context, ex exercise.Exercise) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	r.exercises[r.nextID] = stored(r.nextID, ex)
+	return r.nextID, nil
+}
+
+// FindAll возвращает весь каталог упражнений по названию
+func (r *Repository) FindAll(_ context.Context) ([]exercise.Exercise, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	exercises := make([]exercise.Exercise, 0, len(r.exercises))
+	for id, ex := range r.exercises {
+		exercises = append(exercises, stored(id, ex))
+	}
+	sort.Slice(exercises, func(i, j int) bool {
+		if exercises[i].Name != exercises[j].Name {
+			return exercises[i].Name < exercises[j].Name
+		}
+		return exercises[i].ID < exercises[j].ID
+	})
+	return exercises, nil
+}
+
+// FindOne ищет упражнение каталога по ID
+func (r *Repository) FindOne(_ context.Context, id string) (exercise.Exercise, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return exercise.Exercise{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ex, ok := r.exercises[n]
+	if !ok {
+		return exercise.Exercise{}, apperror.NotFound("exercise %s not found", id)
+	}
+	return stored(n, ex), nil
+}
+
+// Update обновляет упражнение каталога
+func (r *Repository) Update(_ context.Context, ex exercise.Exercise) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.exercises[ex.ID]; ok {
+		r.exercises[ex.ID] = stored(ex.ID, ex)
+	}
+	return nil
+}
+
+// Delete удаляет упражнение из каталога
+func (r *Repository) Delete(_ context.Context, id string) error {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.exercises, n)
+	return nil
+}
@@ -0,0 +1,12 @@
+package memory
+
+import (
+	"fit-journal/internal/entities/storagetest"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunExercises(t, func(t *testing.T) storagetest.Repositories {
+		return storagetest.Repositories{Exercises: NewRepository()}
+	})
+}
@@ -2,13 +2,16 @@ package exercise
 
 type Exercise struct {
 	ID          int64         `json:"id"`
-	Name        string        `json:"name"`
+	CatalogID   int64         `json:"catalog_id,omitempty"` // Ссылка на упражнение из каталога, если оно сопоставлено
+	Name        string        `json:"name" validate:"required,max=200"`
 	Sets        []ExerciseSet `json:"sets"`
 	Description string        `json:"description,omitempty"` // Описание упражнения, если нужно
+	DeletedAt   *int64        `json:"deleted_at,omitempty"`  // Время перемещения в корзину (Unix), nil для активных
 }
 
 type ExerciseSet struct {
-	ID     int64   `json:"id"` // Уникальный ID для подхода
-	Reps   int     `json:"reps"`
-	Weight float64 `json:"weight"` // Вес для каждого подхода
+	ID        int64   `json:"id"` // Уникальный ID для подхода
+	Reps      int     `json:"reps" validate:"min=0,max=1000"`
+	Weight    float64 `json:"weight" validate:"min=0,max=1000"` // Вес для каждого подхода
+	DeletedAt *int64  `json:"deleted_at,omitempty"`             // Время перемещения в корзину (Unix), nil для активных
 }
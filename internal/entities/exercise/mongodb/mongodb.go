@@ -0,0 +1,134 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/pkg/client/mongodb"
+	"fit-journal/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strconv"
+)
+
+type Repository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// document упражнение каталога в коллекции exercises
+type document struct {
+	ID          int64                  `bson:"_id"`
+	Name        string                 `bson:"name"`
+	Sets        []exercise.ExerciseSet `bson:"sets"`
+	Description string                 `bson:"description"`
+}
+
+func (d document) exercise() exercise.Exercise {
+	sets := d.Sets
+	if sets == nil {
+		sets = []exercise.ExerciseSet{}
+	}
+	return exercise.Exercise{ID: d.ID, Name: d.Name, Sets: sets, Description: d.Description}
+}
+
+// setsOf возвращает подходы упражнения, пустой список вместо nil
+func setsOf(ex exercise.Exercise) []exercise.ExerciseSet {
+	if ex.Sets == nil {
+		return []exercise.ExerciseSet{}
+	}
+	return ex.Sets
+}
+
+// Create добавляет упражнение в каталог
+func (r *Repository) Create(ctx context.Context, ex exercise.Exercise) (int64, error) {
+	id, err := mongodb.NextID(ctx, r.db, r.collection.Name())
+	if err != nil {
+		return 0, err
+	}
+	doc := document{ID: id, Name: ex.Name, Sets: setsOf(ex), Description: ex.Description}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// FindAll возвращает весь каталог упражнений
+func (r *Repository) FindAll(ctx context.Context) ([]exercise.Exercise, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	exercises := make([]exercise.Exercise, 0)
+	for cursor.Next(ctx) {
+		var d document
+		if err := cursor.Decode(&d); err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, d.exercise())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return exercises, nil
+}
+
+// FindOne ищет упражнение каталога по ID
+func (r *Repository) FindOne(ctx context.Context, id string) (exercise.Exercise, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return exercise.Exercise{}, err
+	}
+
+	var d document
+	if err := r.collection.FindOne(ctx, bson.M{"_id": n}).Decode(&d); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return exercise.Exercise{}, apperror.NotFound("exercise %s not found", id)
+		}
+		return exercise.Exercise{}, err
+	}
+	return d.exercise(), nil
+}
+
+// Update обновляет упражнение каталога
+func (r *Repository) Update(ctx context.Context, ex exercise.Exercise) error {
+	update := bson.M{"$set": bson.M{
+		"name":        ex.Name,
+		"sets":        setsOf(ex),
+		"description": ex.Description,
+	}}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": ex.ID}, update); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет упражнение из каталога
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": n}); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(db *mongo.Database) *Repository {
+	return &Repository{
+		db:         db,
+		collection: db.Collection("exercises"),
+	}
+}
@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/pkg/client/sqlite"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"strings"
+)
+
+type Repository struct {
+	client sqlite.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// encodeSets сериализует подходы в JSON, пустой список вместо nil
+func encodeSets(sets []exercise.ExerciseSet) (string, error) {
+	if sets == nil {
+		sets = []exercise.ExerciseSet{}
+	}
+	data, err := json.Marshal(sets)
+	return string(data), err
+}
+
+// Create добавляет упражнение в каталог
+func (r *Repository) Create(ctx context.Context, ex exercise.Exercise) (int64, error) {
+	q := `
+		INSERT INTO exercises (name, sets, description) VALUES (?, ?, ?)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	sets, err := encodeSets(ex.Sets)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.client.ExecContext(ctx, q, ex.Name, sets, ex.Description)
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// scanner строка результата *sql.Row или *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanExercise(row scanner) (exercise.Exercise, error) {
+	var e exercise.Exercise
+	var sets string
+	if err := row.Scan(&e.ID, &e.Name, &sets, &e.Description); err != nil {
+		return exercise.Exercise{}, err
+	}
+	if err := json.Unmarshal([]byte(sets), &e.Sets); err != nil {
+		return exercise.Exercise{}, err
+	}
+	return e, nil
+}
+
+// FindAll возвращает весь каталог упражнений
+func (r *Repository) FindAll(ctx context.Context) ([]exercise.Exercise, error) {
+	q := `
+		SELECT id, name, sets, COALESCE(description, '') FROM exercises ORDER BY name, id
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exercises := make([]exercise.Exercise, 0)
+	for rows.Next() {
+		e, err := scanExercise(rows)
+		if err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return exercises, nil
+}
+
+// FindOne ищет упражнение каталога по ID
+func (r *Repository) FindOne(ctx context.Context, id string) (exercise.Exercise, error) {
+	q := `
+		SELECT id, name, sets, COALESCE(description, '') FROM exercises WHERE id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	e, err := scanExercise(r.client.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return exercise.Exercise{}, apperror.NotFound("exercise %s not found", id)
+		}
+		return exercise.Exercise{}, err
+	}
+	return e, nil
+}
+
+// Update обновляет упражнение каталога
+func (r *Repository) Update(ctx context.Context, ex exercise.Exercise) error {
+	q := `
+		UPDATE exercises SET name = ?, sets = ?, description = ? WHERE id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	sets, err := encodeSets(ex.Sets)
+	if err != nil {
+		return err
+	}
+	if _, err := r.client.ExecContext(ctx, q, ex.Name, sets, ex.Description, ex.ID); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет упражнение из каталога
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	q := `
+		DELETE FROM exercises WHERE id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if _, err := r.client.ExecContext(ctx, q, id); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client sqlite.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
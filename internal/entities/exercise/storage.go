@@ -3,7 +3,7 @@ package exercise
 import "context"
 
 type Repository interface {
-	Create(ctx context.Context, exercise Exercise) error
+	Create(ctx context.Context, exercise Exercise) (int64, error)
 	FindOne(ctx context.Context, id string) (Exercise, error)
 	Update(ctx context.Context, exercise Exercise) error
 	Delete(ctx context.Context, id string) error
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/pkg/client/postgresql"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"strings"
+)
+
+type Repository struct {
+	client postgresql.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// Create сохраняет метрику пользователя
+func (r *Repository) Create(ctx context.Context, m metric.Metric) (int64, error) {
+	var id int64
+	q := `
+        INSERT INTO metrics
+            (user_id, weight, calories_consumed, day)
+        VALUES
+            ($1, $2, $3, $4)
+        RETURNING id
+    `
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if err := r.client.QueryRow(ctx, q, m.UserID, m.Weight, m.CaloriesConsumed, m.Day).Scan(&id); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s",
+				pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return 0, newErr
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// FindAllByUserID возвращает все метрики пользователя по возрастанию дня
+func (r *Repository) FindAllByUserID(ctx context.Context, userID int64) ([]metric.Metric, error) {
+	q := `
+		SELECT id, user_id, COALESCE(weight, ''), COALESCE(calories_consumed, ''), day
+		FROM metrics WHERE user_id = $1 ORDER BY day, id
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	metrics := make([]metric.Metric, 0)
+
+	for rows.Next() {
+		var m metric.Metric
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Weight, &m.CaloriesConsumed, &m.Day); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// FindOne ищет метрику по ID
+func (r *Repository) FindOne(ctx context.Context, id int64) (metric.Metric, error) {
+	q := `
+		SELECT id, user_id, COALESCE(weight, ''), COALESCE(calories_consumed, ''), day
+		FROM metrics WHERE id = $1
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	var m metric.Metric
+	if err := r.client.QueryRow(ctx, q, id).Scan(&m.ID, &m.UserID, &m.Weight, &m.CaloriesConsumed, &m.Day); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return metric.Metric{}, apperror.NotFound("metric %d not found", id)
+		}
+		return metric.Metric{}, err
+	}
+
+	return m, nil
+}
+
+// Update обновляет метрику
+func (r *Repository) Update(ctx context.Context, m metric.Metric) error {
+	q := `
+		UPDATE metrics
+		SET weight = $1, calories_consumed = $2, day = $3
+		WHERE id = $4
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	_, err := r.client.Exec(ctx, q, m.Weight, m.CaloriesConsumed, m.Day, m.ID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return newErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete удаляет метрику по ID
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	q := `
+		DELETE FROM metrics
+		WHERE id = $1
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	_, err := r.client.Exec(ctx, q, id)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return newErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client postgresql.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
@@ -1,12 +1,14 @@
 package metric
 
-import (
-	"fit-journal/internal/entities/user"
-)
-
 type CreateMetricDTO struct {
-	UserID           user.User `json:"id"`
-	Weigth           string    `json:"weigth,omitempty"`
-	CaloriesConsumed string    `json:"calories_consumed,omitempty"`
-	Day              string    `json:"day"`
+	Weight           string `json:"weight,omitempty" validate:"number,min=0"`
+	CaloriesConsumed string `json:"calories_consumed,omitempty" validate:"number,min=0"`
+	Day              string `json:"day" validate:"required,date"`
+}
+
+// UpdateMetricDTO поля метрики для обновления; пустые поля не меняются
+type UpdateMetricDTO struct {
+	Weight           string `json:"weight,omitempty" validate:"number,min=0"`
+	CaloriesConsumed string `json:"calories_consumed,omitempty" validate:"number,min=0"`
+	Day              string `json:"day,omitempty" validate:"date"`
 }
@@ -0,0 +1,200 @@
+package metric
+
+import (
+	"encoding/json"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/auth"
+	"fit-journal/internal/entities/user"
+	"fit-journal/internal/handlers"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/metrics"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+const (
+	metricsURL = "/users/metrics"
+	metricURL  = "/users/metrics/:metric_id"
+
+	// DayLayout формат дня метрики
+	DayLayout = "2006-01-02"
+)
+
+type handler struct {
+	repository     Repository
+	userRepository user.Repository
+	audit          *audit.Recorder
+	auth           *auth.Service
+}
+
+func NewHandler(repo Repository, userRepo user.Repository, recorder *audit.Recorder, authService *auth.Service) handlers.Handler {
+	return &handler{
+		repository:     repo,
+		userRepository: userRepo,
+		audit:          recorder,
+		auth:           authService,
+	}
+}
+
+func (h *handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, metricsURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.GetMetrics)))
+	router.HandlerFunc(http.MethodPost, metricsURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.CreateMetric)))
+	router.HandlerFunc(http.MethodGet, metricURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.GetMetric)))
+	router.HandlerFunc(http.MethodPut, metricURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.UpdateMetric)))
+	router.HandlerFunc(http.MethodDelete, metricURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.DeleteMetric)))
+}
+
+// currentUser возвращает пользователя, от имени которого выполняется запрос
+func (h *handler) currentUser(r *http.Request) (user.User, error) {
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		return user.User{}, apperror.NewAppError(nil, "Invalid or missing username", "", http.StatusUnauthorized)
+	}
+
+	u, err := h.userRepository.FindOne(r.Context(), username)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return user.User{}, apperror.NewAppError(err, "User not found", "", http.StatusInternalServerError)
+	}
+	return u, nil
+}
+
+// ownMetric возвращает метрику текущего пользователя по параметру metric_id
+func (h *handler) ownMetric(r *http.Request, u user.User) (Metric, error) {
+	id, err := strconv.ParseInt(httprouter.ParamsFromContext(r.Context()).ByName("metric_id"), 10, 64)
+	if err != nil {
+		return Metric{}, apperror.NewAppError(err, "Invalid metric id", "", http.StatusBadRequest)
+	}
+
+	m, err := h.repository.FindOne(r.Context(), id)
+	if err != nil {
+		return Metric{}, apperror.NewAppError(err, "Metric not found", "", http.StatusInternalServerError)
+	}
+	// Чужая метрика неотличима от отсутствующей
+	if m.UserID != u.ID {
+		return Metric{}, apperror.NewAppError(apperror.ErrNotFound, "Metric not found", "", http.StatusNotFound)
+	}
+	return m, nil
+}
+
+// GetMetrics возвращает все метрики пользователя
+func (h *handler) GetMetrics(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	metrics, err := h.repository.FindAllByUserID(r.Context(), u.ID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to fetch metrics", "", http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(metrics)
+}
+
+// GetMetric возвращает метрику по ID
+func (h *handler) GetMetric(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+	m, err := h.ownMetric(r, u)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(m)
+}
+
+// CreateMetric сохраняет метрику за день
+func (h *handler) CreateMetric(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	var dto CreateMetricDTO
+	if err := handlers.Decode(r, &dto); err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Invalid metric data", "", http.StatusBadRequest)
+	}
+
+	m := Metric{UserID: u.ID, Weight: dto.Weight, CaloriesConsumed: dto.CaloriesConsumed, Day: dto.Day}
+	if m.ID, err = h.repository.Create(r.Context(), m); err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to save metric", "", http.StatusInternalServerError)
+	}
+	h.audit.Record(r, audit.ActionCreate, audit.ResourceMetric, m.ID, u.ID, nil, m)
+	metrics.MetricsRecorded.Inc()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(m)
+}
+
+// UpdateMetric обновляет переданные поля метрики
+func (h *handler) UpdateMetric(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+	m, err := h.ownMetric(r, u)
+	if err != nil {
+		return err
+	}
+
+	var dto UpdateMetricDTO
+	if err := handlers.Decode(r, &dto); err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Invalid metric data", "", http.StatusBadRequest)
+	}
+
+	before := m
+	if dto.Weight != "" {
+		m.Weight = dto.Weight
+	}
+	if dto.CaloriesConsumed != "" {
+		m.CaloriesConsumed = dto.CaloriesConsumed
+	}
+	if dto.Day != "" {
+		m.Day = dto.Day
+	}
+
+	if err := h.repository.Update(r.Context(), m); err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to update metric", "", http.StatusInternalServerError)
+	}
+	h.audit.Record(r, audit.ActionUpdate, audit.ResourceMetric, m.ID, u.ID, before, m)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(m)
+}
+
+// DeleteMetric удаляет метрику
+func (h *handler) DeleteMetric(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+	m, err := h.ownMetric(r, u)
+	if err != nil {
+		return err
+	}
+
+	if err := h.repository.Delete(r.Context(), m.ID); err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to delete metric", "", http.StatusInternalServerError)
+	}
+	h.audit.Record(r, audit.ActionDelete, audit.ResourceMetric, m.ID, u.ID, m, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package memory хранит метрики пользователей в памяти процесса: для тестов и режима --storage=memory.
+// Поведение повторяет репозиторий PostgreSQL, см. storagetest.
+package memory
+
+import (
+	"context"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/metric"
+	"sort"
+	"sync"
+)
+
+type Repository struct {
+	mu      sync.RWMutex
+	metrics map[int64]metric.Metric
+	nextID  int64
+}
+
+// NewRepository создает пустой репозиторий
+func NewRepository() *Repository {
+	return &Repository{metrics: make(map[int64]metric.Metric)}
+}
+
+// Create сохраняет метрику пользователя
+func (r *Repository) Create(_ context.Context, m metric.Metric) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	m.ID = r.nextID
+	r.metrics[m.ID] = m
+	return m.ID, nil
+}
+
+// FindAllByUserID возвращает все метрики пользователя по возрастанию дня
+func (r *Repository) FindAllByUserID(_ context.Context, userID int64) ([]metric.Metric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	metrics := make([]metric.Metric, 0)
+	for _, m := range r.metrics {
+		if m.UserID == userID {
+			metrics = append(metrics, m)
+		}
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Day != metrics[j].Day {
+			return metrics[i].Day < metrics[j].Day
+		}
+		return metrics[i].ID < metrics[j].ID
+	})
+	return metrics, nil
+}
+
+// FindOne ищет метрику по ID
+func (r *Repository) FindOne(_ context.Context, id int64) (metric.Metric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.metrics[id]
+	if !ok {
+		return metric.Metric{}, apperror.NotFound("metric %d not found", id)
+	}
+	return m, nil
+}
+
+// Update обновляет значения и день метрики, владелец не меняется
+func (r *Repository) Update(_ context.Context, m metric.Metric) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.metrics[m.ID]
+	if !ok {
+		return nil
+	}
+	existing.Weight, existing.CaloriesConsumed, existing.Day = m.Weight, m.CaloriesConsumed, m.Day
+	r.metrics[m.ID] = existing
+	return nil
+}
+
+// Delete удаляет метрику по ID
+func (r *Repository) Delete(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.metrics, id)
+	return nil
+}
+
+// DeleteByUserID удаляет все метрики пользователя
+func (r *Repository) DeleteByUserID(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, m := range r.metrics {
+		if m.UserID == userID {
+			delete(r.metrics, id)
+		}
+	}
+}
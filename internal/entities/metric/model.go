@@ -1,13 +1,9 @@
 package metric
 
-import (
-	"fit-journal/internal/entities/user"
-)
-
 type Metric struct {
-	ID               string    `json:"id"`
-	UserID           user.User `json:"id"`
-	Weigth           string    `json:"weigth,omitempty"`
-	CaloriesConsumed string    `json:"calories_consumed,omitempty"`
-	Day              string    `json:"day"`
+	ID               int64  `json:"id"`
+	UserID           int64  `json:"user_id"`
+	Weight           string `json:"weight,omitempty"`
+	CaloriesConsumed string `json:"calories_consumed,omitempty"`
+	Day              string `json:"day"`
 }
@@ -0,0 +1,112 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/pkg/client/mongodb"
+	"fit-journal/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Repository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// document метрика в коллекции metrics
+type document struct {
+	ID               int64  `bson:"_id"`
+	UserID           int64  `bson:"user_id"`
+	Weight           string `bson:"weight"`
+	CaloriesConsumed string `bson:"calories_consumed"`
+	Day              string `bson:"day"`
+}
+
+func (d document) metric() metric.Metric {
+	return metric.Metric{ID: d.ID, UserID: d.UserID, Weight: d.Weight, CaloriesConsumed: d.CaloriesConsumed, Day: d.Day}
+}
+
+// Create сохраняет метрику пользователя
+func (r *Repository) Create(ctx context.Context, m metric.Metric) (int64, error) {
+	id, err := mongodb.NextID(ctx, r.db, r.collection.Name())
+	if err != nil {
+		return 0, err
+	}
+	doc := document{ID: id, UserID: m.UserID, Weight: m.Weight, CaloriesConsumed: m.CaloriesConsumed, Day: m.Day}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// FindAllByUserID возвращает все метрики пользователя по возрастанию дня
+func (r *Repository) FindAllByUserID(ctx context.Context, userID int64) ([]metric.Metric, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "day", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	metrics := make([]metric.Metric, 0)
+	for cursor.Next(ctx) {
+		var d document
+		if err := cursor.Decode(&d); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, d.metric())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// FindOne ищет метрику по ID
+func (r *Repository) FindOne(ctx context.Context, id int64) (metric.Metric, error) {
+	var d document
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&d); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return metric.Metric{}, apperror.NotFound("metric %d not found", id)
+		}
+		return metric.Metric{}, err
+	}
+	return d.metric(), nil
+}
+
+// Update обновляет метрику
+func (r *Repository) Update(ctx context.Context, m metric.Metric) error {
+	update := bson.M{"$set": bson.M{
+		"weight":            m.Weight,
+		"calories_consumed": m.CaloriesConsumed,
+		"day":               m.Day,
+	}}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": m.ID}, update); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет метрику по ID
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(db *mongo.Database) *Repository {
+	return &Repository{
+		db:         db,
+		collection: db.Collection("metrics"),
+	}
+}
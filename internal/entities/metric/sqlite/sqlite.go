@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/pkg/client/sqlite"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"strings"
+)
+
+type Repository struct {
+	client sqlite.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// Create сохраняет метрику пользователя
+func (r *Repository) Create(ctx context.Context, m metric.Metric) (int64, error) {
+	q := `
+		INSERT INTO metrics (user_id, weight, calories_consumed, day) VALUES (?, ?, ?, ?)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	res, err := r.client.ExecContext(ctx, q, m.UserID, m.Weight, m.CaloriesConsumed, m.Day)
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// metricColumns колонки, из которых собирается metric.Metric
+const metricColumns = `id, user_id, COALESCE(weight, ''), COALESCE(calories_consumed, ''), day`
+
+// FindAllByUserID возвращает все метрики пользователя по возрастанию дня
+func (r *Repository) FindAllByUserID(ctx context.Context, userID int64) ([]metric.Metric, error) {
+	q := `
+		SELECT ` + metricColumns + ` FROM metrics WHERE user_id = ? ORDER BY day, id
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	metrics := make([]metric.Metric, 0)
+	for rows.Next() {
+		var m metric.Metric
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Weight, &m.CaloriesConsumed, &m.Day); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// FindOne ищет метрику по ID
+func (r *Repository) FindOne(ctx context.Context, id int64) (metric.Metric, error) {
+	q := `
+		SELECT ` + metricColumns + ` FROM metrics WHERE id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	var m metric.Metric
+	if err := r.client.QueryRowContext(ctx, q, id).Scan(&m.ID, &m.UserID, &m.Weight, &m.CaloriesConsumed, &m.Day); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return metric.Metric{}, apperror.NotFound("metric %d not found", id)
+		}
+		return metric.Metric{}, err
+	}
+	return m, nil
+}
+
+// Update обновляет метрику
+func (r *Repository) Update(ctx context.Context, m metric.Metric) error {
+	q := `
+		UPDATE metrics SET weight = ?, calories_consumed = ?, day = ? WHERE id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if _, err := r.client.ExecContext(ctx, q, m.Weight, m.CaloriesConsumed, m.Day, m.ID); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет метрику по ID
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	q := `
+		DELETE FROM metrics WHERE id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if _, err := r.client.ExecContext(ctx, q, id); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client sqlite.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
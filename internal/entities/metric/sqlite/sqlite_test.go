@@ -0,0 +1,17 @@
+package sqlite
+
+import (
+	"fit-journal/internal/entities/storagetest"
+	userSQLite "fit-journal/internal/entities/user/sqlite"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunMetrics(t, func(t *testing.T) storagetest.Repositories {
+		client := storagetest.SQLite(t)
+		return storagetest.Repositories{
+			Users:   userSQLite.NewRepository(client),
+			Metrics: NewRepository(client),
+		}
+	})
+}
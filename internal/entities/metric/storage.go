@@ -3,9 +3,9 @@ package metric
 import "context"
 
 type Repository interface {
-	Create(ctx context.Context, metric Metric) error
-	FindOne(ctx context.Context, id string) (Metric, error)
+	Create(ctx context.Context, metric Metric) (int64, error)
+	FindOne(ctx context.Context, id int64) (Metric, error)
 	Update(ctx context.Context, metric Metric) error
-	Delete(ctx context.Context, id string) error
-	FindAll(ctx context.Context) (m []Metric, err error)
+	Delete(ctx context.Context, id int64) error
+	FindAllByUserID(ctx context.Context, userID int64) (m []Metric, err error)
 }
@@ -0,0 +1,132 @@
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"fit-journal/internal/audit"
+	"slices"
+	"testing"
+	"time"
+)
+
+// auditBase время записей журнала; хранилища сохраняют его с точностью до миллисекунд
+var auditBase = time.UnixMilli(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).UnixMilli())
+
+// auditEntry запись журнала об изменении ресурса resourceID
+func auditEntry(at time.Time, actor string, ownerID int64, resource string, resourceID int64) audit.Entry {
+	return audit.Entry{
+		At:         at,
+		Actor:      actor,
+		OwnerID:    ownerID,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Action:     audit.ActionUpdate,
+		Changes: []audit.Change{
+			{Field: "name", Before: json.RawMessage(`"Squat"`), After: json.RawMessage(`"Deadlift"`)},
+			{Field: "weight", After: json.RawMessage(`100`)},
+		},
+		RequestID: "req-1",
+	}
+}
+
+func createEntries(t *testing.T, r Repositories, entries ...audit.Entry) {
+	t.Helper()
+	for _, e := range entries {
+		requireNoError(t, r.Audit.Create(context.Background(), e))
+	}
+}
+
+// resourceIDs возвращает ResourceID записей в порядке выборки
+func resourceIDs(entries []audit.Entry) []int64 {
+	ids := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ResourceID)
+	}
+	return ids
+}
+
+// RunAudit проверяет audit.Repository
+func RunAudit(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	run(t, newRepos, map[string]func(t *testing.T, r Repositories){
+		"CreateAndFind": func(t *testing.T, r Repositories) {
+			want := auditEntry(auditBase.Add(1500*time.Microsecond), "alice", 1, audit.ResourceWorkout, 7)
+			createEntries(t, r, want)
+
+			got, err := r.Audit.Find(ctx, audit.Filter{})
+			requireNoError(t, err)
+			if len(got) != 1 || got[0].ID == 0 {
+				t.Fatalf("expected one entry with generated ID, got %+v", got)
+			}
+			// Время усекается до миллисекунд
+			want.ID, want.At = got[0].ID, auditBase.Add(time.Millisecond)
+			requireEqual(t, want, got[0])
+		},
+		"FindNewestFirst": func(t *testing.T, r Repositories) {
+			createEntries(t, r,
+				auditEntry(auditBase, "alice", 1, audit.ResourceWorkout, 1),
+				auditEntry(auditBase.Add(2*time.Second), "alice", 1, audit.ResourceWorkout, 2),
+				auditEntry(auditBase.Add(time.Second), "alice", 1, audit.ResourceWorkout, 3),
+				// При равном времени первой идёт более поздняя запись
+				auditEntry(auditBase.Add(3*time.Second), "alice", 1, audit.ResourceWorkout, 4),
+				auditEntry(auditBase.Add(3*time.Second), "alice", 1, audit.ResourceWorkout, 5),
+			)
+
+			got, err := r.Audit.Find(ctx, audit.Filter{})
+			requireNoError(t, err)
+			requireEqual(t, []int64{5, 4, 2, 3, 1}, resourceIDs(got))
+		},
+		"FindFilters": func(t *testing.T, r Repositories) {
+			createEntries(t, r,
+				auditEntry(auditBase, "alice", 1, audit.ResourceWorkout, 1),
+				auditEntry(auditBase.Add(time.Second), "alice", 1, audit.ResourceMetric, 2),
+				auditEntry(auditBase.Add(2*time.Second), "admin", 1, audit.ResourceUser, 3),
+				auditEntry(auditBase.Add(3*time.Second), "bob", 2, audit.ResourceWorkout, 4),
+			)
+
+			for _, tt := range []struct {
+				name   string
+				filter audit.Filter
+				want   []int64
+			}{
+				{"none", audit.Filter{}, []int64{4, 3, 2, 1}},
+				{"owner", audit.Filter{OwnerID: 1}, []int64{3, 2, 1}},
+				{"actor", audit.Filter{Actor: "alice"}, []int64{2, 1}},
+				{"resource", audit.Filter{Resource: audit.ResourceWorkout}, []int64{4, 1}},
+				// Граница Since включается в выборку
+				{"since", audit.Filter{Since: auditBase.Add(time.Second)}, []int64{4, 3, 2}},
+				{"limit", audit.Filter{Limit: 2}, []int64{4, 3}},
+				{"combined", audit.Filter{OwnerID: 1, Resource: audit.ResourceWorkout, Limit: 10}, []int64{1}},
+				{"no match", audit.Filter{OwnerID: 404}, []int64{}},
+			} {
+				got, err := r.Audit.Find(ctx, tt.filter)
+				requireNoError(t, err)
+				if got == nil {
+					t.Fatalf("%s: expected empty non-nil slice", tt.name)
+				}
+				if ids := resourceIDs(got); !slices.Equal(ids, tt.want) {
+					t.Errorf("%s: got %v, want %v", tt.name, ids, tt.want)
+				}
+			}
+		},
+		"PurgeBefore": func(t *testing.T, r Repositories) {
+			createEntries(t, r,
+				auditEntry(auditBase, "alice", 1, audit.ResourceWorkout, 1),
+				auditEntry(auditBase.Add(time.Second), "alice", 1, audit.ResourceWorkout, 2),
+				auditEntry(auditBase.Add(2*time.Second), "alice", 2, audit.ResourceWorkout, 3),
+			)
+
+			// Записи ровно на границе сохраняются
+			n, err := r.Audit.PurgeBefore(ctx, auditBase.Add(time.Second))
+			requireNoError(t, err)
+			requireEqual(t, int64(1), n)
+			n, err = r.Audit.PurgeBefore(ctx, auditBase.Add(time.Second))
+			requireNoError(t, err)
+			requireEqual(t, int64(0), n)
+
+			got, err := r.Audit.Find(ctx, audit.Filter{})
+			requireNoError(t, err)
+			requireEqual(t, []int64{3, 2}, resourceIDs(got))
+		},
+	})
+}
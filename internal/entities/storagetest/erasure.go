@@ -0,0 +1,181 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/internal/erasure"
+	"testing"
+	"time"
+)
+
+// erasureBase время запросов на удаление; хранилища сохраняют его с точностью до секунд
+var erasureBase = time.Unix(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Unix(), 0)
+
+func deletionRequest(userID int64, eraseAfter time.Time) erasure.Request {
+	return erasure.Request{
+		UserID:      userID,
+		RequestedAt: erasureBase,
+		EraseAfter:  eraseAfter,
+		ExportPath:  "exports/user.zip",
+	}
+}
+
+func requireErasureNotFound(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, erasure.ErrNotFound) {
+		t.Fatalf("expected erasure.ErrNotFound, got %v", err)
+	}
+}
+
+// userIDs возвращает ID пользователей запросов в порядке выборки
+func userIDs(requests []erasure.Request) []int64 {
+	ids := make([]int64, 0, len(requests))
+	for _, req := range requests {
+		ids = append(ids, req.UserID)
+	}
+	return ids
+}
+
+// RunErasure проверяет erasure.Repository, включая удаление всех данных пользователя в EraseUser
+func RunErasure(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	run(t, newRepos, map[string]func(t *testing.T, r Repositories){
+		"CreateAndFindByUserID": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			want := deletionRequest(userID, erasureBase.Add(time.Hour))
+			requireNoError(t, r.Erasure.Create(ctx, want))
+
+			got, err := r.Erasure.FindByUserID(ctx, userID)
+			requireNoError(t, err)
+			requireEqual(t, want, got)
+
+			_, err = r.Erasure.FindByUserID(ctx, 404)
+			requireErasureNotFound(t, err)
+		},
+		"DuplicateRequest": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			requireNoError(t, r.Erasure.Create(ctx, deletionRequest(userID, erasureBase.Add(time.Hour))))
+			if err := r.Erasure.Create(ctx, deletionRequest(userID, erasureBase.Add(2*time.Hour))); !errors.Is(err, apperror.ErrConflict) {
+				t.Fatalf("expected apperror.ErrConflict for a second request of the same user, got %v", err)
+			}
+
+			got, err := r.Erasure.FindByUserID(ctx, userID)
+			requireNoError(t, err)
+			requireEqual(t, erasureBase.Add(time.Hour), got.EraseAfter)
+		},
+		"Delete": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			requireNoError(t, r.Erasure.Create(ctx, deletionRequest(userID, erasureBase.Add(time.Hour))))
+
+			requireNoError(t, r.Erasure.Delete(ctx, userID))
+			_, err := r.Erasure.FindByUserID(ctx, userID)
+			requireErasureNotFound(t, err)
+			requireErasureNotFound(t, r.Erasure.Delete(ctx, userID))
+		},
+		"FindDue": func(t *testing.T, r Repositories) {
+			alice, bob, carol := createUser(t, r, "alice"), createUser(t, r, "bob"), createUser(t, r, "carol")
+			requireNoError(t, r.Erasure.Create(ctx, deletionRequest(carol, erasureBase.Add(-time.Hour))))
+			requireNoError(t, r.Erasure.Create(ctx, deletionRequest(bob, erasureBase.Add(time.Second))))
+			requireNoError(t, r.Erasure.Create(ctx, deletionRequest(alice, erasureBase)))
+
+			// Срок, истекающий ровно сейчас, уже наступил; порядок по ID пользователя
+			due, err := r.Erasure.FindDue(ctx, erasureBase)
+			requireNoError(t, err)
+			requireEqual(t, []int64{alice, carol}, userIDs(due))
+			requireEqual(t, deletionRequest(carol, erasureBase.Add(-time.Hour)), due[1])
+
+			none, err := r.Erasure.FindDue(ctx, erasureBase.Add(-2*time.Hour))
+			requireNoError(t, err)
+			if none == nil || len(none) != 0 {
+				t.Fatalf("expected empty non-nil slice, got %#v", none)
+			}
+		},
+		"EnqueueSoftDeleted": func(t *testing.T, r Repositories) {
+			alice, bob := createUser(t, r, "alice"), createUser(t, r, "bob")
+			createUser(t, r, "carol")
+			requireNoError(t, r.Users.Delete(ctx, "alice"))
+			requireNoError(t, r.Users.Delete(ctx, "bob"))
+			existing := deletionRequest(bob, erasureBase.Add(time.Minute))
+			requireNoError(t, r.Erasure.Create(ctx, existing))
+
+			eraseAfter := erasureBase.Add(24 * time.Hour)
+			n, err := r.Erasure.EnqueueSoftDeleted(ctx, erasureBase, eraseAfter)
+			requireNoError(t, err)
+			requireEqual(t, int64(1), n)
+
+			// Новый запрос без архива выгрузки, существующий не меняется
+			got, err := r.Erasure.FindByUserID(ctx, alice)
+			requireNoError(t, err)
+			requireEqual(t, erasure.Request{UserID: alice, RequestedAt: erasureBase, EraseAfter: eraseAfter}, got)
+			got, err = r.Erasure.FindByUserID(ctx, bob)
+			requireNoError(t, err)
+			requireEqual(t, existing, got)
+
+			n, err = r.Erasure.EnqueueSoftDeleted(ctx, erasureBase, eraseAfter)
+			requireNoError(t, err)
+			requireEqual(t, int64(0), n)
+		},
+		"EraseUser": func(t *testing.T, r Repositories) {
+			alice, bob := createUser(t, r, "alice"), createUser(t, r, "bob")
+			for _, userID := range []int64{alice, bob} {
+				_, err := r.Workouts.Create(ctx, sampleWorkout(userID, 1700000000))
+				requireNoError(t, err)
+				trashed, err := r.Workouts.Create(ctx, sampleWorkout(userID, 1700003600))
+				requireNoError(t, err)
+				requireNoError(t, r.Workouts.Trash(ctx, trashed, 1700007200))
+				_, err = r.Metrics.Create(ctx, metric.Metric{UserID: userID, Weight: "80", Day: "2024-03-01"})
+				requireNoError(t, err)
+				requireNoError(t, r.Erasure.Create(ctx, deletionRequest(userID, erasureBase)))
+			}
+			createEntries(t, r,
+				auditEntry(auditBase, "alice", alice, audit.ResourceWorkout, 1),
+				// Записи о чужих данных, сделанные пользователем, принадлежат их владельцу и сохраняются
+				auditEntry(auditBase, "alice", bob, audit.ResourceWorkout, 2),
+				auditEntry(auditBase, "bob", bob, audit.ResourceMetric, 3),
+			)
+
+			requireNoError(t, r.Erasure.EraseUser(ctx, alice))
+
+			_, err := r.Users.FindOne(ctx, "alice")
+			requireNotFound(t, err)
+			workouts, err := r.Workouts.FindAllByUserID(ctx, alice)
+			requireNoError(t, err)
+			trashed, err := r.Workouts.FindTrashedByUserID(ctx, alice)
+			requireNoError(t, err)
+			metrics, err := r.Metrics.FindAllByUserID(ctx, alice)
+			requireNoError(t, err)
+			if len(workouts) != 0 || len(trashed) != 0 || len(metrics) != 0 {
+				t.Fatalf("data left after erasure: %d workouts, %d trashed, %d metrics", len(workouts), len(trashed), len(metrics))
+			}
+			entries, err := r.Audit.Find(ctx, audit.Filter{OwnerID: alice})
+			requireNoError(t, err)
+			requireEqual(t, []int64{}, resourceIDs(entries))
+			_, err = r.Erasure.FindByUserID(ctx, alice)
+			requireErasureNotFound(t, err)
+
+			// Данные другого пользователя не затронуты
+			_, err = r.Users.FindOne(ctx, "bob")
+			requireNoError(t, err)
+			workouts, err = r.Workouts.FindAllByUserID(ctx, bob)
+			requireNoError(t, err)
+			trashed, err = r.Workouts.FindTrashedByUserID(ctx, bob)
+			requireNoError(t, err)
+			metrics, err = r.Metrics.FindAllByUserID(ctx, bob)
+			requireNoError(t, err)
+			if len(workouts) != 1 || len(trashed) != 1 || len(metrics) != 1 {
+				t.Fatalf("bob has %d workouts, %d trashed, %d metrics; want 1 each", len(workouts), len(trashed), len(metrics))
+			}
+			entries, err = r.Audit.Find(ctx, audit.Filter{OwnerID: bob})
+			requireNoError(t, err)
+			requireEqual(t, []int64{3, 2}, resourceIDs(entries))
+			_, err = r.Erasure.FindByUserID(ctx, bob)
+			requireNoError(t, err)
+
+			// Повторное удаление не считается ошибкой: проход мог прерваться после части шагов
+			requireNoError(t, r.Erasure.EraseUser(ctx, alice))
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package storagetest
+
+import (
+	"context"
+	"fit-journal/pkg/client/mongodb"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// MongoDBURIEnv переменная со строкой подключения (mongodb://...) для контрактных тестов MongoDB
+const MongoDBURIEnv = "TEST_MONGODB_URI"
+
+// MongoDB возвращает пустую базу, созданную для теста и удаляемую после него.
+// Если TEST_MONGODB_URI не задана, тест пропускается.
+func MongoDB(t *testing.T) *mongo.Database {
+	t.Helper()
+	uri := os.Getenv(MongoDBURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", MongoDBURIEnv)
+	}
+	ctx := context.Background()
+
+	db, err := mongodb.Connect(ctx, uri, fmt.Sprintf("storagetest_%d", rand.Int63()))
+	if err != nil {
+		t.Fatalf("connect to %s: %v", MongoDBURIEnv, err)
+	}
+	t.Cleanup(func() {
+		if err := db.Drop(ctx); err != nil {
+			t.Errorf("drop database %s: %v", db.Name(), err)
+		}
+		db.Client().Disconnect(ctx)
+	})
+	return db
+}
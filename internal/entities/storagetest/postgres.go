@@ -0,0 +1,59 @@
+package storagetest
+
+import (
+	"context"
+	"fit-journal/internal/config"
+	"fit-journal/pkg/client/postgresql"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"math/rand"
+	"net/url"
+	"os"
+	"testing"
+)
+
+// DatabaseURLEnv переменная со строкой подключения (postgres://...) к базе для контрактных тестов PostgreSQL
+const DatabaseURLEnv = "TEST_DATABASE_URL"
+
+// Postgres возвращает клиент к пустой схеме, созданной для теста и удаляемой после него.
+// Если TEST_DATABASE_URL не задана, тест пропускается.
+func Postgres(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv(DatabaseURLEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", DatabaseURLEnv)
+	}
+	ctx := context.Background()
+
+	admin, err := pgxpool.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect to %s: %v", DatabaseURLEnv, err)
+	}
+	schema := fmt.Sprintf("storagetest_%d", rand.Int63())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close()
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("drop schema %s: %v", schema, err)
+		}
+		admin.Close()
+	})
+
+	// Таблицы создаются в схеме теста: неизвестные параметры строки подключения pgx передаёт серверу
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("%s must be a URL: %v", DatabaseURLEnv, err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+
+	pool, err := postgresql.NewClient(ctx, 1, config.StorageConfig{URL: u.String()})
+	if err != nil {
+		t.Fatalf("initialize schema: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
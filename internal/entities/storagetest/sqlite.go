@@ -0,0 +1,20 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"fit-journal/pkg/client/sqlite"
+	"path/filepath"
+	"testing"
+)
+
+// SQLite возвращает клиент к новой базе во временном каталоге теста
+func SQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.NewClient(context.Background(), filepath.Join(t.TempDir(), "fit-journal.db"))
+	if err != nil {
+		t.Fatalf("initialize schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
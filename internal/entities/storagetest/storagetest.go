@@ -0,0 +1,530 @@
+// Package storagetest общий контракт репозиториев пользователей, тренировок, метрик, упражнений,
+// журнала аудита и запросов на удаление аккаунтов.
+//
+// Каждый бэкенд хранения (memory, PostgreSQL, SQLite, MongoDB) запускает эти проверки из своих тестов, чтобы
+// обработчики вели себя одинаково независимо от хранилища. Фабрика вызывается для каждой
+// проверки и должна возвращать пустое хранилище.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/internal/entities/user"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/internal/erasure"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// Repositories репозитории проверяемого бэкенда. Проверке нужны только репозитории её сущности и Users;
+// RunErasure также нужны Workouts, Metrics и Audit над тем же хранилищем, чтобы проверить удаление данных.
+type Repositories struct {
+	Users     user.Repository
+	Workouts  workout.Repository
+	Metrics   metric.Repository
+	Exercises exercise.Repository
+	Audit     audit.Repository
+	Erasure   erasure.Repository
+}
+
+// Factory создаёт пустое хранилище для одной проверки
+type Factory func(t *testing.T) Repositories
+
+// run запускает проверки как подтесты, каждую на новом хранилище
+func run(t *testing.T, newRepos Factory, cases map[string]func(t *testing.T, r Repositories)) {
+	t.Helper()
+	for name, check := range cases {
+		check := check
+		t.Run(name, func(t *testing.T) {
+			check(t, newRepos(t))
+		})
+	}
+}
+
+// createUser создаёт пользователя и возвращает его ID
+func createUser(t *testing.T, r Repositories, username string) int64 {
+	t.Helper()
+	ctx := context.Background()
+	if err := r.Users.Create(ctx, user.User{Username: username, PasswordHash: "hash-" + username}); err != nil {
+		t.Fatalf("create user %s: %v", username, err)
+	}
+	u, err := r.Users.FindOne(ctx, username)
+	if err != nil {
+		t.Fatalf("find user %s: %v", username, err)
+	}
+	return u.ID
+}
+
+func requireNotFound(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("expected apperror.ErrNotFound, got %v", err)
+	}
+}
+
+func requireConflict(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("expected apperror.ErrConflict, got %v", err)
+	}
+}
+
+func requireNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func requireEqual(t *testing.T, want, got interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("mismatch:\nwant %+v\n got %+v", want, got)
+	}
+}
+
+// RunUsers проверяет user.Repository
+func RunUsers(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	run(t, newRepos, map[string]func(t *testing.T, r Repositories){
+		"CreateAndFindOne": func(t *testing.T, r Repositories) {
+			want := user.User{Username: "alice", PasswordHash: "hash", BirthDate: "1990-05-01", Height: "170"}
+			requireNoError(t, r.Users.Create(ctx, want))
+
+			got, err := r.Users.FindOne(ctx, "alice")
+			requireNoError(t, err)
+			if got.ID == 0 {
+				t.Fatal("expected generated ID")
+			}
+			want.ID = got.ID
+			requireEqual(t, want, got)
+		},
+		"FindOneMissing": func(t *testing.T, r Repositories) {
+			_, err := r.Users.FindOne(ctx, "nobody")
+			requireNotFound(t, err)
+		},
+		"DuplicateUsername": func(t *testing.T, r Repositories) {
+			createUser(t, r, "alice")
+			requireConflict(t, r.Users.Create(ctx, user.User{Username: "alice", PasswordHash: "other"}))
+
+			id := createUser(t, r, "bob")
+			requireConflict(t, r.Users.Update(ctx, user.User{ID: id, Username: "alice", PasswordHash: "hash-bob"}))
+		},
+		"Update": func(t *testing.T, r Repositories) {
+			id := createUser(t, r, "alice")
+			updated := user.User{ID: id, Username: "alice2", PasswordHash: "new", BirthDate: "1991-01-01", Height: "180"}
+			requireNoError(t, r.Users.Update(ctx, updated))
+
+			_, err := r.Users.FindOne(ctx, "alice")
+			requireNotFound(t, err)
+			got, err := r.Users.FindOne(ctx, "alice2")
+			requireNoError(t, err)
+			requireEqual(t, updated, got)
+		},
+		"DeleteIsSoft": func(t *testing.T, r Repositories) {
+			id := createUser(t, r, "alice")
+			requireNoError(t, r.Users.Delete(ctx, "alice"))
+
+			_, err := r.Users.FindOne(ctx, "alice")
+			requireNotFound(t, err)
+
+			// Удалённый пользователь не обновляется, а его имя остаётся занятым
+			requireNoError(t, r.Users.Update(ctx, user.User{ID: id, Username: "alice", PasswordHash: "new"}))
+			_, err = r.Users.FindOne(ctx, "alice")
+			requireNotFound(t, err)
+			requireConflict(t, r.Users.Create(ctx, user.User{Username: "alice", PasswordHash: "hash"}))
+
+			all, err := r.Users.FindAll(ctx)
+			requireNoError(t, err)
+			if len(all) != 1 || all[0].ID != id {
+				t.Fatalf("FindAll must include deleted users, got %+v", all)
+			}
+		},
+		"FindAllOmitsPasswordHash": func(t *testing.T, r Repositories) {
+			createUser(t, r, "alice")
+			createUser(t, r, "bob")
+
+			all, err := r.Users.FindAll(ctx)
+			requireNoError(t, err)
+			if len(all) != 2 {
+				t.Fatalf("expected 2 users, got %d", len(all))
+			}
+			for _, u := range all {
+				if u.PasswordHash != "" {
+					t.Fatalf("FindAll returned password hash for %s", u.Username)
+				}
+			}
+		},
+	})
+}
+
+// sampleWorkout силовая тренировка с одним упражнением и подходом
+func sampleWorkout(userID, startTime int64) workout.Workout {
+	return workout.Workout{
+		UserID:    userID,
+		StartTime: startTime,
+		Exercises: []exercise.Exercise{{
+			ID:   10,
+			Name: "Squat",
+			Sets: []exercise.ExerciseSet{{ID: 100, Reps: 5, Weight: 100}},
+		}},
+		Groups: []exercise.Group{},
+	}
+}
+
+// RunWorkouts проверяет workout.Repository, включая корзину
+func RunWorkouts(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	run(t, newRepos, map[string]func(t *testing.T, r Repositories){
+		"CreateAndFindOne": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			want := sampleWorkout(userID, 1000)
+			id, err := r.Workouts.Create(ctx, want)
+			requireNoError(t, err)
+
+			// Изменения переданного значения после сохранения не влияют на хранилище
+			want.Exercises[0].Sets[0].Reps = 99
+
+			got, err := r.Workouts.FindOne(ctx, id)
+			requireNoError(t, err)
+			want = sampleWorkout(userID, 1000)
+			want.ID, want.Kind = id, workout.KindStrength
+			requireEqual(t, want, got)
+		},
+		"CardioRoundTrip": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			want := workout.Workout{
+				UserID:    userID,
+				StartTime: 2000,
+				Kind:      workout.KindCardio,
+				Exercises: []exercise.Exercise{},
+				Groups:    []exercise.Group{},
+				Cardio:    &workout.Cardio{Activity: workout.ActivityRun, DistanceMeters: 5000},
+			}
+			id, err := r.Workouts.Create(ctx, want)
+			requireNoError(t, err)
+
+			got, err := r.Workouts.FindOne(ctx, id)
+			requireNoError(t, err)
+			want.ID = id
+			requireEqual(t, want, got)
+		},
+		"FindOneMissing": func(t *testing.T, r Repositories) {
+			_, err := r.Workouts.FindOne(ctx, 404)
+			requireNotFound(t, err)
+			_, err = r.Workouts.FindOneTrashed(ctx, 404)
+			requireNotFound(t, err)
+		},
+		"FindAllByUserID": func(t *testing.T, r Repositories) {
+			alice, bob := createUser(t, r, "alice"), createUser(t, r, "bob")
+			first, err := r.Workouts.Create(ctx, sampleWorkout(alice, 1000))
+			requireNoError(t, err)
+			second, err := r.Workouts.Create(ctx, sampleWorkout(alice, 2000))
+			requireNoError(t, err)
+			_, err = r.Workouts.Create(ctx, sampleWorkout(bob, 1000))
+			requireNoError(t, err)
+
+			workouts, err := r.Workouts.FindAllByUserID(ctx, alice)
+			requireNoError(t, err)
+			ids := map[int64]bool{}
+			for _, w := range workouts {
+				ids[w.ID] = true
+			}
+			requireEqual(t, map[int64]bool{first: true, second: true}, ids)
+
+			requireNoError(t, r.Workouts.Trash(ctx, first, 50))
+			workouts, err = r.Workouts.FindAllByUserID(ctx, alice)
+			requireNoError(t, err)
+			if len(workouts) != 1 || workouts[0].ID != second {
+				t.Fatalf("trashed workout must be hidden, got %+v", workouts)
+			}
+
+			empty, err := r.Workouts.FindAllByUserID(ctx, 404)
+			requireNoError(t, err)
+			if empty == nil || len(empty) != 0 {
+				t.Fatalf("expected empty non-nil slice, got %#v", empty)
+			}
+		},
+		"ExistsByStartTime": func(t *testing.T, r Repositories) {
+			alice, bob := createUser(t, r, "alice"), createUser(t, r, "bob")
+			id, err := r.Workouts.Create(ctx, sampleWorkout(alice, 1000))
+			requireNoError(t, err)
+
+			for _, c := range []struct {
+				userID, start int64
+				want          bool
+			}{{alice, 1000, true}, {alice, 2000, false}, {bob, 1000, false}} {
+				exists, err := r.Workouts.ExistsByStartTime(ctx, c.userID, c.start)
+				requireNoError(t, err)
+				if exists != c.want {
+					t.Fatalf("ExistsByStartTime(%d, %d) = %v, want %v", c.userID, c.start, exists, c.want)
+				}
+			}
+
+			requireNoError(t, r.Workouts.Trash(ctx, id, 50))
+			exists, err := r.Workouts.ExistsByStartTime(ctx, alice, 1000)
+			requireNoError(t, err)
+			if exists {
+				t.Fatal("trashed workout must not be reported by ExistsByStartTime")
+			}
+		},
+		"UpdateKeepsTrashState": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			id, err := r.Workouts.Create(ctx, sampleWorkout(userID, 1000))
+			requireNoError(t, err)
+
+			updated := sampleWorkout(userID, 1500)
+			updated.ID, updated.Kind = id, workout.KindStrength
+			updated.Exercises[0].Sets = append(updated.Exercises[0].Sets, exercise.ExerciseSet{ID: 101, Reps: 3, Weight: 110})
+			requireNoError(t, r.Workouts.Update(ctx, updated))
+			got, err := r.Workouts.FindOne(ctx, id)
+			requireNoError(t, err)
+			requireEqual(t, updated, got)
+
+			requireNoError(t, r.Workouts.Trash(ctx, id, 50))
+			requireNoError(t, r.Workouts.Update(ctx, updated))
+			_, err = r.Workouts.FindOne(ctx, id)
+			requireNotFound(t, err)
+		},
+		"TrashAndRestore": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			id, err := r.Workouts.Create(ctx, sampleWorkout(userID, 1000))
+			requireNoError(t, err)
+
+			requireNotFound(t, r.Workouts.Restore(ctx, id))
+			requireNoError(t, r.Workouts.Trash(ctx, id, 50))
+			requireNotFound(t, r.Workouts.Trash(ctx, id, 60))
+
+			_, err = r.Workouts.FindOne(ctx, id)
+			requireNotFound(t, err)
+			trashed, err := r.Workouts.FindOneTrashed(ctx, id)
+			requireNoError(t, err)
+			if trashed.DeletedAt == nil || *trashed.DeletedAt != 50 {
+				t.Fatalf("expected deleted_at 50, got %v", trashed.DeletedAt)
+			}
+
+			requireNoError(t, r.Workouts.Restore(ctx, id))
+			got, err := r.Workouts.FindOne(ctx, id)
+			requireNoError(t, err)
+			if got.DeletedAt != nil {
+				t.Fatalf("restored workout must not have deleted_at, got %v", *got.DeletedAt)
+			}
+			requireNotFound(t, r.Workouts.Trash(ctx, 404, 50))
+			requireNotFound(t, r.Workouts.Restore(ctx, 404))
+		},
+		"FindTrashedByUserID": func(t *testing.T, r Repositories) {
+			alice, bob := createUser(t, r, "alice"), createUser(t, r, "bob")
+			var ids []int64
+			for i, deletedAt := range []int64{10, 30, 20} {
+				id, err := r.Workouts.Create(ctx, sampleWorkout(alice, int64(1000+i)))
+				requireNoError(t, err)
+				requireNoError(t, r.Workouts.Trash(ctx, id, deletedAt))
+				ids = append(ids, id)
+			}
+			_, err := r.Workouts.Create(ctx, sampleWorkout(alice, 5000))
+			requireNoError(t, err)
+			other, err := r.Workouts.Create(ctx, sampleWorkout(bob, 1000))
+			requireNoError(t, err)
+			requireNoError(t, r.Workouts.Trash(ctx, other, 40))
+
+			trashed, err := r.Workouts.FindTrashedByUserID(ctx, alice)
+			requireNoError(t, err)
+			var got []int64
+			for _, w := range trashed {
+				got = append(got, w.ID)
+			}
+			// Недавно удалённые первыми
+			requireEqual(t, []int64{ids[1], ids[2], ids[0]}, got)
+		},
+		"FindAllWithTrashedItems": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			deletedAt := int64(50)
+
+			_, err := r.Workouts.Create(ctx, sampleWorkout(userID, 1000))
+			requireNoError(t, err)
+
+			withExercise := sampleWorkout(userID, 2000)
+			withExercise.Exercises[0].DeletedAt = &deletedAt
+			exerciseID, err := r.Workouts.Create(ctx, withExercise)
+			requireNoError(t, err)
+
+			withSet := sampleWorkout(userID, 3000)
+			withSet.Exercises[0].Sets[0].DeletedAt = &deletedAt
+			setID, err := r.Workouts.Create(ctx, withSet)
+			requireNoError(t, err)
+
+			// Тренировки в корзине целиком очищаются отдельно
+			trashed, err := r.Workouts.Create(ctx, withSet)
+			requireNoError(t, err)
+			requireNoError(t, r.Workouts.Trash(ctx, trashed, 60))
+
+			workouts, err := r.Workouts.FindAllWithTrashedItems(ctx)
+			requireNoError(t, err)
+			ids := map[int64]bool{}
+			for _, w := range workouts {
+				ids[w.ID] = true
+			}
+			requireEqual(t, map[int64]bool{exerciseID: true, setID: true}, ids)
+		},
+		"PurgeTrashed": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			active, err := r.Workouts.Create(ctx, sampleWorkout(userID, 1000))
+			requireNoError(t, err)
+			var ids []int64
+			for i, deletedAt := range []int64{10, 20, 30} {
+				id, err := r.Workouts.Create(ctx, sampleWorkout(userID, int64(2000+i)))
+				requireNoError(t, err)
+				requireNoError(t, r.Workouts.Trash(ctx, id, deletedAt))
+				ids = append(ids, id)
+			}
+
+			purged, err := r.Workouts.PurgeTrashed(ctx, 30)
+			requireNoError(t, err)
+			if purged != 2 {
+				t.Fatalf("expected 2 purged workouts, got %d", purged)
+			}
+			_, err = r.Workouts.FindOneTrashed(ctx, ids[0])
+			requireNotFound(t, err)
+			_, err = r.Workouts.FindOneTrashed(ctx, ids[2])
+			requireNoError(t, err)
+			_, err = r.Workouts.FindOne(ctx, active)
+			requireNoError(t, err)
+		},
+		"Delete": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			id, err := r.Workouts.Create(ctx, sampleWorkout(userID, 1000))
+			requireNoError(t, err)
+
+			requireNoError(t, r.Workouts.Delete(ctx, id))
+			_, err = r.Workouts.FindOne(ctx, id)
+			requireNotFound(t, err)
+			_, err = r.Workouts.FindOneTrashed(ctx, id)
+			requireNotFound(t, err)
+			requireNoError(t, r.Workouts.Delete(ctx, id))
+		},
+	})
+}
+
+// RunMetrics проверяет metric.Repository
+func RunMetrics(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	run(t, newRepos, map[string]func(t *testing.T, r Repositories){
+		"CreateAndFindOne": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			want := metric.Metric{UserID: userID, Weight: "80.5", Day: "2024-03-01"}
+			id, err := r.Metrics.Create(ctx, want)
+			requireNoError(t, err)
+
+			got, err := r.Metrics.FindOne(ctx, id)
+			requireNoError(t, err)
+			want.ID = id
+			requireEqual(t, want, got)
+
+			_, err = r.Metrics.FindOne(ctx, 404)
+			requireNotFound(t, err)
+		},
+		"FindAllByUserIDOrderedByDay": func(t *testing.T, r Repositories) {
+			alice, bob := createUser(t, r, "alice"), createUser(t, r, "bob")
+			var ids []int64
+			for _, day := range []string{"2024-03-02", "2024-03-01", "2024-03-02"} {
+				id, err := r.Metrics.Create(ctx, metric.Metric{UserID: alice, CaloriesConsumed: "2000", Day: day})
+				requireNoError(t, err)
+				ids = append(ids, id)
+			}
+			_, err := r.Metrics.Create(ctx, metric.Metric{UserID: bob, Weight: "70", Day: "2024-01-01"})
+			requireNoError(t, err)
+
+			metrics, err := r.Metrics.FindAllByUserID(ctx, alice)
+			requireNoError(t, err)
+			var got []int64
+			for _, m := range metrics {
+				got = append(got, m.ID)
+			}
+			requireEqual(t, []int64{ids[1], ids[0], ids[2]}, got)
+
+			empty, err := r.Metrics.FindAllByUserID(ctx, 404)
+			requireNoError(t, err)
+			if empty == nil || len(empty) != 0 {
+				t.Fatalf("expected empty non-nil slice, got %#v", empty)
+			}
+		},
+		"UpdateKeepsOwner": func(t *testing.T, r Repositories) {
+			alice, bob := createUser(t, r, "alice"), createUser(t, r, "bob")
+			id, err := r.Metrics.Create(ctx, metric.Metric{UserID: alice, Weight: "80", Day: "2024-03-01"})
+			requireNoError(t, err)
+
+			requireNoError(t, r.Metrics.Update(ctx, metric.Metric{ID: id, UserID: bob, Weight: "79", CaloriesConsumed: "1800", Day: "2024-03-02"}))
+			got, err := r.Metrics.FindOne(ctx, id)
+			requireNoError(t, err)
+			requireEqual(t, metric.Metric{ID: id, UserID: alice, Weight: "79", CaloriesConsumed: "1800", Day: "2024-03-02"}, got)
+		},
+		"Delete": func(t *testing.T, r Repositories) {
+			userID := createUser(t, r, "alice")
+			id, err := r.Metrics.Create(ctx, metric.Metric{UserID: userID, Weight: "80", Day: "2024-03-01"})
+			requireNoError(t, err)
+
+			requireNoError(t, r.Metrics.Delete(ctx, id))
+			_, err = r.Metrics.FindOne(ctx, id)
+			requireNotFound(t, err)
+		},
+	})
+}
+
+// RunExercises проверяет exercise.Repository
+func RunExercises(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	run(t, newRepos, map[string]func(t *testing.T, r Repositories){
+		"CreateAndFindOne": func(t *testing.T, r Repositories) {
+			id, err := r.Exercises.Create(ctx, exercise.Exercise{Name: "Squat", Description: "Back squat"})
+			requireNoError(t, err)
+
+			got, err := r.Exercises.FindOne(ctx, strconv.FormatInt(id, 10))
+			requireNoError(t, err)
+			// Подходы без значения сохраняются пустым списком
+			requireEqual(t, exercise.Exercise{ID: id, Name: "Squat", Sets: []exercise.ExerciseSet{}, Description: "Back squat"}, got)
+
+			_, err = r.Exercises.FindOne(ctx, "404")
+			requireNotFound(t, err)
+		},
+		"FindAllOrderedByName": func(t *testing.T, r Repositories) {
+			var ids []int64
+			for _, name := range []string{"Squat", "Bench press", "Deadlift"} {
+				id, err := r.Exercises.Create(ctx, exercise.Exercise{Name: name, Sets: []exercise.ExerciseSet{{ID: 1, Reps: 5, Weight: 60}}})
+				requireNoError(t, err)
+				ids = append(ids, id)
+			}
+
+			all, err := r.Exercises.FindAll(ctx)
+			requireNoError(t, err)
+			var got []int64
+			for _, e := range all {
+				got = append(got, e.ID)
+			}
+			requireEqual(t, []int64{ids[1], ids[2], ids[0]}, got)
+			requireEqual(t, []exercise.ExerciseSet{{ID: 1, Reps: 5, Weight: 60}}, all[0].Sets)
+		},
+		"Update": func(t *testing.T, r Repositories) {
+			id, err := r.Exercises.Create(ctx, exercise.Exercise{Name: "Squat"})
+			requireNoError(t, err)
+
+			want := exercise.Exercise{ID: id, Name: "Front squat", Sets: []exercise.ExerciseSet{{ID: 2, Reps: 8, Weight: 50}}, Description: "Front rack"}
+			requireNoError(t, r.Exercises.Update(ctx, want))
+			got, err := r.Exercises.FindOne(ctx, strconv.FormatInt(id, 10))
+			requireNoError(t, err)
+			requireEqual(t, want, got)
+		},
+		"Delete": func(t *testing.T, r Repositories) {
+			id, err := r.Exercises.Create(ctx, exercise.Exercise{Name: "Squat"})
+			requireNoError(t, err)
+
+			requireNoError(t, r.Exercises.Delete(ctx, strconv.FormatInt(id, 10)))
+			_, err = r.Exercises.FindOne(ctx, strconv.FormatInt(id, 10))
+			requireNotFound(t, err)
+		},
+	})
+}
@@ -2,17 +2,19 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fit-journal/internal/apperror"
 	"fit-journal/internal/entities/user"
 	"fit-journal/pkg/client/postgresql"
 	"fit-journal/pkg/logging"
 	"fmt"
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"strings"
 )
 
 type Repository struct {
 	client postgresql.Client
-	logger *logging.Logger
 }
 
 // formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
@@ -29,14 +31,17 @@ func (r *Repository) Create(ctx context.Context, user user.User) error {
             ($1, $2, $3, $4)
         RETURNING id
     `
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", q))
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", q))
 
 	// Сканируем ID в user.ID
 	if err := r.client.QueryRow(ctx, q, user.Username, user.PasswordHash, user.BirthDate, user.Height).Scan(&user.ID); err != nil {
+		if postgresql.UniqueViolation(err) {
+			return apperror.Conflict("username %q is already taken", user.Username)
+		}
 		if pgErr, ok := err.(*pgconn.PgError); ok {
-			newErr := fmt.Errorf("SQL Error: %s, Detail: %s, Where: %s, Code: %s, SQLState: %s",
-				pgErr.Message, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
-			r.logger.Error(newErr)
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s",
+				pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
 			return newErr
 		}
 		return err
@@ -50,7 +55,7 @@ func (r *Repository) FindAll(ctx context.Context) ([]user.User, error) {
 	q := `
 		SELECT id, username, birth_date, height FROM users;
 	`
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
 
 	rows, err := r.client.Query(ctx, q)
 	if err != nil {
@@ -82,11 +87,14 @@ func (r *Repository) FindOne(ctx context.Context, username string) (user.User, e
 	q := `
 		SELECT id, username, password_hash, birth_date, height FROM users WHERE username = $1 AND is_deleted = FALSE
 	`
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
 
 	var u user.User
 	err := r.client.QueryRow(ctx, q, username).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.BirthDate, &u.Height)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, apperror.NotFound("user %q not found", username)
+		}
 		return user.User{}, err
 	}
 
@@ -100,13 +108,16 @@ func (r *Repository) Update(ctx context.Context, user user.User) error {
 		SET username = $1, password_hash = $2, birth_date = $3, height = $4
 		WHERE id = $5  AND is_deleted = FALSE
 	`
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
 
 	_, err := r.client.Exec(ctx, q, user.Username, user.PasswordHash, user.BirthDate, user.Height, user.ID)
 	if err != nil {
+		if postgresql.UniqueViolation(err) {
+			return apperror.Conflict("username %q is already taken", user.Username)
+		}
 		if pgErr, ok := err.(*pgconn.PgError); ok {
-			newErr := fmt.Errorf("SQL Error: %s, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr.Message, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
-			r.logger.Error(newErr)
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
 			return newErr
 		}
 		return err
@@ -120,13 +131,13 @@ func (r *Repository) Delete(ctx context.Context, username string) error {
 	q := `
 		UPDATE users SET is_deleted = TRUE WHERE username = $1
 	`
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
 
 	_, err := r.client.Exec(ctx, q, username)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
-			newErr := fmt.Errorf("SQL Error: %s, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr.Message, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
-			r.logger.Error(newErr)
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
 			return newErr
 		}
 		return err
@@ -136,9 +147,8 @@ func (r *Repository) Delete(ctx context.Context, username string) error {
 }
 
 // NewRepository создает новый экземпляр репозитория
-func NewRepository(client postgresql.Client, logger *logging.Logger) user.Repository {
+func NewRepository(client postgresql.Client) user.Repository {
 	return &Repository{
 		client: client,
-		logger: logger,
 	}
 }
@@ -1,8 +1,22 @@
 package user
 
 type CreateUserDTO struct {
-	Username  string `json:"username,omitempty"`
-	Password  string `json:"password,omitempty"`
-	BirthDate string `json:"birth_date"`
-	Height    string `json:"height,omitempty"`
+	Username  string `json:"username,omitempty" validate:"required,max=64"`
+	Password  string `json:"password,omitempty" validate:"required,max=72"` // bcrypt учитывает только первые 72 байта
+	BirthDate string `json:"birth_date" validate:"date,past"`
+	Height    string `json:"height,omitempty" validate:"number,min=1,max=300"` // Рост в сантиметрах
+}
+
+// UpdateUserDTO поля профиля для обновления; пустые поля не меняются
+type UpdateUserDTO struct {
+	Username  string `json:"username,omitempty" validate:"max=64"`
+	Password  string `json:"password,omitempty" validate:"max=72"`
+	BirthDate string `json:"birth_date,omitempty" validate:"date,past"`
+	Height    string `json:"height,omitempty" validate:"number,min=1,max=300"`
+}
+
+// LoginDTO данные для входа
+type LoginDTO struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
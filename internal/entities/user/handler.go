@@ -2,17 +2,19 @@ package user
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
 	"fit-journal/internal/auth"
 	"fit-journal/internal/handlers"
 	"fit-journal/pkg/logging"
-	repeatable "fit-journal/pkg/utils"
+	"fit-journal/pkg/metrics"
+	"fit-journal/pkg/uow"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const (
@@ -20,17 +22,31 @@ const (
 	userURL     = "/users"
 	registerURL = "/auth/register"
 	loginURL    = "/auth/login"
+
+	// invalidCredentials ответ на вход с неизвестным именем или неверным паролем
+	invalidCredentials = "Invalid login or password"
 )
 
+// ErasureScheduler планирует полное удаление пользователя и всех его данных
+type ErasureScheduler interface {
+	ScheduleErasure(ctx context.Context, u User) (time.Time, error)
+}
+
 type handler struct {
-	logger     *logging.Logger
 	repository Repository
+	erasure    ErasureScheduler
+	uow        uow.UnitOfWork
+	audit      *audit.Recorder
+	auth       *auth.Service
 }
 
-func NewHandler(logger *logging.Logger, repo Repository) handlers.Handler {
+func NewHandler(repo Repository, unitOfWork uow.UnitOfWork, erasure ErasureScheduler, recorder *audit.Recorder, authService *auth.Service) handlers.Handler {
 	return &handler{
-		logger:     logger,
 		repository: repo,
+		uow:        unitOfWork,
+		erasure:    erasure,
+		audit:      recorder,
+		auth:       authService,
 	}
 }
 
@@ -40,49 +56,25 @@ func (h *handler) Register(router *httprouter.Router) {
 	router.HandlerFunc(http.MethodPost, loginURL, apperror.Middleware(h.Login))
 
 	// Защищенные маршруты
-	router.HandlerFunc(http.MethodGet, userURL, apperror.Middleware(auth.TokenAuthMiddleware(h.GetUserByUsername))) // Получить пользователя по UUID
-	router.HandlerFunc(http.MethodPut, userURL, apperror.Middleware(auth.TokenAuthMiddleware(h.UpdateUser)))        // Обновить пользователя
-	router.HandlerFunc(http.MethodDelete, userURL, apperror.Middleware(auth.TokenAuthMiddleware(h.DeleteUser)))     // Удалить пользователя
+	router.HandlerFunc(http.MethodGet, userURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.GetUserByUsername))) // Получить пользователя по UUID
+	router.HandlerFunc(http.MethodPut, userURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.UpdateUser)))        // Обновить пользователя
+	router.HandlerFunc(http.MethodDelete, userURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.DeleteUser)))     // Запланировать удаление пользователя
 }
 
 func (h *handler) RegisterUser(w http.ResponseWriter, r *http.Request) error {
-	h.logger.Info("Registering new user")
+	logging.FromContext(r.Context()).Info("Registering new user")
 
 	var reqBody CreateUserDTO
 
-	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-		h.logger.Error(err)
+	if err := handlers.Decode(r, &reqBody); err != nil {
+		logging.FromContext(r.Context()).Error(err)
 		return apperror.NewAppError(err, "Invalid registration data", "", http.StatusBadRequest)
 	}
 
-	// Проверка обязательных полей
-	err := repeatable.ValidateRequiredFields(map[string]string{
-		"username": reqBody.Username,
-		"password": reqBody.Password,
-	})
+	// Хэшируем пароль до начала транзакции: bcrypt медленный
+	hashedPassword, err := h.auth.HashPassword(reqBody.Password)
 	if err != nil {
-		h.logger.Error(err)
-		return apperror.NewAppError(err, err.Error(), "", http.StatusBadRequest)
-	}
-
-	// Проверяем, существует ли пользователь с таким же именем
-	ctx := context.Background()
-	foundUser, errFindOne := h.repository.FindOne(ctx, reqBody.Username)
-	if foundUser.Username != "" {
-		// Если пользователь найден, возвращаем ошибку
-		h.logger.Error("User with this username already exists")
-		return apperror.NewAppError(nil, "User with this username already exists", "", http.StatusConflict) // Conflict 409
-	} else if errFindOne != nil && errFindOne.Error() != "no rows in result set" {
-		h.logger.Info(errFindOne)
-		return apperror.NewAppError(err, "Failed", "", http.StatusInternalServerError)
-	}
-
-	// Если пользователь не найден и нет ошибки, продолжаем регистрацию
-
-	// Хэшируем пароль
-	hashedPassword, err := auth.HashPassword(reqBody.Password)
-	if err != nil {
-		h.logger.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		return apperror.NewAppError(err, "Password hashing error", "", http.StatusInternalServerError)
 	}
 
@@ -93,55 +85,74 @@ func (h *handler) RegisterUser(w http.ResponseWriter, r *http.Request) error {
 		Height:       reqBody.Height,
 	}
 
-	// Создаем пользователя в базе данных
-	if err := h.repository.Create(ctx, newUser); err != nil {
-		h.logger.Error(err)
-		return apperror.NewAppError(err, "Failed to save user", "", http.StatusInternalServerError)
+	// Проверка имени и создание выполняются в одной единице работы, чтобы два одновременных
+	// запроса не зарегистрировали одно и то же имя
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		_, errFindOne := h.repository.FindOne(ctx, reqBody.Username)
+		if errFindOne == nil {
+			// Если пользователь найден, возвращаем ошибку
+			logging.FromContext(r.Context()).Error("User with this username already exists")
+			return apperror.NewAppError(apperror.ErrConflict, "User with this username already exists", "", http.StatusConflict)
+		} else if !errors.Is(errFindOne, apperror.ErrNotFound) {
+			logging.FromContext(r.Context()).Error(errFindOne)
+			return apperror.NewAppError(errFindOne, "Failed", "", http.StatusInternalServerError)
+		}
+
+		// Создаем пользователя в базе данных; имя могли занять удалённым аккаунтом, тогда ошибка ErrConflict
+		if err := h.repository.Create(ctx, newUser); err != nil {
+			logging.FromContext(r.Context()).Error(err)
+			if errors.Is(err, apperror.ErrConflict) {
+				return apperror.NewAppError(err, "User with this username already exists", "", http.StatusConflict)
+			}
+			return apperror.NewAppError(err, "Failed to save user", "", http.StatusInternalServerError)
+		}
+		created, err := h.repository.FindOne(ctx, newUser.Username)
+		if err != nil {
+			logging.FromContext(r.Context()).Error(err)
+			return apperror.NewAppError(err, "Failed to save user", "", http.StatusInternalServerError)
+		}
+		newUser.ID = created.ID
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	h.audit.Record(r, audit.ActionCreate, audit.ResourceUser, newUser.ID, newUser.ID, nil, newUser)
+	metrics.UsersRegistered.Inc()
 
 	w.WriteHeader(http.StatusCreated)
 	return json.NewEncoder(w).Encode(newUser)
 }
 
 func (h *handler) Login(w http.ResponseWriter, r *http.Request) error {
-	h.logger.Info("User login")
-
-	var reqBody struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-	}
+	logging.FromContext(r.Context()).Info("User login")
 
-	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-		h.logger.Error(err)
+	var reqBody LoginDTO
+	if err := handlers.Decode(r, &reqBody); err != nil {
+		logging.FromContext(r.Context()).Error(err)
 		return apperror.NewAppError(err, "Invalid login data", "", http.StatusBadRequest)
 	}
 
-	// Проверка обязательных полей
-	err := repeatable.ValidateRequiredFields(map[string]string{
-		"username": reqBody.Username,
-		"password": reqBody.Password,
-	})
-	if err != nil {
-		h.logger.Error(err)
-		return apperror.NewAppError(err, err.Error(), "", http.StatusBadRequest)
-	}
-
 	// Получаем пользователя из базы данных по имени пользователя
-	ctx := context.Background()
+	ctx := r.Context()
+	// Неизвестное имя и неверный пароль дают одинаковый ответ, чтобы по нему нельзя было перебирать имена
 	user, err := h.repository.FindOne(ctx, reqBody.Username)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return apperror.NewAppError(nil, invalidCredentials, "", http.StatusUnauthorized)
+	}
 	if err != nil {
-		h.logger.Error(err)
-		return apperror.NewAppError(err, "User not found", "", http.StatusNotFound)
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to find user", "", http.StatusInternalServerError)
 	}
 	// Проверяем пароль
-	if !auth.CheckPasswordHash(reqBody.Password, user.PasswordHash) {
-		return apperror.NewAppError(nil, "Invalid login or password!", "", http.StatusUnauthorized)
+	if !h.auth.CheckPasswordHash(reqBody.Password, user.PasswordHash) {
+		return apperror.NewAppError(nil, invalidCredentials, "", http.StatusUnauthorized)
 	}
 
 	// Генерируем JWT токен
-	token, err := auth.GenerateJWT(user.Username)
+	token, err := h.auth.GenerateJWT(user.Username, user.ID)
 	if err != nil {
-		h.logger.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		return apperror.NewAppError(err, "Failed to generate token", "", http.StatusInternalServerError)
 	}
 
@@ -154,7 +165,7 @@ func (h *handler) Login(w http.ResponseWriter, r *http.Request) error {
 }
 
 func (h *handler) GetUserByUsername(w http.ResponseWriter, r *http.Request) error {
-	h.logger.Info("Fetching user by username")
+	logging.FromContext(r.Context()).Info("Fetching user by username")
 
 	// Извлекаем username из контекста, например, расшифрованный из JWT
 	username, ok := r.Context().Value("username").(string)
@@ -162,14 +173,13 @@ func (h *handler) GetUserByUsername(w http.ResponseWriter, r *http.Request) erro
 		return apperror.NewAppError(nil, "Invalid or missing username", "", http.StatusBadRequest)
 	}
 
-	ctx := context.Background()
+	ctx := r.Context()
 	usr, err := h.repository.FindOne(ctx, username)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			h.logger.Error("User not found")
-			return apperror.NewAppError(nil, "User not found", "", http.StatusNotFound)
+		logging.FromContext(r.Context()).Error(err)
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.NewAppError(err, "User not found", "", http.StatusNotFound)
 		}
-		h.logger.Error(err)
 		return apperror.NewAppError(err, "Failed to fetch user", "", http.StatusInternalServerError)
 	}
 
@@ -179,7 +189,7 @@ func (h *handler) GetUserByUsername(w http.ResponseWriter, r *http.Request) erro
 }
 
 func (h *handler) UpdateUser(w http.ResponseWriter, r *http.Request) error {
-	h.logger.Info("Updating user")
+	logging.FromContext(r.Context()).Info("Updating user")
 
 	// Получаем nickname пользователя из контекста
 	nickname, ok := r.Context().Value("username").(string)
@@ -187,53 +197,73 @@ func (h *handler) UpdateUser(w http.ResponseWriter, r *http.Request) error {
 		return apperror.NewAppError(nil, "Invalid or missing nickname in context", "", http.StatusUnauthorized)
 	}
 
-	// Ищем пользователя по никнейму
-	ctx := context.Background()
-	existingUser, err := h.repository.FindOne(ctx, nickname)
-	if err != nil {
-		h.logger.Error(err)
-		return apperror.NewAppError(err, "User not found", "", http.StatusNotFound)
-	}
-
 	// Парсим входящие данные для обновления
-	var updates User
-	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
-		h.logger.Error(err)
+	var updates UpdateUserDTO
+	if err := handlers.Decode(r, &updates); err != nil {
+		logging.FromContext(r.Context()).Error(err)
 		return apperror.NewAppError(err, "Invalid user data", "", http.StatusBadRequest)
 	}
 
-	// Обновляем только те поля, которые были переданы
-	if updates.Username != "" && updates.Username != existingUser.Username {
-		existingUser.Username = updates.Username
-	}
-	if updates.BirthDate != "" && updates.BirthDate != existingUser.BirthDate {
-		existingUser.BirthDate = updates.BirthDate
-	}
-	if updates.Height != "" && updates.Height != existingUser.Height {
-		existingUser.Height = updates.Height
-	}
-	if updates.PasswordHash != "" {
-		// Хэшируем новый пароль, если он был передан
-		hashedPassword, err := auth.HashPassword(updates.PasswordHash)
+	// Хэшируем новый пароль, если он был передан, до начала транзакции
+	var hashedPassword string
+	if updates.Password != "" {
+		var err error
+		hashedPassword, err = h.auth.HashPassword(updates.Password)
 		if err != nil {
-			h.logger.Error(err)
+			logging.FromContext(r.Context()).Error(err)
 			return apperror.NewAppError(err, "Failed to hash password", "", http.StatusInternalServerError)
 		}
-		existingUser.PasswordHash = hashedPassword
 	}
 
-	// Выполняем обновление в базе данных
-	if err := h.repository.Update(ctx, existingUser); err != nil {
-		h.logger.Error(err)
-		return apperror.NewAppError(err, "Failed to update user", "", http.StatusInternalServerError)
+	var before, existingUser User
+	err := h.uow.Do(r.Context(), func(ctx context.Context) error {
+		// Ищем пользователя по никнейму
+		var err error
+		existingUser, err = h.repository.FindOne(ctx, nickname)
+		if err != nil {
+			logging.FromContext(r.Context()).Error(err)
+			return apperror.NewAppError(err, "User not found", "", http.StatusInternalServerError)
+		}
+
+		// Обновляем только те поля, которые были переданы
+		before = existingUser
+		if updates.Username != "" && updates.Username != existingUser.Username {
+			existingUser.Username = updates.Username
+		}
+		if updates.BirthDate != "" && updates.BirthDate != existingUser.BirthDate {
+			existingUser.BirthDate = updates.BirthDate
+		}
+		if updates.Height != "" && updates.Height != existingUser.Height {
+			existingUser.Height = updates.Height
+		}
+		if hashedPassword != "" {
+			existingUser.PasswordHash = hashedPassword
+		}
+
+		// Выполняем обновление в базе данных
+		if err := h.repository.Update(ctx, existingUser); err != nil {
+			logging.FromContext(r.Context()).Error(err)
+			return apperror.NewAppError(err, "Failed to update user", "", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Хэш пароля не сериализуется, поэтому его смена отмечается отдельно без значений
+	changes := audit.Diff(before, existingUser)
+	if before.PasswordHash != existingUser.PasswordHash {
+		changes = append(changes, audit.RedactedChange("password"))
 	}
+	h.audit.RecordChanges(r, audit.ActionUpdate, audit.ResourceUser, existingUser.ID, existingUser.ID, changes)
 
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
 func (h *handler) DeleteUser(w http.ResponseWriter, r *http.Request) error {
-	h.logger.Info("Deleting user")
+	logging.FromContext(r.Context()).Info("Deleting user")
 	// Получаем nickname пользователя из контекста
 	nickname, ok := r.Context().Value("username").(string)
 	if !ok || strings.TrimSpace(nickname) == "" {
@@ -241,18 +271,24 @@ func (h *handler) DeleteUser(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	// Ищем пользователя по никнейму
-	ctx := context.Background()
+	ctx := r.Context()
 	existingUser, err := h.repository.FindOne(ctx, nickname)
 	if err != nil {
-		h.logger.Error(err)
-		return apperror.NewAppError(err, "User not found", "", http.StatusNotFound)
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "User not found", "", http.StatusInternalServerError)
 	}
 
-	if err := h.repository.Delete(ctx, existingUser.Username); err != nil {
-		h.logger.Error(err)
+	// Данные удаляются после срока ожидания, до этого запрос можно отменить через DELETE /users/erasure
+	eraseAfter, err := h.erasure.ScheduleErasure(ctx, existingUser)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
 		return apperror.NewAppError(err, "Failed to delete user", "", http.StatusInternalServerError)
 	}
+	h.audit.Record(r, audit.ActionDelete, audit.ResourceUser, existingUser.ID, existingUser.ID, existingUser, nil)
 
-	w.WriteHeader(http.StatusNoContent)
-	return nil
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(map[string]time.Time{
+		"erase_after": eraseAfter,
+	})
 }
@@ -0,0 +1,128 @@
+// Package memory хранит пользователей в памяти процесса: для тестов и режима --storage=memory.
+// Поведение повторяет репозиторий PostgreSQL, см. storagetest.
+package memory
+
+import (
+	"context"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/user"
+	"sort"
+	"sync"
+)
+
+type record struct {
+	user      user.User
+	isDeleted bool
+}
+
+type Repository struct {
+	mu     sync.RWMutex
+	users  map[int64]*record
+	nextID int64
+}
+
+// NewRepository создает пустой репозиторий
+func NewRepository() *Repository {
+	return &Repository{users: make(map[int64]*record)}
+}
+
+// byUsername ищет запись по имени, включая удалённых пользователей
+func (r *Repository) byUsername(username string) *record {
+	for _, rec := range r.users {
+		if rec.user.Username == username {
+			return rec
+		}
+	}
+	return nil
+}
+
+// Create создает нового пользователя. Имя уникально, в том числе среди удалённых пользователей.
+func (r *Repository) Create(_ context.Context, u user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byUsername(u.Username) != nil {
+		return apperror.Conflict("username %q is already taken", u.Username)
+	}
+	r.nextID++
+	u.ID = r.nextID
+	r.users[u.ID] = &record{user: u}
+	return nil
+}
+
+// FindAll возвращает список всех пользователей, включая удалённых, без хэшей паролей
+func (r *Repository) FindAll(_ context.Context) ([]user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]user.User, 0, len(r.users))
+	for _, rec := range r.users {
+		u := rec.user
+		u.PasswordHash = ""
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// FindOne ищет активного пользователя по имени
+func (r *Repository) FindOne(_ context.Context, username string) (user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec := r.byUsername(username)
+	if rec == nil || rec.isDeleted {
+		return user.User{}, apperror.NotFound("user %q not found", username)
+	}
+	return rec.user, nil
+}
+
+// Update обновляет информацию об активном пользователе
+func (r *Repository) Update(_ context.Context, u user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.users[u.ID]
+	if !ok || rec.isDeleted {
+		return nil
+	}
+	if other := r.byUsername(u.Username); other != nil && other != rec {
+		return apperror.Conflict("username %q is already taken", u.Username)
+	}
+	rec.user = u
+	return nil
+}
+
+// Delete помечает пользователя удалённым
+func (r *Repository) Delete(_ context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rec := r.byUsername(username); rec != nil {
+		rec.isDeleted = true
+	}
+	return nil
+}
+
+// DeletedIDs возвращает ID пользователей, помеченных удалёнными
+func (r *Repository) DeletedIDs() []int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []int64
+	for id, rec := range r.users {
+		if rec.isDeleted {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Erase безвозвратно удаляет пользователя
+func (r *Repository) Erase(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id)
+}
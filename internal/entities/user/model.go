@@ -1,5 +1,13 @@
 package user
 
+import (
+	"errors"
+	"time"
+)
+
+// BirthDateLayout формат даты рождения пользователя
+const BirthDateLayout = "2006-01-02"
+
 type User struct {
 	ID           int64  `json:"id"`
 	Username     string `json:"username"`
@@ -7,3 +15,23 @@ type User struct {
 	BirthDate    string `json:"birth_date"`
 	Height       string `json:"height,omitempty"`
 }
+
+// Age возвращает полный возраст пользователя на указанный момент
+func (u User) Age(now time.Time) (int, error) {
+	if u.BirthDate == "" {
+		return 0, errors.New("birth date is not set")
+	}
+	birth, err := time.Parse(BirthDateLayout, u.BirthDate)
+	if err != nil {
+		return 0, err
+	}
+
+	age := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		age--
+	}
+	if age < 0 {
+		return 0, errors.New("birth date is in the future")
+	}
+	return age, nil
+}
@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/user"
+	"fit-journal/pkg/client/mongodb"
+	"fit-journal/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Repository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// document пользователь в коллекции users
+type document struct {
+	ID           int64  `bson:"_id"`
+	Username     string `bson:"username"`
+	PasswordHash string `bson:"password_hash"`
+	BirthDate    string `bson:"birth_date"`
+	Height       string `bson:"height"`
+	IsDeleted    bool   `bson:"is_deleted"`
+}
+
+func (d document) user() user.User {
+	return user.User{ID: d.ID, Username: d.Username, PasswordHash: d.PasswordHash, BirthDate: d.BirthDate, Height: d.Height}
+}
+
+// Create создает нового пользователя
+func (r *Repository) Create(ctx context.Context, u user.User) error {
+	id, err := mongodb.NextID(ctx, r.db, r.collection.Name())
+	if err != nil {
+		return err
+	}
+	doc := document{ID: id, Username: u.Username, PasswordHash: u.PasswordHash, BirthDate: u.BirthDate, Height: u.Height}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperror.Conflict("username %q is already taken", u.Username)
+		}
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// FindAll возвращает список всех пользователей, включая удалённых, без хешей паролей
+func (r *Repository) FindAll(ctx context.Context) ([]user.User, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]user.User, 0)
+	for cursor.Next(ctx) {
+		var d document
+		if err := cursor.Decode(&d); err != nil {
+			return nil, err
+		}
+		u := d.user()
+		u.PasswordHash = ""
+		users = append(users, u)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindOne ищет активного пользователя по имени
+func (r *Repository) FindOne(ctx context.Context, username string) (user.User, error) {
+	var d document
+	if err := r.collection.FindOne(ctx, bson.M{"username": username, "is_deleted": false}).Decode(&d); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return user.User{}, apperror.NotFound("user %q not found", username)
+		}
+		return user.User{}, err
+	}
+	return d.user(), nil
+}
+
+// Update обновляет информацию об активном пользователе
+func (r *Repository) Update(ctx context.Context, u user.User) error {
+	update := bson.M{"$set": bson.M{
+		"username":      u.Username,
+		"password_hash": u.PasswordHash,
+		"birth_date":    u.BirthDate,
+		"height":        u.Height,
+	}}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": u.ID, "is_deleted": false}, update); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperror.Conflict("username %q is already taken", u.Username)
+		}
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Delete помечает пользователя удалённым
+func (r *Repository) Delete(ctx context.Context, username string) error {
+	update := bson.M{"$set": bson.M{"is_deleted": true}}
+	if _, err := r.collection.UpdateMany(ctx, bson.M{"username": username}, update); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(db *mongo.Database) *Repository {
+	return &Repository{
+		db:         db,
+		collection: db.Collection("users"),
+	}
+}
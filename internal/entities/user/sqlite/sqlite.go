@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/user"
+	"fit-journal/pkg/client/sqlite"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"strings"
+)
+
+type Repository struct {
+	client sqlite.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// Create создает нового пользователя
+func (r *Repository) Create(ctx context.Context, u user.User) error {
+	q := `
+		INSERT INTO users (username, password_hash, birth_date, height) VALUES (?, ?, ?, ?)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if _, err := r.client.ExecContext(ctx, q, u.Username, u.PasswordHash, u.BirthDate, u.Height); err != nil {
+		if sqlite.UniqueViolation(err) {
+			return apperror.Conflict("username %q is already taken", u.Username)
+		}
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// FindAll возвращает список всех пользователей, включая удалённых
+func (r *Repository) FindAll(ctx context.Context) ([]user.User, error) {
+	q := `
+		SELECT id, username, COALESCE(birth_date, ''), COALESCE(height, '') FROM users ORDER BY id
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]user.User, 0)
+	for rows.Next() {
+		var u user.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.BirthDate, &u.Height); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindOne ищет активного пользователя по имени
+func (r *Repository) FindOne(ctx context.Context, username string) (user.User, error) {
+	q := `
+		SELECT id, username, password_hash, COALESCE(birth_date, ''), COALESCE(height, '')
+		FROM users WHERE username = ? AND is_deleted = 0
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	var u user.User
+	err := r.client.QueryRowContext(ctx, q, username).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.BirthDate, &u.Height)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.User{}, apperror.NotFound("user %q not found", username)
+		}
+		return user.User{}, err
+	}
+	return u, nil
+}
+
+// Update обновляет информацию об активном пользователе
+func (r *Repository) Update(ctx context.Context, u user.User) error {
+	q := `
+		UPDATE users SET username = ?, password_hash = ?, birth_date = ?, height = ?
+		WHERE id = ? AND is_deleted = 0
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if _, err := r.client.ExecContext(ctx, q, u.Username, u.PasswordHash, u.BirthDate, u.Height, u.ID); err != nil {
+		if sqlite.UniqueViolation(err) {
+			return apperror.Conflict("username %q is already taken", u.Username)
+		}
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Delete помечает пользователя удалённым
+func (r *Repository) Delete(ctx context.Context, username string) error {
+	q := `
+		UPDATE users SET is_deleted = 1 WHERE username = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if _, err := r.client.ExecContext(ctx, q, username); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client sqlite.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/storagetest"
+	"fit-journal/internal/entities/user"
+	"fit-journal/pkg/client/sqlite"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunUsers(t, func(t *testing.T) storagetest.Repositories {
+		return storagetest.Repositories{Users: NewRepository(storagetest.SQLite(t))}
+	})
+}
+
+func TestUnitOfWorkRollback(t *testing.T) {
+	client := storagetest.SQLite(t)
+	unitOfWork := sqlite.NewUnitOfWork(client)
+	repo := NewRepository(sqlite.WithContextTx(client))
+	ctx := context.Background()
+
+	errAbort := errors.New("abort")
+	err := unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, user.User{Username: "rolled-back", PasswordHash: "hash"}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Do() error = %v, want %v", err, errAbort)
+	}
+	if _, err := repo.FindOne(ctx, "rolled-back"); !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("FindOne() after rollback error = %v, want apperror.ErrNotFound", err)
+	}
+
+	err = unitOfWork.Do(ctx, func(ctx context.Context) error {
+		return repo.Create(ctx, user.User{Username: "committed", PasswordHash: "hash"})
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if _, err := repo.FindOne(ctx, "committed"); err != nil {
+		t.Fatalf("FindOne() after commit error = %v", err)
+	}
+}
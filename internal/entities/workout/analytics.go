@@ -0,0 +1,114 @@
+package workout
+
+import (
+	"fit-journal/internal/entities/exercise"
+)
+
+// BlockSummary сводка по одному блоку тренировки: группе или отдельному упражнению
+type BlockSummary struct {
+	GroupID     int64              `json:"group_id,omitempty"`
+	Type        exercise.GroupType `json:"type,omitempty"` // Пусто для упражнения вне группы
+	ExerciseIDs []int64            `json:"exercise_ids"`
+	Sets        int                `json:"sets"`
+	Reps        int                `json:"reps"`
+	Volume      float64            `json:"volume"`            // Суммарный тоннаж: вес * повторы
+	Rounds      int                `json:"rounds"`            // Завершённые круги (для отдельного упражнения — подходы)
+	RestPeriods int                `json:"rest_periods"`      // Количество пауз на отдых внутри блока
+	RestSec     int                `json:"rest_sec"`          // Запланированное время отдыха внутри блока
+	Density     float64            `json:"density,omitempty"` // Тоннаж в минуту для блоков с лимитом времени
+}
+
+// Summary аналитика тренировки с учётом групп упражнений
+type Summary struct {
+	WorkoutID   int64          `json:"workout_id"`
+	Sets        int            `json:"sets"`
+	Reps        int            `json:"reps"`
+	Volume      float64        `json:"volume"`
+	RestPeriods int            `json:"rest_periods"`
+	RestSec     int            `json:"rest_sec"`
+	Blocks      []BlockSummary `json:"blocks"`
+}
+
+// Summarize считает аналитику по тренировке.
+// Упражнения внутри группы выполняются подряд, поэтому отдых считается между кругами, а не между подходами.
+// Упражнения и подходы из корзины не учитываются.
+func Summarize(w Workout) Summary {
+	w = w.WithoutTrashed()
+	s := Summary{WorkoutID: w.ID, Blocks: make([]BlockSummary, 0, len(w.Exercises))}
+
+	grouped := make(map[int64]struct{})
+	for _, g := range w.Groups {
+		block := BlockSummary{GroupID: g.ID, Type: g.Type, ExerciseIDs: g.ExerciseIDs}
+		rounds := -1
+		for _, id := range g.ExerciseIDs {
+			grouped[id] = struct{}{}
+			ex, ok := w.FindExercise(id)
+			if !ok {
+				rounds = 0
+				continue
+			}
+			addSets(&block, ex.Sets)
+			if rounds == -1 || len(ex.Sets) < rounds {
+				rounds = len(ex.Sets)
+			}
+		}
+		if rounds < 0 {
+			rounds = 0
+		}
+		if g.Type == exercise.GroupEMOM && g.Rounds > 0 && rounds > g.Rounds {
+			rounds = g.Rounds
+		}
+		block.Rounds = rounds
+
+		// В EMOM отдых — остаток каждого интервала, отдельных пауз между кругами нет
+		if g.Type != exercise.GroupEMOM && rounds > 1 {
+			block.RestPeriods = rounds - 1
+			block.RestSec = block.RestPeriods * g.RestSec
+		}
+
+		var minutes float64
+		switch g.Type {
+		case exercise.GroupAMRAP:
+			minutes = float64(g.TimeCapSec) / 60
+		case exercise.GroupEMOM:
+			minutes = float64(g.IntervalSec*g.Rounds) / 60
+		}
+		if minutes > 0 {
+			block.Density = block.Volume / minutes
+		}
+
+		s.add(block)
+	}
+
+	for _, ex := range w.Exercises {
+		if _, ok := grouped[ex.ID]; ok {
+			continue
+		}
+		block := BlockSummary{ExerciseIDs: []int64{ex.ID}}
+		addSets(&block, ex.Sets)
+		block.Rounds = len(ex.Sets)
+		if block.Rounds > 1 {
+			block.RestPeriods = block.Rounds - 1
+		}
+		s.add(block)
+	}
+
+	return s
+}
+
+func addSets(b *BlockSummary, sets []exercise.ExerciseSet) {
+	for _, set := range sets {
+		b.Sets++
+		b.Reps += set.Reps
+		b.Volume += float64(set.Reps) * set.Weight
+	}
+}
+
+func (s *Summary) add(b BlockSummary) {
+	s.Sets += b.Sets
+	s.Reps += b.Reps
+	s.Volume += b.Volume
+	s.RestPeriods += b.RestPeriods
+	s.RestSec += b.RestSec
+	s.Blocks = append(s.Blocks, b)
+}
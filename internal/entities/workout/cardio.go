@@ -0,0 +1,205 @@
+package workout
+
+import (
+	"fit-journal/internal/apperror"
+	"math"
+)
+
+// Kind вид тренировки
+type Kind string
+
+const (
+	KindStrength Kind = "strength" // Силовая тренировка: упражнения и подходы
+	KindCardio   Kind = "cardio"   // Кардио: дистанция, время, пульс
+)
+
+// ActivityType вид кардио-активности
+type ActivityType string
+
+const (
+	ActivityRun  ActivityType = "run"
+	ActivityRide ActivityType = "ride"
+	ActivityRow  ActivityType = "row"
+	ActivitySwim ActivityType = "swim"
+)
+
+// paceUnitMeters длина отрезка, на который принято считать темп для активности
+func (a ActivityType) paceUnitMeters() (float64, string) {
+	switch a {
+	case ActivitySwim:
+		return 100, "100m"
+	case ActivityRow:
+		return 500, "500m"
+	case ActivityRun:
+		return 1000, "km"
+	default:
+		// Для велосипеда темп не используется, ориентируются на скорость
+		return 0, ""
+	}
+}
+
+// SplitMeters длина отрезка для разбивки активности на сплиты
+func (a ActivityType) SplitMeters() float64 {
+	switch a {
+	case ActivitySwim:
+		return 100
+	case ActivityRow:
+		return 500
+	default:
+		return 1000
+	}
+}
+
+// HRSample замер пульса относительно начала активности
+type HRSample struct {
+	OffsetSec int64 `json:"offset_sec"`
+	BPM       int   `json:"bpm"`
+}
+
+// ZoneTime время, проведённое в пульсовой зоне
+type ZoneTime struct {
+	Zone    int   `json:"zone"`
+	MinBPM  int   `json:"min_bpm"`
+	MaxBPM  int   `json:"max_bpm"`
+	TimeSec int64 `json:"time_sec"`
+}
+
+// Split отрезок фиксированной длины внутри активности
+type Split struct {
+	Index          int     `json:"index"`
+	DistanceMeters float64 `json:"distance_meters"`
+	TimeSec        int64   `json:"time_sec"`
+	ElevationGainM float64 `json:"elevation_gain_m,omitempty"`
+	AvgHR          int     `json:"avg_hr,omitempty"`
+}
+
+// Cardio данные кардио-тренировки
+type Cardio struct {
+	Activity       ActivityType `json:"activity" validate:"required,oneof=run ride row swim"`
+	DistanceMeters float64      `json:"distance_meters" validate:"min=0"`
+	MovingTimeSec  int64        `json:"moving_time_sec" validate:"min=1"`
+	ElapsedTimeSec int64        `json:"elapsed_time_sec,omitempty" validate:"min=0"` // Общее время с учётом остановок
+	ElevationGainM float64      `json:"elevation_gain_m,omitempty" validate:"min=0"`
+	AvgHR          int          `json:"avg_hr,omitempty" validate:"min=0,max=250"`
+	MaxHR          int          `json:"max_hr,omitempty" validate:"min=0,max=250"`
+	HRSamples      []HRSample   `json:"hr_samples,omitempty"`
+	Splits         []Split      `json:"splits,omitempty"`
+
+	// Вычисляемые поля, заполняются в Compute
+	PaceSec  float64    `json:"pace_sec,omitempty"`  // Секунд на отрезок PaceUnit
+	PaceUnit string     `json:"pace_unit,omitempty"` // km, 100m или 500m в зависимости от активности
+	SpeedKmh float64    `json:"speed_kmh,omitempty"`
+	HRZones  []ZoneTime `json:"hr_zones,omitempty"`
+}
+
+// hrZoneBounds границы пяти пульсовых зон в долях от максимального пульса
+var hrZoneBounds = []float64{0.5, 0.6, 0.7, 0.8, 0.9, 1.0}
+
+// maxSampleGapSec промежуток между замерами, после которого считаем, что запись была на паузе
+const maxSampleGapSec = 60
+
+// Validate проверяет исходные данные кардио-тренировки; ошибки errors.Is apperror.ErrValidation
+func (c Cardio) Validate() error {
+	switch c.Activity {
+	case ActivityRun, ActivityRide, ActivityRow, ActivitySwim:
+	default:
+		return apperror.Validation("unknown activity %q", c.Activity)
+	}
+	if c.DistanceMeters < 0 || math.IsNaN(c.DistanceMeters) || math.IsInf(c.DistanceMeters, 0) {
+		return apperror.Validation("distance must be a non-negative number")
+	}
+	if c.MovingTimeSec <= 0 {
+		return apperror.Validation("moving time must be positive")
+	}
+	if c.ElapsedTimeSec < 0 {
+		return apperror.Validation("elapsed time must not be negative")
+	}
+	if math.IsNaN(c.ElevationGainM) || c.ElevationGainM < 0 {
+		return apperror.Validation("elevation gain must be a non-negative number")
+	}
+	if c.AvgHR < 0 || c.MaxHR < 0 || (c.MaxHR > 0 && c.AvgHR > c.MaxHR) {
+		return apperror.Validation("invalid heart rate values")
+	}
+	return nil
+}
+
+// Compute рассчитывает темп, скорость и время в пульсовых зонах.
+// age — возраст пользователя в годах; если он неизвестен (0), зоны не рассчитываются.
+func (c *Cardio) Compute(age int) {
+	c.PaceSec, c.PaceUnit, c.SpeedKmh, c.HRZones = 0, "", 0, nil
+
+	if c.DistanceMeters > 0 && c.MovingTimeSec > 0 {
+		c.SpeedKmh = round2(c.DistanceMeters / 1000 / (float64(c.MovingTimeSec) / 3600))
+		if unit, name := c.Activity.paceUnitMeters(); unit > 0 {
+			c.PaceSec = round2(float64(c.MovingTimeSec) / (c.DistanceMeters / unit))
+			c.PaceUnit = name
+		}
+	}
+
+	if len(c.HRSamples) > 0 {
+		var sum, maxBPM int
+		for _, s := range c.HRSamples {
+			sum += s.BPM
+			if s.BPM > maxBPM {
+				maxBPM = s.BPM
+			}
+		}
+		if c.AvgHR == 0 {
+			c.AvgHR = sum / len(c.HRSamples)
+		}
+		if c.MaxHR == 0 {
+			c.MaxHR = maxBPM
+		}
+	}
+
+	if age <= 0 {
+		return
+	}
+	c.HRZones = hrZones(220-age, c)
+}
+
+// hrZones распределяет время по пульсовым зонам.
+// При наличии замеров используются они, иначе всё время движения относится к зоне среднего пульса.
+func hrZones(maxHR int, c *Cardio) []ZoneTime {
+	zones := make([]ZoneTime, len(hrZoneBounds)-1)
+	for i := range zones {
+		zones[i] = ZoneTime{
+			Zone:   i + 1,
+			MinBPM: int(math.Round(hrZoneBounds[i] * float64(maxHR))),
+			MaxBPM: int(math.Round(hrZoneBounds[i+1] * float64(maxHR))),
+		}
+	}
+
+	zoneOf := func(bpm int) int {
+		for i := len(zones) - 1; i >= 0; i-- {
+			if bpm >= zones[i].MinBPM {
+				return i
+			}
+		}
+		return -1
+	}
+
+	if len(c.HRSamples) > 1 {
+		for i := 0; i < len(c.HRSamples)-1; i++ {
+			gap := c.HRSamples[i+1].OffsetSec - c.HRSamples[i].OffsetSec
+			if gap <= 0 || gap > maxSampleGapSec {
+				continue
+			}
+			if z := zoneOf(c.HRSamples[i].BPM); z >= 0 {
+				zones[z].TimeSec += gap
+			}
+		}
+		return zones
+	}
+
+	if c.AvgHR > 0 {
+		if z := zoneOf(c.AvgHR); z >= 0 {
+			zones[z].TimeSec = c.MovingTimeSec
+		}
+	}
+	return zones
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
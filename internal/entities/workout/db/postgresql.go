@@ -2,17 +2,19 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fit-journal/internal/apperror"
 	"fit-journal/internal/entities/workout"
 	"fit-journal/pkg/client/postgresql"
 	"fit-journal/pkg/logging"
 	"fmt"
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"strings"
 )
 
 type Repository struct {
 	client postgresql.Client
-	logger *logging.Logger
 }
 
 // formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
@@ -20,24 +22,32 @@ func formatQuery(q string) string {
 	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
 }
 
+// kindOf возвращает вид тренировки, по умолчанию силовая
+func kindOf(w workout.Workout) string {
+	if w.Kind == "" {
+		return string(workout.KindStrength)
+	}
+	return string(w.Kind)
+}
+
 // Create создает новую тренировку в БД
 func (r *Repository) Create(ctx context.Context, workout workout.Workout) (int64, error) {
 	var id int64
 	q := `
         INSERT INTO workouts
-            (user_id, start_time, exercises)
+            (user_id, start_time, kind, exercises, groups, cardio)
         VALUES
-            ($1, $2, $3)
+            ($1, $2, $3, $4, $5, $6)
         RETURNING id
     `
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", q))
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", q))
 
 	// Сканируем ID в переменную id
-	if err := r.client.QueryRow(ctx, q, workout.UserID, workout.StartTime, workout.Exercises).Scan(&id); err != nil {
+	if err := r.client.QueryRow(ctx, q, workout.UserID, workout.StartTime, kindOf(workout), workout.Exercises, workout.Groups, workout.Cardio).Scan(&id); err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
-			newErr := fmt.Errorf("SQL Error: %s, Detail: %s, Where: %s, Code: %s, SQLState: %s",
-				pgErr.Message, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
-			r.logger.Error(newErr)
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s",
+				pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
 			return 0, newErr
 		}
 		return 0, err
@@ -46,23 +56,18 @@ func (r *Repository) Create(ctx context.Context, workout workout.Workout) (int64
 	return id, nil
 }
 
-// FindAllByUserID возвращает список всех тренировок для конкретного пользователя
-func (r *Repository) FindAllByUserID(ctx context.Context, userID int64) ([]workout.Workout, error) {
-	q := `
-		SELECT id, user_id, start_time, exercises FROM workouts WHERE user_id = $1
-	`
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+// workoutColumns колонки, из которых собирается workout.Workout в scanWorkouts
+const workoutColumns = `id, user_id, start_time, kind, exercises, groups, cardio, deleted_at`
 
-	rows, err := r.client.Query(ctx, q, userID)
-	if err != nil {
-		return nil, err
-	}
+// scanWorkouts читает тренировки из результата запроса по workoutColumns
+func scanWorkouts(rows pgx.Rows) ([]workout.Workout, error) {
+	defer rows.Close()
 
 	workouts := make([]workout.Workout, 0)
 
 	for rows.Next() {
 		var w workout.Workout
-		if err := rows.Scan(&w.ID, &w.UserID, &w.StartTime, &w.Exercises); err != nil {
+		if err := rows.Scan(&w.ID, &w.UserID, &w.StartTime, &w.Kind, &w.Exercises, &w.Groups, &w.Cardio, &w.DeletedAt); err != nil {
 			return nil, err
 		}
 		workouts = append(workouts, w)
@@ -75,36 +80,113 @@ func (r *Repository) FindAllByUserID(ctx context.Context, userID int64) ([]worko
 	return workouts, nil
 }
 
-// FindOne ищет тренировку по ID
+// FindAllByUserID возвращает список всех тренировок пользователя, кроме находящихся в корзине
+func (r *Repository) FindAllByUserID(ctx context.Context, userID int64) ([]workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts WHERE user_id = $1 AND deleted_at IS NULL
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// FindTrashedByUserID возвращает тренировки пользователя, находящиеся в корзине
+func (r *Repository) FindTrashedByUserID(ctx context.Context, userID int64) ([]workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// FindAllWithTrashedItems возвращает активные тренировки, в которых есть упражнения или подходы в корзине
+func (r *Repository) FindAllWithTrashedItems(ctx context.Context) ([]workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts
+		WHERE deleted_at IS NULL
+		  AND (jsonb_path_exists(exercises, '$[*].deleted_at') OR jsonb_path_exists(exercises, '$[*].sets[*].deleted_at'))
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// FindOne ищет активную тренировку по ID
 func (r *Repository) FindOne(ctx context.Context, id int64) (workout.Workout, error) {
 	q := `
-		SELECT id, user_id, start_time, exercises FROM workouts WHERE id = $1
+		SELECT ` + workoutColumns + ` FROM workouts WHERE id = $1 AND deleted_at IS NULL
+	`
+	return r.findOne(ctx, q, id)
+}
+
+// FindOneTrashed ищет тренировку в корзине по ID
+func (r *Repository) FindOneTrashed(ctx context.Context, id int64) (workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts WHERE id = $1 AND deleted_at IS NOT NULL
 	`
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+	return r.findOne(ctx, q, id)
+}
+
+func (r *Repository) findOne(ctx context.Context, q string, id int64) (workout.Workout, error) {
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
 
 	var w workout.Workout
-	err := r.client.QueryRow(ctx, q, id).Scan(&w.ID, &w.UserID, &w.StartTime, &w.Exercises)
+	err := r.client.QueryRow(ctx, q, id).Scan(&w.ID, &w.UserID, &w.StartTime, &w.Kind, &w.Exercises, &w.Groups, &w.Cardio, &w.DeletedAt)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return workout.Workout{}, apperror.NotFound("workout %d not found", id)
+		}
 		return workout.Workout{}, err
 	}
 
 	return w, nil
 }
 
+// ExistsByStartTime проверяет, есть ли у пользователя тренировка с таким временем начала
+func (r *Repository) ExistsByStartTime(ctx context.Context, userID, startTime int64) (bool, error) {
+	q := `
+		SELECT EXISTS(SELECT 1 FROM workouts WHERE user_id = $1 AND start_time = $2 AND deleted_at IS NULL)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	var exists bool
+	if err := r.client.QueryRow(ctx, q, userID, startTime).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
 // Update обновляет информацию о тренировке
 func (r *Repository) Update(ctx context.Context, workout workout.Workout) error {
 	q := `
 		UPDATE workouts
-		SET user_id = $1, start_time = $2, exercises = $3
-		WHERE id = $4
+		SET user_id = $1, start_time = $2, kind = $3, exercises = $4, groups = $5, cardio = $6
+		WHERE id = $7
 	`
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
 
-	_, err := r.client.Exec(ctx, q, workout.UserID, workout.StartTime, workout.Exercises, workout.ID)
+	_, err := r.client.Exec(ctx, q, workout.UserID, workout.StartTime, kindOf(workout), workout.Exercises, workout.Groups, workout.Cardio, workout.ID)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
-			newErr := fmt.Errorf("SQL Error: %s, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr.Message, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
-			r.logger.Error(newErr)
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
 			return newErr
 		}
 		return err
@@ -113,19 +195,75 @@ func (r *Repository) Update(ctx context.Context, workout workout.Workout) error
 	return nil
 }
 
+// Trash перемещает тренировку в корзину
+func (r *Repository) Trash(ctx context.Context, id int64, deletedAt int64) error {
+	q := `
+		UPDATE workouts SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL
+	`
+	return r.setDeletedAt(ctx, q, deletedAt, id)
+}
+
+// Restore возвращает тренировку из корзины
+func (r *Repository) Restore(ctx context.Context, id int64) error {
+	q := `
+		UPDATE workouts SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NOT NULL
+	`
+	return r.setDeletedAt(ctx, q, nil, id)
+}
+
+// setDeletedAt выполняет Trash/Restore и возвращает apperror.ErrNotFound, если тренировка не найдена
+func (r *Repository) setDeletedAt(ctx context.Context, q string, deletedAt interface{}, id int64) error {
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	tag, err := r.client.Exec(ctx, q, deletedAt, id)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return newErr
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apperror.NotFound("workout %d not found", id)
+	}
+
+	return nil
+}
+
+// PurgeTrashed безвозвратно удаляет тренировки, перемещённые в корзину раньше before
+func (r *Repository) PurgeTrashed(ctx context.Context, before int64) (int64, error) {
+	q := `
+		DELETE FROM workouts WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	tag, err := r.client.Exec(ctx, q, before)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
+			return 0, newErr
+		}
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
 // Delete удаляет тренировку по ID
 func (r *Repository) Delete(ctx context.Context, id int64) error {
 	q := `
 		DELETE FROM workouts
 		WHERE id = $1
 	`
-	r.logger.Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
 
 	_, err := r.client.Exec(ctx, q, id)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
-			newErr := fmt.Errorf("SQL Error: %s, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr.Message, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
-			r.logger.Error(newErr)
+			newErr := fmt.Errorf("SQL Error: %w, Detail: %s, Where: %s, Code: %s, SQLState: %s", pgErr, pgErr.Detail, pgErr.Where, pgErr.Code, pgErr.SQLState())
+			logging.FromContext(ctx).Error(newErr)
 			return newErr
 		}
 		return err
@@ -135,9 +273,8 @@ func (r *Repository) Delete(ctx context.Context, id int64) error {
 }
 
 // NewRepository создает новый экземпляр репозитория
-func NewRepository(client postgresql.Client, logger *logging.Logger) *Repository {
+func NewRepository(client postgresql.Client) *Repository {
 	return &Repository{
 		client: client,
-		logger: logger,
 	}
 }
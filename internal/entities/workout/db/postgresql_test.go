@@ -0,0 +1,17 @@
+package db
+
+import (
+	"fit-journal/internal/entities/storagetest"
+	userDB "fit-journal/internal/entities/user/db"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunWorkouts(t, func(t *testing.T) storagetest.Repositories {
+		client := storagetest.Postgres(t)
+		return storagetest.Repositories{
+			Users:    userDB.NewRepository(client),
+			Workouts: NewRepository(client),
+		}
+	})
+}
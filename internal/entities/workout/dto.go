@@ -6,7 +6,9 @@ import (
 )
 
 type CreateWorkoutDTO struct {
-	User      user.User           `json:"user"`       // Пользователь, который выполняет тренировку
-	StartTime int64               `json:"start_time"` // Время начала тренировки (Unix timestamp)
-	Exercises []exercise.Exercise `json:"exercises"`  // Список упражнений в тренировке
+	User      user.User           `json:"user"`                                  // Пользователь, который выполняет тренировку
+	StartTime int64               `json:"start_time" validate:"min=0"`           // Время начала тренировки (Unix timestamp)
+	Kind      Kind                `json:"kind" validate:"oneof=strength cardio"` // Вид тренировки: strength (по умолчанию) или cardio
+	Exercises []exercise.Exercise `json:"exercises"`                             // Список упражнений в тренировке
+	Cardio    *Cardio             `json:"cardio"`                                // Данные кардио-активности
 }
@@ -0,0 +1,231 @@
+package workout
+
+import (
+	"context"
+	"encoding/json"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/internal/handlers"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"math/rand"
+	"net/http"
+)
+
+// checkGroupMembers проверяет, что все упражнения группы есть в тренировке и не входят в другие группы
+func checkGroupMembers(workout *Workout, group exercise.Group) error {
+	for _, id := range group.ExerciseIDs {
+		if _, ok := workout.FindExercise(id); !ok {
+			return apperror.NewAppError(nil, fmt.Sprintf("Упражнение %d не найдено в тренировке", id), "Ошибка поиска упражнения", http.StatusNotFound)
+		}
+		if other, ok := workout.GroupOf(id); ok && other.ID != group.ID {
+			return apperror.NewAppError(nil, fmt.Sprintf("Упражнение %d уже входит в группу %d", id, other.ID), "Упражнение может входить только в одну группу", http.StatusConflict)
+		}
+	}
+	return nil
+}
+
+// CreateGroup объединяет упражнения тренировки в суперсет, круг или блок EMOM/AMRAP
+func (h *handler) CreateGroup(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
+	}
+
+	var dto exercise.CreateGroupDTO
+	if err := handlers.Decode(r, &dto); err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка декодирования тела запроса: %v", err)
+		return apperror.NewAppError(err, "Неверный формат данных", "Ошибка декодирования JSON", http.StatusBadRequest)
+	}
+
+	group := exercise.Group{
+		ID:          rand.Int63(),
+		Type:        dto.Type,
+		Name:        dto.Name,
+		ExerciseIDs: dto.ExerciseIDs,
+		Rounds:      dto.Rounds,
+		TimeCapSec:  dto.TimeCapSec,
+		IntervalSec: dto.IntervalSec,
+		RestSec:     dto.RestSec,
+	}
+	if err := group.Validate(); err != nil {
+		return apperror.NewAppError(err, err.Error(), "Некорректные параметры группы", http.StatusBadRequest)
+	}
+
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
+
+		if err := checkGroupMembers(&workout, group); err != nil {
+			return err
+		}
+
+		workout.Groups = append(workout.Groups, group)
+
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при создании группы", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.record(r, audit.ActionUpdate, before, workout)
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(workout.WithoutTrashed()); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// UpdateGroup меняет параметры группы и порядок упражнений в ней. При смене типа параметры прежнего типа сбрасываются.
+func (h *handler) UpdateGroup(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
+	}
+	groupID, err := paramID(r, "group_id")
+	if err != nil {
+		return err
+	}
+
+	var dto exercise.UpdateGroupDTO
+	if err := handlers.Decode(r, &dto); err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка декодирования тела запроса: %v", err)
+		return apperror.NewAppError(err, "Неверный формат данных", "Ошибка декодирования JSON", http.StatusBadRequest)
+	}
+
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
+
+		idx := workout.FindGroup(groupID)
+		if idx == -1 {
+			return apperror.NewAppError(nil, "Группа не найдена", "Ошибка поиска группы", http.StatusNotFound)
+		}
+
+		// Обновляем только переданные поля
+		group := dto.Apply(workout.Groups[idx])
+
+		if err := group.Validate(); err != nil {
+			return apperror.NewAppError(err, err.Error(), "Некорректные параметры группы", http.StatusBadRequest)
+		}
+		if err := checkGroupMembers(&workout, group); err != nil {
+			return err
+		}
+
+		workout.Groups[idx] = group
+
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при обновлении группы", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.record(r, audit.ActionUpdate, before, workout)
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(workout.WithoutTrashed()); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// DeleteGroup расформировывает группу, упражнения остаются в тренировке
+func (h *handler) DeleteGroup(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
+	}
+	groupID, err := paramID(r, "group_id")
+	if err != nil {
+		return err
+	}
+
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
+
+		idx := workout.FindGroup(groupID)
+		if idx == -1 {
+			return apperror.NewAppError(nil, "Группа не найдена", "Ошибка поиска группы", http.StatusNotFound)
+		}
+		workout.Groups = append(workout.Groups[:idx], workout.Groups[idx+1:]...)
+
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при удалении группы", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.record(r, audit.ActionUpdate, before, workout)
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetWorkoutSummary возвращает аналитику тренировки с учётом групп упражнений
+func (h *handler) GetWorkoutSummary(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
+	}
+
+	workout, err := h.repository.FindOne(r.Context(), workoutID)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+		return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	}
+	if err := h.ownWorkout(r.Context(), workout); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(Summarize(workout)); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
+	}
+
+	return nil
+}
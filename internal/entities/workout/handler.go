@@ -1,14 +1,21 @@
 package workout
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
 	"fit-journal/internal/auth"
 	"fit-journal/internal/entities/exercise"
 	"fit-journal/internal/entities/user"
 	"fit-journal/internal/handlers"
 	"fit-journal/pkg/logging"
+	"fit-journal/pkg/metrics"
+	"fit-journal/pkg/uow"
+	"fmt"
 	"github.com/julienschmidt/httprouter"
+	"io"
 	"math/rand"
 	"net/http"
 	"strconv"
@@ -20,63 +27,135 @@ const (
 	workoutURL  = "/workouts/:workout_id"
 	exerciseURL = "/workouts/:workout_id/exercises/:exercise_id"
 	setURL      = "/workouts/:workout_id/exercises/:exercise_id/sets/:set_id"
+	groupsURL   = "/workouts/:workout_id/groups"
+	groupURL    = "/workouts/:workout_id/groups/:group_id"
+	summaryURL  = "/workouts/:workout_id/summary"
+	cardioURL   = "/workouts/:workout_id/cardio"
+	weeklyURL   = "/stats/weekly"
+
+	trashURL           = "/trash"
+	restoreWorkoutURL  = "/trash/workouts/:workout_id/restore"
+	restoreExerciseURL = "/trash/workouts/:workout_id/exercises/:exercise_id/restore"
+	restoreSetURL      = "/trash/workouts/:workout_id/exercises/:exercise_id/sets/:set_id/restore"
+)
+
+const (
+	defaultStatsWeeks = 12 // Глубина недельной статистики по умолчанию
+	maxStatsWeeks     = 52 // Наибольшая глубина недельной статистики
 )
 
 type handler struct {
-	logger         *logging.Logger
 	repository     Repository
 	userRepository user.Repository
+	// uow чтение и сохранение тренировки выполняются в одной единице работы,
+	// чтобы параллельные изменения той же тренировки не терялись
+	uow            uow.UnitOfWork
+	trashRetention time.Duration
+	audit          *audit.Recorder
+	auth           *auth.Service
 }
 
-func NewHandler(logger *logging.Logger, repo Repository, userRepo user.Repository) handlers.Handler {
+func NewHandler(repo Repository, userRepo user.Repository, unitOfWork uow.UnitOfWork, trashRetention time.Duration, recorder *audit.Recorder, authService *auth.Service) handlers.Handler {
 	return &handler{
-		logger:         logger,
 		repository:     repo,
 		userRepository: userRepo,
+		uow:            unitOfWork,
+		trashRetention: trashRetention,
+		audit:          recorder,
+		auth:           authService,
 	}
 }
 
+// record пишет изменение тренировки в журнал аудита. before снимок тренировки до изменения или nil.
+func (h *handler) record(r *http.Request, action audit.Action, before json.RawMessage, after Workout) {
+	h.audit.Record(r, action, audit.ResourceWorkout, after.ID, after.UserID, before, after)
+}
+
 func (h *handler) Register(router *httprouter.Router) {
-	router.HandlerFunc(http.MethodPost, workoutsURL, apperror.Middleware(auth.TokenAuthMiddleware(apperror.AppHandler(h.CreateWorkout))))
-	router.HandlerFunc(http.MethodPut, workoutURL, apperror.Middleware(auth.TokenAuthMiddleware(apperror.AppHandler(h.UpdateWorkout))))
-	router.HandlerFunc(http.MethodGet, workoutURL, apperror.Middleware(auth.TokenAuthMiddleware(apperror.AppHandler(h.GetWorkoutByID))))
-	router.HandlerFunc(http.MethodGet, workoutsURL, apperror.Middleware(auth.TokenAuthMiddleware(apperror.AppHandler(h.GetAllWorkouts))))
-	router.HandlerFunc(http.MethodPost, exerciseURL, apperror.Middleware(auth.TokenAuthMiddleware(apperror.AppHandler(h.AddSetToExercise))))
-	router.HandlerFunc(http.MethodDelete, workoutURL, apperror.Middleware(auth.TokenAuthMiddleware(apperror.AppHandler(h.DeleteWorkout))))
-	router.HandlerFunc(http.MethodDelete, exerciseURL, apperror.Middleware(auth.TokenAuthMiddleware(apperror.AppHandler(h.DeleteExercise))))
-	router.HandlerFunc(http.MethodDelete, setURL, apperror.Middleware(auth.TokenAuthMiddleware(apperror.AppHandler(h.DeleteSet))))
+	router.HandlerFunc(http.MethodPost, workoutsURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.CreateWorkout))))
+	router.HandlerFunc(http.MethodPut, workoutURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.UpdateWorkout))))
+	router.HandlerFunc(http.MethodGet, workoutURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.GetWorkoutByID))))
+	router.HandlerFunc(http.MethodGet, workoutsURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.GetAllWorkouts))))
+	router.HandlerFunc(http.MethodPost, exerciseURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.AddSetToExercise))))
+	router.HandlerFunc(http.MethodDelete, workoutURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.DeleteWorkout))))
+	router.HandlerFunc(http.MethodDelete, exerciseURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.DeleteExercise))))
+	router.HandlerFunc(http.MethodDelete, setURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.DeleteSet))))
+	router.HandlerFunc(http.MethodPost, groupsURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.CreateGroup))))
+	router.HandlerFunc(http.MethodPut, groupURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.UpdateGroup))))
+	router.HandlerFunc(http.MethodDelete, groupURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.DeleteGroup))))
+	router.HandlerFunc(http.MethodGet, summaryURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.GetWorkoutSummary))))
+	router.HandlerFunc(http.MethodPut, cardioURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.UpdateCardio))))
+	router.HandlerFunc(http.MethodGet, weeklyURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.GetWeeklyStats))))
+	router.HandlerFunc(http.MethodGet, trashURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.GetTrash))))
+	router.HandlerFunc(http.MethodPost, restoreWorkoutURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.RestoreWorkout))))
+	router.HandlerFunc(http.MethodPost, restoreExerciseURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.RestoreExercise))))
+	router.HandlerFunc(http.MethodPost, restoreSetURL, apperror.Middleware(h.auth.TokenAuthMiddleware(apperror.AppHandler(h.RestoreSet))))
 }
 
 func (h *handler) CreateWorkout(w http.ResponseWriter, r *http.Request) error {
 	// Извлечение username из контекста
 	username, ok := r.Context().Value("username").(string)
 	if !ok {
-		h.logger.Error("Ошибка извлечения username из контекста")
+		logging.FromContext(r.Context()).Error("Ошибка извлечения username из контекста")
 		return apperror.NewAppError(nil, "Ошибка аутентификации", "Не удалось получить пользователя", http.StatusUnauthorized)
 	}
 
 	// Получение user_id на основе username
 	user, err := h.userRepository.FindOne(r.Context(), username)
 	if err != nil {
-		h.logger.Error("Ошибка получения пользователя по username: %v", err)
+		logging.FromContext(r.Context()).Errorf("Ошибка получения пользователя по username: %v", err)
 		return apperror.NewAppError(err, "Ошибка при создании тренировки", "Ошибка получения пользователя", http.StatusInternalServerError)
 	}
+
+	// Тело запроса необязательно: без него создаётся пустая силовая тренировка
+	var dto CreateWorkoutDTO
+	if err := handlers.Decode(r, &dto); err != nil && !errors.Is(err, io.EOF) {
+		logging.FromContext(r.Context()).Errorf("Ошибка декодирования тела запроса: %v", err)
+		return apperror.NewAppError(err, "Неверный формат данных", "Ошибка декодирования JSON", http.StatusBadRequest)
+	}
+
 	// Создание новой тренировки с пустым списком упражнений и текущей датой
 	workout := Workout{
 		UserID:    user.ID,
 		StartTime: time.Now().Unix(),
+		Kind:      KindStrength,
 		Exercises: []exercise.Exercise{},
+		Groups:    []exercise.Group{},
+	}
+	if dto.StartTime > 0 {
+		workout.StartTime = dto.StartTime
+	}
+
+	switch dto.Kind {
+	case "", KindStrength:
+		if dto.Cardio != nil {
+			return apperror.NewAppError(nil, "Данные кардио допустимы только для тренировки вида cardio", "Неверный вид тренировки", http.StatusBadRequest)
+		}
+	case KindCardio:
+		if dto.Cardio == nil {
+			return apperror.NewAppError(nil, "Не переданы данные кардио-активности", "Поле cardio обязательно", http.StatusBadRequest)
+		}
+		if err := dto.Cardio.Validate(); err != nil {
+			return apperror.NewAppError(err, err.Error(), "Некорректные данные кардио-активности", http.StatusBadRequest)
+		}
+		workout.Kind = KindCardio
+		workout.Cardio = dto.Cardio
+		workout.Cardio.Compute(userAge(user))
+	default:
+		return apperror.NewAppError(nil, fmt.Sprintf("Неизвестный вид тренировки %q", dto.Kind), "Неверный вид тренировки", http.StatusBadRequest)
 	}
 
 	// Вызов репозитория для создания тренировки
 	id, err := h.repository.Create(r.Context(), workout)
 	if err != nil {
-		h.logger.Error("Ошибка создания тренировки: %v", err)
+		logging.FromContext(r.Context()).Errorf("Ошибка создания тренировки: %v", err)
 		return apperror.NewAppError(err, "Ошибка при создании тренировки", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
 	}
 
 	// Устанавливаем ID в workout
 	workout.ID = id
+	h.record(r, audit.ActionCreate, nil, workout)
+	metrics.WorkoutsCreated.WithLabelValues(metrics.SourceAPI).Inc()
 
 	// Ответ с созданной тренировкой
 	w.WriteHeader(http.StatusCreated)
@@ -89,45 +168,62 @@ func (h *handler) CreateWorkout(w http.ResponseWriter, r *http.Request) error {
 
 // UpdateWorkout обновляет существующую тренировку, добавляя новое упражнение
 func (h *handler) UpdateWorkout(w http.ResponseWriter, r *http.Request) error {
-	idStr := httprouter.ParamsFromContext(r.Context()).ByName("id")
-
-	// Преобразуем строковый ID в int64
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := paramID(r, "workout_id")
 	if err != nil {
-		h.logger.Error("Ошибка преобразования id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return err
 	}
 
 	// Декодируем данные нового упражнения
 	var newExercise exercise.Exercise
-	if err := json.NewDecoder(r.Body).Decode(&newExercise); err != nil {
-		h.logger.Error("Ошибка декодирования тела запроса: %v", err)
+	if err := handlers.Decode(r, &newExercise); err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка декодирования тела запроса: %v", err)
 		return apperror.NewAppError(err, "Неверный формат данных", "Ошибка декодирования JSON", http.StatusBadRequest)
 	}
 
 	// Получаем текущую тренировку
-	ctx := r.Context()
-	workout, err := h.repository.FindOne(ctx, id)
-	if err != nil {
-		h.logger.Error("Ошибка получения тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при добавлении упражнения", "Тренировка не найдена", http.StatusInternalServerError)
-	}
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, id)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при добавлении упражнения", "Тренировка не найдена", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
 
-	// Генерируем уникальный int64 ID для нового упражнения
-	newExercise.ID = rand.Int63()
+		if workout.Kind == KindCardio {
+			return apperror.NewAppError(nil, "В кардио-тренировку нельзя добавить упражнение", "Упражнения допустимы только в силовой тренировке", http.StatusConflict)
+		}
+
+		// Генерируем уникальный int64 ID для нового упражнения
+		newExercise.ID = rand.Int63()
+		newExercise.DeletedAt = nil
+		for i := range newExercise.Sets {
+			newExercise.Sets[i].DeletedAt = nil
+		}
 
-	// Добавляем новое упражнение в массив упражнений
-	workout.Exercises = append(workout.Exercises, newExercise)
+		// Добавляем новое упражнение в массив упражнений
+		workout.Exercises = append(workout.Exercises, newExercise)
 
-	// Обновляем тренировку в базе данных
-	if err := h.repository.Update(ctx, workout); err != nil {
-		h.logger.Error("Ошибка обновления тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при добавлении упражнения", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		// Обновляем тренировку в базе данных
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при добавлении упражнения", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	h.record(r, audit.ActionUpdate, before, workout)
 
 	// Возвращаем обновленную тренировку
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(workout); err != nil {
+	if err := json.NewEncoder(w).Encode(workout.WithoutTrashed()); err != nil {
 		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
 	}
 
@@ -136,21 +232,32 @@ func (h *handler) UpdateWorkout(w http.ResponseWriter, r *http.Request) error {
 
 // GetWorkoutByID получает тренировку по ID
 func (h *handler) GetWorkoutByID(w http.ResponseWriter, r *http.Request) error {
-	idStr := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	id, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
+	}
 
-	// Преобразуем строковый ID в int64
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	// С include_trashed=true отдаём и тренировку из корзины, и удалённые упражнения и подходы
+	withTrashed, err := includeTrashed(r)
 	if err != nil {
-		h.logger.Error("Ошибка преобразования id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return err
 	}
 
 	// Получаем текущую тренировку
 	ctx := r.Context()
 	workout, err := h.repository.FindOne(ctx, id)
+	if errors.Is(err, apperror.ErrNotFound) && withTrashed {
+		workout, err = h.repository.FindOneTrashed(ctx, id)
+	}
 	if err != nil {
-		h.logger.Error("Ошибка получения тренировки: %v", err)
-		return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusNotFound)
+		logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+		return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	}
+	if err := h.ownWorkout(ctx, workout); err != nil {
+		return err
+	}
+	if !withTrashed {
+		workout = workout.WithoutTrashed()
 	}
 
 	// Возвращаем тренировку в ответ
@@ -166,24 +273,43 @@ func (h *handler) GetAllWorkouts(w http.ResponseWriter, r *http.Request) error {
 	// Получаем username из контекста
 	username, ok := r.Context().Value("username").(string)
 	if !ok {
-		h.logger.Error("Ошибка извлечения username из контекста")
+		logging.FromContext(r.Context()).Error("Ошибка извлечения username из контекста")
 		return apperror.NewAppError(nil, "Не удалось получить данные пользователя", "Ошибка контекста", http.StatusInternalServerError)
 	}
 
 	// Ищем user_id по username
 	user, err := h.userRepository.FindOne(r.Context(), username)
 	if err != nil {
-		h.logger.Error("Ошибка получения пользователя по username: %v", err)
+		logging.FromContext(r.Context()).Errorf("Ошибка получения пользователя по username: %v", err)
 		return apperror.NewAppError(err, "Ошибка при получении тренировок", "Ошибка получения пользователя", http.StatusInternalServerError)
 	}
 
+	withTrashed, err := includeTrashed(r)
+	if err != nil {
+		return err
+	}
+
 	// Ищем все тренировки для найденного пользователя
 	workouts, err := h.repository.FindAllByUserID(r.Context(), user.ID)
 	if err != nil {
-		h.logger.Error("Ошибка получения тренировок: %v", err)
+		logging.FromContext(r.Context()).Errorf("Ошибка получения тренировок: %v", err)
 		return apperror.NewAppError(err, "Ошибка при получении тренировок", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
 	}
 
+	// По умолчанию содержимое корзины не показывается
+	if withTrashed {
+		trashed, err := h.repository.FindTrashedByUserID(r.Context(), user.ID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировок из корзины: %v", err)
+			return apperror.NewAppError(err, "Ошибка при получении тренировок", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		workouts = append(workouts, trashed...)
+	} else {
+		for i := range workouts {
+			workouts[i] = workouts[i].WithoutTrashed()
+		}
+	}
+
 	// Отправляем ответ
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(workouts); err != nil {
@@ -195,202 +321,359 @@ func (h *handler) GetAllWorkouts(w http.ResponseWriter, r *http.Request) error {
 
 // AddSetToExercise добавляет новый подход к упражнению в тренировке
 func (h *handler) AddSetToExercise(w http.ResponseWriter, r *http.Request) error {
-	// Получаем ID тренировки и ID упражнения из параметров URL
-	workoutIDStr := httprouter.ParamsFromContext(r.Context()).ByName("workout_id")
-	exerciseIDStr := httprouter.ParamsFromContext(r.Context()).ByName("exercise_id")
-
-	// Преобразуем строковые ID в int64
-	workoutID, err := strconv.ParseInt(workoutIDStr, 10, 64)
+	workoutID, err := paramID(r, "workout_id")
 	if err != nil {
-		h.logger.Error("Ошибка преобразования workout_id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат workout_id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return err
 	}
-	exerciseID, err := strconv.ParseInt(exerciseIDStr, 10, 64)
+	exerciseID, err := paramID(r, "exercise_id")
 	if err != nil {
-		h.logger.Error("Ошибка преобразования exercise_id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат exercise_id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return err
 	}
 
 	// Декодируем новый подход (с весом и повторами)
 	var newSet exercise.ExerciseSet
-	if err := json.NewDecoder(r.Body).Decode(&newSet); err != nil {
-		h.logger.Error("Ошибка декодирования тела запроса: %v", err)
+	if err := handlers.Decode(r, &newSet); err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка декодирования тела запроса: %v", err)
 		return apperror.NewAppError(err, "Неверный формат данных", "Ошибка декодирования JSON", http.StatusBadRequest)
 	}
 
 	// Присваиваем уникальный ID для нового подхода
 	newSet.ID = rand.Int63()
+	newSet.DeletedAt = nil
 
 	// Получаем текущую тренировку
-	ctx := r.Context()
-	workout, err := h.repository.FindOne(ctx, workoutID)
-	if err != nil {
-		h.logger.Error("Ошибка получения тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при добавлении подхода", "Тренировка не найдена", http.StatusInternalServerError)
-	}
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при добавлении подхода", "Тренировка не найдена", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
 
-	// Ищем упражнение по его ID в тренировке
-	foundExercise := false
-	for i := range workout.Exercises {
-		if workout.Exercises[i].ID == exerciseID {
-			// Добавляем новый подход к упражнению
-			workout.Exercises[i].Sets = append(workout.Exercises[i].Sets, newSet)
-			foundExercise = true
-			break
+		// Ищем упражнение по его ID в тренировке, упражнения из корзины не изменяются
+		ex, foundExercise := workout.FindExercise(exerciseID)
+		if !foundExercise {
+			logging.FromContext(r.Context()).Error("Упражнение не найдено в тренировке")
+			return apperror.NewAppError(nil, "Упражнение не найдено", "Ошибка поиска упражнения в тренировке", http.StatusNotFound)
 		}
-	}
 
-	// Если упражнение не найдено, возвращаем ошибку
-	if !foundExercise {
-		h.logger.Error("Упражнение не найдено в тренировке")
-		return apperror.NewAppError(nil, "Упражнение не найдено", "Ошибка поиска упражнения в тренировке", http.StatusNotFound)
-	}
+		// Добавляем новый подход к упражнению
+		ex.Sets = append(ex.Sets, newSet)
 
-	// Обновляем тренировку в базе данных
-	if err := h.repository.Update(ctx, workout); err != nil {
-		h.logger.Error("Ошибка обновления тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при добавлении подхода", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		// Обновляем тренировку в базе данных
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при добавлении подхода", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	h.record(r, audit.ActionUpdate, before, workout)
+	metrics.SetsLogged.Inc()
 
 	// Возвращаем обновленную тренировку
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(workout); err != nil {
+	if err := json.NewEncoder(w).Encode(workout.WithoutTrashed()); err != nil {
 		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
 	}
 
 	return nil
 }
 
-// DeleteWorkout удаляет тренировку по её ID
+// DeleteWorkout перемещает тренировку в корзину
 func (h *handler) DeleteWorkout(w http.ResponseWriter, r *http.Request) error {
-	idStr := httprouter.ParamsFromContext(r.Context()).ByName("workout_id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := paramID(r, "workout_id")
 	if err != nil {
-		h.logger.Error("Ошибка преобразования id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат id", "Ошибка преобразования ID", http.StatusBadRequest)
-	}
+		return err
+	}
+
+	var workout Workout
+	var before json.RawMessage
+	deletedAt := time.Now().Unix()
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, id)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при удалении тренировки", "Тренировка не найдена", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
 
-	// Удаление тренировки
-	if err := h.repository.Delete(r.Context(), id); err != nil {
-		h.logger.Error("Ошибка удаления тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при удалении тренировки", "Тренировка не найдена", http.StatusNotFound)
+		// Тренировка остаётся в корзине до восстановления или очистки по истечении срока хранения
+		if err := h.repository.Trash(ctx, id, deletedAt); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка удаления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при удалении тренировки", "Тренировка не найдена", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	workout.DeletedAt = &deletedAt
+	h.record(r, audit.ActionDelete, before, workout)
 
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
-// DeleteExercise удаляет упражнение из тренировки по ID
+// DeleteExercise перемещает упражнение тренировки в корзину. Членство в группах сохраняется до очистки корзины.
 func (h *handler) DeleteExercise(w http.ResponseWriter, r *http.Request) error {
-	workoutIDStr := httprouter.ParamsFromContext(r.Context()).ByName("workout_id")
-	exerciseIDStr := httprouter.ParamsFromContext(r.Context()).ByName("exercise_id")
-	workoutID, err := strconv.ParseInt(workoutIDStr, 10, 64)
+	workoutID, err := paramID(r, "workout_id")
 	if err != nil {
-		h.logger.Error("Ошибка преобразования workout_id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат workout_id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return err
 	}
-	exerciseID, err := strconv.ParseInt(exerciseIDStr, 10, 64)
+	exerciseID, err := paramID(r, "exercise_id")
 	if err != nil {
-		h.logger.Error("Ошибка преобразования exercise_id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат exercise_id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return err
 	}
 
 	// Получаем текущую тренировку
-	ctx := r.Context()
-	workout, err := h.repository.FindOne(ctx, workoutID)
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при удалении упражнения", "Тренировка не найдена", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
+
+		// Перемещаем упражнение в корзину
+		ex, found := workout.FindExercise(exerciseID)
+		if !found {
+			return apperror.NewAppError(nil, "Упражнение не найдено", "Ошибка поиска упражнения", http.StatusNotFound)
+		}
+		deletedAt := time.Now().Unix()
+		ex.DeletedAt = &deletedAt
+
+		// Обновляем тренировку в базе данных
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при удалении упражнения", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
 	if err != nil {
-		h.logger.Error("Ошибка получения тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при удалении упражнения", "Тренировка не найдена", http.StatusInternalServerError)
+		return err
 	}
+	h.record(r, audit.ActionDelete, before, workout)
 
-	// Удаляем упражнение
-	var updatedExercises []exercise.Exercise
-	found := false
-	for _, ex := range workout.Exercises {
-		if ex.ID != exerciseID {
-			updatedExercises = append(updatedExercises, ex)
-		} else {
-			found = true
-		}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// DeleteSet перемещает подход упражнения в корзину
+func (h *handler) DeleteSet(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
 	}
-	if !found {
-		return apperror.NewAppError(nil, "Упражнение не найдено", "Ошибка поиска упражнения", http.StatusNotFound)
+	exerciseID, err := paramID(r, "exercise_id")
+	if err != nil {
+		return err
+	}
+	setID, err := paramID(r, "set_id")
+	if err != nil {
+		return err
 	}
-	workout.Exercises = updatedExercises
 
-	// Обновляем тренировку в базе данных
-	if err := h.repository.Update(ctx, workout); err != nil {
-		h.logger.Error("Ошибка обновления тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при удалении упражнения", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	// Получаем текущую тренировку
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при удалении подхода", "Тренировка не найдена", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
+
+		// Находим упражнение
+		ex, foundExercise := workout.FindExercise(exerciseID)
+		if !foundExercise {
+			return apperror.NewAppError(nil, "Упражнение не найдено", "Ошибка поиска упражнения", http.StatusNotFound)
+		}
+
+		// Перемещаем подход в корзину
+		foundSet := false
+		for i := range ex.Sets {
+			if ex.Sets[i].ID == setID && ex.Sets[i].DeletedAt == nil {
+				deletedAt := time.Now().Unix()
+				ex.Sets[i].DeletedAt = &deletedAt
+				foundSet = true
+				break
+			}
+		}
+		if !foundSet {
+			return apperror.NewAppError(nil, "Подход не найден", "Ошибка поиска подхода", http.StatusNotFound)
+		}
+
+		// Обновляем тренировку в базе данных
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при удалении подхода", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	h.record(r, audit.ActionDelete, before, workout)
 
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
-// DeleteSet удаляет подход из упражнения
-func (h *handler) DeleteSet(w http.ResponseWriter, r *http.Request) error {
-	workoutIDStr := httprouter.ParamsFromContext(r.Context()).ByName("workout_id")
-	exerciseIDStr := httprouter.ParamsFromContext(r.Context()).ByName("exercise_id")
-	setIDStr := httprouter.ParamsFromContext(r.Context()).ByName("set_id")
-
-	workoutID, err := strconv.ParseInt(workoutIDStr, 10, 64)
+// paramID извлекает числовой параметр маршрута
+func paramID(r *http.Request, name string) (int64, error) {
+	value := httprouter.ParamsFromContext(r.Context()).ByName(name)
+	id, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		h.logger.Error("Ошибка преобразования workout_id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат workout_id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return 0, apperror.NewAppError(err, fmt.Sprintf("Неверный формат %s", name), "Ошибка преобразования ID", http.StatusBadRequest)
 	}
-	exerciseID, err := strconv.ParseInt(exerciseIDStr, 10, 64)
+	return id, nil
+}
+
+// userAge возвращает возраст пользователя или 0, если дата рождения не указана
+func userAge(u user.User) int {
+	age, err := u.Age(time.Now())
 	if err != nil {
-		h.logger.Error("Ошибка преобразования exercise_id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат exercise_id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return 0
 	}
-	setID, err := strconv.ParseInt(setIDStr, 10, 64)
+	return age
+}
+
+// UpdateCardio заменяет данные кардио-активности тренировки и пересчитывает темп и пульсовые зоны
+func (h *handler) UpdateCardio(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
 	if err != nil {
-		h.logger.Error("Ошибка преобразования set_id: %v", err)
-		return apperror.NewAppError(err, "Неверный формат set_id", "Ошибка преобразования ID", http.StatusBadRequest)
+		return err
+	}
+
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		logging.FromContext(r.Context()).Error("Ошибка извлечения username из контекста")
+		return apperror.NewAppError(nil, "Ошибка аутентификации", "Не удалось получить пользователя", http.StatusUnauthorized)
+	}
+
+	var cardio Cardio
+	if err := handlers.Decode(r, &cardio); err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка декодирования тела запроса: %v", err)
+		return apperror.NewAppError(err, "Неверный формат данных", "Ошибка декодирования JSON", http.StatusBadRequest)
+	}
+	if err := cardio.Validate(); err != nil {
+		return apperror.NewAppError(err, err.Error(), "Некорректные данные кардио-активности", http.StatusBadRequest)
 	}
 
-	// Получаем текущую тренировку
 	ctx := r.Context()
-	workout, err := h.repository.FindOne(ctx, workoutID)
+	user, err := h.userRepository.FindOne(ctx, username)
 	if err != nil {
-		h.logger.Error("Ошибка получения тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при удалении подхода", "Тренировка не найдена", http.StatusInternalServerError)
-	}
-
-	// Находим упражнение
-	foundExercise := false
-	for i := range workout.Exercises {
-		if workout.Exercises[i].ID == exerciseID {
-			// Удаляем подход
-			var updatedSets []exercise.ExerciseSet
-			foundSet := false
-			for _, set := range workout.Exercises[i].Sets {
-				if set.ID != setID {
-					updatedSets = append(updatedSets, set)
-				} else {
-					foundSet = true
-				}
-			}
-			if !foundSet {
-				return apperror.NewAppError(nil, "Подход не найден", "Ошибка поиска подхода", http.StatusNotFound)
-			}
-			workout.Exercises[i].Sets = updatedSets
-			foundExercise = true
-			break
+		logging.FromContext(r.Context()).Errorf("Ошибка получения пользователя по username: %v", err)
+		return apperror.NewAppError(err, "Ошибка при обновлении тренировки", "Ошибка получения пользователя", http.StatusInternalServerError)
+	}
+
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		// Зоны считаются по возрасту пользователя, поэтому менять можно только свою тренировку
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
 		}
+		before = audit.Snapshot(workout)
+
+		// Кардио-тренировка не содержит силовых упражнений
+		if workout.HasActiveExercises() {
+			return apperror.NewAppError(nil, "Тренировка с упражнениями не может стать кардио-тренировкой", "Сначала удалите упражнения из тренировки", http.StatusConflict)
+		}
+
+		cardio.Compute(userAge(user))
+		workout.Kind = KindCardio
+		workout.Cardio = &cardio
+
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при обновлении тренировки", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	h.record(r, audit.ActionUpdate, before, workout)
 
-	if !foundExercise {
-		return apperror.NewAppError(nil, "Упражнение не найдено", "Ошибка поиска упражнения", http.StatusNotFound)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(workout.WithoutTrashed()); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
 	}
 
-	// Обновляем тренировку в базе данных
-	if err := h.repository.Update(ctx, workout); err != nil {
-		h.logger.Error("Ошибка обновления тренировки: %v", err)
-		return apperror.NewAppError(err, "Ошибка при удалении подхода", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	return nil
+}
+
+// GetWeeklyStats возвращает недельные итоги силовых и кардио-тренировок пользователя.
+// Параметр weeks задаёт глубину выборки: от 1 до 52 недель, по умолчанию 12.
+func (h *handler) GetWeeklyStats(w http.ResponseWriter, r *http.Request) error {
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		logging.FromContext(r.Context()).Error("Ошибка извлечения username из контекста")
+		return apperror.NewAppError(nil, "Не удалось получить данные пользователя", "Ошибка контекста", http.StatusInternalServerError)
+	}
+
+	weeks := defaultStatsWeeks
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxStatsWeeks {
+			violation := apperror.ValidationErrors{{Field: "weeks", Message: fmt.Sprintf("must be an integer between 1 and %d", maxStatsWeeks)}}
+			return apperror.NewAppError(violation, "Неверный параметр weeks", fmt.Sprintf("Ожидается число от 1 до %d", maxStatsWeeks), http.StatusBadRequest)
+		}
+		weeks = n
+	}
+
+	user, err := h.userRepository.FindOne(r.Context(), username)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения пользователя по username: %v", err)
+		return apperror.NewAppError(err, "Ошибка при получении статистики", "Ошибка получения пользователя", http.StatusInternalServerError)
+	}
+
+	workouts, err := h.repository.FindAllByUserID(r.Context(), user.ID)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения тренировок: %v", err)
+		return apperror.NewAppError(err, "Ошибка при получении статистики", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	}
+
+	now := time.Now()
+	monday := now.AddDate(0, 0, -((int(now.Weekday())+6)%7)-7*(weeks-1))
+	since := time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, now.Location())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(WeeklyTotals(workouts, since, now.Location())); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
 	}
 
-	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
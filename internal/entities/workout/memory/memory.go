@@ -0,0 +1,233 @@
+// Package memory хранит тренировки в памяти процесса: для тестов и режима --storage=memory.
+// Поведение повторяет репозиторий PostgreSQL, см. storagetest.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/workout"
+	"sort"
+	"sync"
+)
+
+type Repository struct {
+	mu       sync.RWMutex
+	workouts map[int64]workout.Workout
+	nextID   int64
+}
+
+// NewRepository создает пустой репозиторий
+func NewRepository() *Repository {
+	return &Repository{workouts: make(map[int64]workout.Workout)}
+}
+
+// clone глубокая копия тренировки. Упражнения, группы и кардио копируются через JSON,
+// как при хранении в JSONB, чтобы вызывающий код не мог изменить сохранённые данные.
+func clone(w workout.Workout) workout.Workout {
+	c := w
+	c.Exercises, c.Groups, c.Cardio = nil, nil, nil
+	copyJSON(w.Exercises, &c.Exercises)
+	copyJSON(w.Groups, &c.Groups)
+	copyJSON(w.Cardio, &c.Cardio)
+	if w.DeletedAt != nil {
+		deletedAt := *w.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	return c
+}
+
+func copyJSON(src, dst interface{}) {
+	data, err := json.Marshal(src)
+	if err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		panic(err)
+	}
+}
+
+// Create создает новую тренировку, вид по умолчанию силовой
+func (r *Repository) Create(_ context.Context, w workout.Workout) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	w = clone(w)
+	w.ID, w.DeletedAt = r.nextID, nil
+	if w.Kind == "" {
+		w.Kind = workout.KindStrength
+	}
+	r.workouts[w.ID] = w
+	return w.ID, nil
+}
+
+// find возвращает копии тренировок, подходящих под условие, по возрастанию ID
+func (r *Repository) find(match func(w workout.Workout) bool) []workout.Workout {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workouts := make([]workout.Workout, 0)
+	for _, w := range r.workouts {
+		if match(w) {
+			workouts = append(workouts, clone(w))
+		}
+	}
+	sort.Slice(workouts, func(i, j int) bool { return workouts[i].ID < workouts[j].ID })
+	return workouts
+}
+
+// FindAllByUserID возвращает список всех тренировок пользователя, кроме находящихся в корзине
+func (r *Repository) FindAllByUserID(_ context.Context, userID int64) ([]workout.Workout, error) {
+	return r.find(func(w workout.Workout) bool {
+		return w.UserID == userID && w.DeletedAt == nil
+	}), nil
+}
+
+// FindTrashedByUserID возвращает тренировки пользователя, находящиеся в корзине, недавно удалённые первыми
+func (r *Repository) FindTrashedByUserID(_ context.Context, userID int64) ([]workout.Workout, error) {
+	workouts := r.find(func(w workout.Workout) bool {
+		return w.UserID == userID && w.DeletedAt != nil
+	})
+	sort.SliceStable(workouts, func(i, j int) bool { return *workouts[i].DeletedAt > *workouts[j].DeletedAt })
+	return workouts, nil
+}
+
+// FindAllWithTrashedItems возвращает активные тренировки, в которых есть упражнения или подходы в корзине
+func (r *Repository) FindAllWithTrashedItems(_ context.Context) ([]workout.Workout, error) {
+	return r.find(func(w workout.Workout) bool {
+		if w.DeletedAt != nil {
+			return false
+		}
+		for _, ex := range w.Exercises {
+			if ex.DeletedAt != nil {
+				return true
+			}
+			for _, s := range ex.Sets {
+				if s.DeletedAt != nil {
+					return true
+				}
+			}
+		}
+		return false
+	}), nil
+}
+
+// FindOne ищет активную тренировку по ID
+func (r *Repository) FindOne(_ context.Context, id int64) (workout.Workout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.workouts[id]
+	if !ok || w.DeletedAt != nil {
+		return workout.Workout{}, apperror.NotFound("workout %d not found", id)
+	}
+	return clone(w), nil
+}
+
+// FindOneTrashed ищет тренировку в корзине по ID
+func (r *Repository) FindOneTrashed(_ context.Context, id int64) (workout.Workout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.workouts[id]
+	if !ok || w.DeletedAt == nil {
+		return workout.Workout{}, apperror.NotFound("workout %d not found", id)
+	}
+	return clone(w), nil
+}
+
+// ExistsByStartTime проверяет, есть ли у пользователя тренировка с таким временем начала
+func (r *Repository) ExistsByStartTime(_ context.Context, userID, startTime int64) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, w := range r.workouts {
+		if w.UserID == userID && w.StartTime == startTime && w.DeletedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Update обновляет информацию о тренировке. Время перемещения в корзину не меняется.
+func (r *Repository) Update(_ context.Context, w workout.Workout) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.workouts[w.ID]
+	if !ok {
+		return nil
+	}
+	w = clone(w)
+	w.DeletedAt = existing.DeletedAt
+	if w.Kind == "" {
+		w.Kind = workout.KindStrength
+	}
+	r.workouts[w.ID] = w
+	return nil
+}
+
+// Trash перемещает тренировку в корзину
+func (r *Repository) Trash(_ context.Context, id int64, deletedAt int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.workouts[id]
+	if !ok || w.DeletedAt != nil {
+		return apperror.NotFound("workout %d not found", id)
+	}
+	w.DeletedAt = &deletedAt
+	r.workouts[id] = w
+	return nil
+}
+
+// Restore возвращает тренировку из корзины
+func (r *Repository) Restore(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.workouts[id]
+	if !ok || w.DeletedAt == nil {
+		return apperror.NotFound("workout %d not found", id)
+	}
+	w.DeletedAt = nil
+	r.workouts[id] = w
+	return nil
+}
+
+// PurgeTrashed безвозвратно удаляет тренировки, перемещённые в корзину раньше before
+func (r *Repository) PurgeTrashed(_ context.Context, before int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, w := range r.workouts {
+		if w.DeletedAt != nil && *w.DeletedAt < before {
+			delete(r.workouts, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Delete удаляет тренировку по ID
+func (r *Repository) Delete(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.workouts, id)
+	return nil
+}
+
+// DeleteByUserID удаляет все тренировки пользователя, включая находящиеся в корзине
+func (r *Repository) DeleteByUserID(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, w := range r.workouts {
+		if w.UserID == userID {
+			delete(r.workouts, id)
+		}
+	}
+}
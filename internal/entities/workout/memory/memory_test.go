@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"fit-journal/internal/entities/storagetest"
+	userMemory "fit-journal/internal/entities/user/memory"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunWorkouts(t, func(t *testing.T) storagetest.Repositories {
+		return storagetest.Repositories{Users: userMemory.NewRepository(), Workouts: NewRepository()}
+	})
+}
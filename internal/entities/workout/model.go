@@ -8,5 +8,68 @@ type Workout struct {
 	ID        int64               `json:"id"`
 	UserID    int64               `json:"user_id"`
 	StartTime int64               `json:"start_time"`
+	Kind      Kind                `json:"kind"`
 	Exercises []exercise.Exercise `json:"exercises"`
+	Groups    []exercise.Group    `json:"groups"`               // Суперсеты, круги и прочие блоки упражнений
+	Cardio    *Cardio             `json:"cardio,omitempty"`     // Заполняется для кардио-тренировок
+	DeletedAt *int64              `json:"deleted_at,omitempty"` // Время перемещения в корзину (Unix), nil для активных
+}
+
+// FindExercise возвращает активное (не находящееся в корзине) упражнение тренировки по ID
+func (w *Workout) FindExercise(id int64) (*exercise.Exercise, bool) {
+	for i := range w.Exercises {
+		if w.Exercises[i].ID == id && w.Exercises[i].DeletedAt == nil {
+			return &w.Exercises[i], true
+		}
+	}
+	return nil, false
+}
+
+// HasActiveExercises проверяет, есть ли в тренировке упражнения вне корзины
+func (w *Workout) HasActiveExercises() bool {
+	for _, ex := range w.Exercises {
+		if ex.DeletedAt == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FindGroup возвращает индекс группы по ID или -1
+func (w *Workout) FindGroup(id int64) int {
+	for i := range w.Groups {
+		if w.Groups[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// GroupOf возвращает группу, в которую входит упражнение
+func (w *Workout) GroupOf(exerciseID int64) (exercise.Group, bool) {
+	for _, g := range w.Groups {
+		if g.Contains(exerciseID) {
+			return g, true
+		}
+	}
+	return exercise.Group{}, false
+}
+
+// RemoveExerciseFromGroups убирает упражнение из всех групп и удаляет группы, ставшие невалидными
+func (w *Workout) RemoveExerciseFromGroups(exerciseID int64) {
+	groups := make([]exercise.Group, 0, len(w.Groups))
+	for _, g := range w.Groups {
+		ids := make([]int64, 0, len(g.ExerciseIDs))
+		for _, id := range g.ExerciseIDs {
+			if id != exerciseID {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) < g.Type.MinExercises() {
+			continue
+		}
+		g.ExerciseIDs = ids
+		groups = append(groups, g)
+	}
+	w.Groups = groups
 }
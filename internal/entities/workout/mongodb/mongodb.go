@@ -0,0 +1,219 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/pkg/client/mongodb"
+	"fit-journal/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Repository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// document тренировка в коллекции workouts. Упражнения, группы и кардио хранятся
+// с именами полей из тегов json; deleted_at равен null у активных тренировок.
+type document struct {
+	ID        int64               `bson:"_id"`
+	UserID    int64               `bson:"user_id"`
+	StartTime int64               `bson:"start_time"`
+	Kind      workout.Kind        `bson:"kind"`
+	Exercises []exercise.Exercise `bson:"exercises"`
+	Groups    []exercise.Group    `bson:"groups"`
+	Cardio    *workout.Cardio     `bson:"cardio"`
+	DeletedAt *int64              `bson:"deleted_at"`
+}
+
+func (d document) workout() workout.Workout {
+	return workout.Workout{
+		ID:        d.ID,
+		UserID:    d.UserID,
+		StartTime: d.StartTime,
+		Kind:      d.Kind,
+		Exercises: d.Exercises,
+		Groups:    d.Groups,
+		Cardio:    d.Cardio,
+		DeletedAt: d.DeletedAt,
+	}
+}
+
+// kindOf возвращает вид тренировки, по умолчанию силовая
+func kindOf(w workout.Workout) workout.Kind {
+	if w.Kind == "" {
+		return workout.KindStrength
+	}
+	return w.Kind
+}
+
+// Create создает новую тренировку
+func (r *Repository) Create(ctx context.Context, w workout.Workout) (int64, error) {
+	id, err := mongodb.NextID(ctx, r.db, r.collection.Name())
+	if err != nil {
+		return 0, err
+	}
+	doc := document{
+		ID:        id,
+		UserID:    w.UserID,
+		StartTime: w.StartTime,
+		Kind:      kindOf(w),
+		Exercises: w.Exercises,
+		Groups:    w.Groups,
+		Cardio:    w.Cardio,
+	}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// find возвращает тренировки по фильтру в заданном порядке
+func (r *Repository) find(ctx context.Context, filter bson.M, sort bson.D) ([]workout.Workout, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	workouts := make([]workout.Workout, 0)
+	for cursor.Next(ctx) {
+		var d document
+		if err := cursor.Decode(&d); err != nil {
+			return nil, err
+		}
+		workouts = append(workouts, d.workout())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return workouts, nil
+}
+
+// FindAllByUserID возвращает список всех тренировок пользователя, кроме находящихся в корзине
+func (r *Repository) FindAllByUserID(ctx context.Context, userID int64) ([]workout.Workout, error) {
+	return r.find(ctx, bson.M{"user_id": userID, "deleted_at": nil}, bson.D{{Key: "_id", Value: 1}})
+}
+
+// FindTrashedByUserID возвращает тренировки пользователя, находящиеся в корзине
+func (r *Repository) FindTrashedByUserID(ctx context.Context, userID int64) ([]workout.Workout, error) {
+	filter := bson.M{"user_id": userID, "deleted_at": bson.M{"$ne": nil}}
+	return r.find(ctx, filter, bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: 1}})
+}
+
+// FindAllWithTrashedItems возвращает активные тренировки, в которых есть упражнения или подходы в корзине
+func (r *Repository) FindAllWithTrashedItems(ctx context.Context) ([]workout.Workout, error) {
+	filter := bson.M{
+		"deleted_at": nil,
+		"$or": bson.A{
+			bson.M{"exercises.deleted_at": bson.M{"$exists": true}},
+			bson.M{"exercises.sets.deleted_at": bson.M{"$exists": true}},
+		},
+	}
+	return r.find(ctx, filter, bson.D{{Key: "_id", Value: 1}})
+}
+
+// FindOne ищет активную тренировку по ID
+func (r *Repository) FindOne(ctx context.Context, id int64) (workout.Workout, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "deleted_at": nil})
+}
+
+// FindOneTrashed ищет тренировку в корзине по ID
+func (r *Repository) FindOneTrashed(ctx context.Context, id int64) (workout.Workout, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}})
+}
+
+func (r *Repository) findOne(ctx context.Context, filter bson.M) (workout.Workout, error) {
+	var d document
+	if err := r.collection.FindOne(ctx, filter).Decode(&d); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return workout.Workout{}, apperror.NotFound("workout %v not found", filter["_id"])
+		}
+		return workout.Workout{}, err
+	}
+	return d.workout(), nil
+}
+
+// ExistsByStartTime проверяет, есть ли у пользователя тренировка с таким временем начала
+func (r *Repository) ExistsByStartTime(ctx context.Context, userID, startTime int64) (bool, error) {
+	filter := bson.M{"user_id": userID, "start_time": startTime, "deleted_at": nil}
+	n, err := r.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Update обновляет информацию о тренировке, не меняя её состояние в корзине
+func (r *Repository) Update(ctx context.Context, w workout.Workout) error {
+	update := bson.M{"$set": bson.M{
+		"user_id":    w.UserID,
+		"start_time": w.StartTime,
+		"kind":       kindOf(w),
+		"exercises":  w.Exercises,
+		"groups":     w.Groups,
+		"cardio":     w.Cardio,
+	}}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": w.ID}, update); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Trash перемещает тренировку в корзину
+func (r *Repository) Trash(ctx context.Context, id int64, deletedAt int64) error {
+	return r.setDeletedAt(ctx, bson.M{"_id": id, "deleted_at": nil}, deletedAt)
+}
+
+// Restore возвращает тренировку из корзины
+func (r *Repository) Restore(ctx context.Context, id int64) error {
+	return r.setDeletedAt(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}}, nil)
+}
+
+// setDeletedAt выполняет Trash/Restore и возвращает apperror.ErrNotFound, если тренировка не найдена
+func (r *Repository) setDeletedAt(ctx context.Context, filter bson.M, deletedAt interface{}) error {
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"deleted_at": deletedAt}})
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return apperror.NotFound("workout %v not found", filter["_id"])
+	}
+	return nil
+}
+
+// PurgeTrashed безвозвратно удаляет тренировки, перемещённые в корзину раньше before
+func (r *Repository) PurgeTrashed(ctx context.Context, before int64) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$ne": nil, "$lt": before}})
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// Delete удаляет тренировку по ID
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(db *mongo.Database) *Repository {
+	return &Repository{
+		db:         db,
+		collection: db.Collection("workouts"),
+	}
+}
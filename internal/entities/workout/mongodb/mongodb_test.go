@@ -0,0 +1,17 @@
+package mongodb
+
+import (
+	"fit-journal/internal/entities/storagetest"
+	userMongo "fit-journal/internal/entities/user/mongodb"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunWorkouts(t, func(t *testing.T) storagetest.Repositories {
+		db := storagetest.MongoDB(t)
+		return storagetest.Repositories{
+			Users:    userMongo.NewRepository(db),
+			Workouts: NewRepository(db),
+		}
+	})
+}
@@ -0,0 +1,319 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/pkg/client/sqlite"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"strings"
+)
+
+type Repository struct {
+	client sqlite.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// kindOf возвращает вид тренировки, по умолчанию силовая
+func kindOf(w workout.Workout) string {
+	if w.Kind == "" {
+		return string(workout.KindStrength)
+	}
+	return string(w.Kind)
+}
+
+// encode сериализует JSON-колонки тренировки; cardio остаётся NULL, если не задано
+func encode(w workout.Workout) (exercises, groups string, cardio interface{}, err error) {
+	data, err := json.Marshal(w.Exercises)
+	if err != nil {
+		return "", "", nil, err
+	}
+	exercises = string(data)
+
+	if data, err = json.Marshal(w.Groups); err != nil {
+		return "", "", nil, err
+	}
+	groups = string(data)
+
+	if w.Cardio != nil {
+		if data, err = json.Marshal(w.Cardio); err != nil {
+			return "", "", nil, err
+		}
+		cardio = string(data)
+	}
+	return exercises, groups, cardio, nil
+}
+
+// Create создает новую тренировку в БД
+func (r *Repository) Create(ctx context.Context, workout workout.Workout) (int64, error) {
+	q := `
+		INSERT INTO workouts
+			(user_id, start_time, kind, exercises, "groups", cardio)
+		VALUES
+			(?, ?, ?, ?, ?, ?)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	exercises, groups, cardio, err := encode(workout)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.client.ExecContext(ctx, q, workout.UserID, workout.StartTime, kindOf(workout), exercises, groups, cardio)
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// workoutColumns колонки, из которых собирается workout.Workout в scanWorkout
+const workoutColumns = `id, user_id, start_time, kind, exercises, "groups", cardio, deleted_at`
+
+// scanner строка результата *sql.Row или *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWorkout читает тренировку по workoutColumns
+func scanWorkout(row scanner) (workout.Workout, error) {
+	var w workout.Workout
+	var exercises, groups string
+	var cardio sql.NullString
+	if err := row.Scan(&w.ID, &w.UserID, &w.StartTime, &w.Kind, &exercises, &groups, &cardio, &w.DeletedAt); err != nil {
+		return workout.Workout{}, err
+	}
+	if err := json.Unmarshal([]byte(exercises), &w.Exercises); err != nil {
+		return workout.Workout{}, err
+	}
+	if err := json.Unmarshal([]byte(groups), &w.Groups); err != nil {
+		return workout.Workout{}, err
+	}
+	if cardio.Valid {
+		if err := json.Unmarshal([]byte(cardio.String), &w.Cardio); err != nil {
+			return workout.Workout{}, err
+		}
+	}
+	return w, nil
+}
+
+// scanWorkouts читает тренировки из результата запроса по workoutColumns
+func scanWorkouts(rows *sql.Rows) ([]workout.Workout, error) {
+	defer rows.Close()
+
+	workouts := make([]workout.Workout, 0)
+
+	for rows.Next() {
+		w, err := scanWorkout(rows)
+		if err != nil {
+			return nil, err
+		}
+		workouts = append(workouts, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return workouts, nil
+}
+
+// FindAllByUserID возвращает список всех тренировок пользователя, кроме находящихся в корзине
+func (r *Repository) FindAllByUserID(ctx context.Context, userID int64) ([]workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts WHERE user_id = ? AND deleted_at IS NULL
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// FindTrashedByUserID возвращает тренировки пользователя, находящиеся в корзине
+func (r *Repository) FindTrashedByUserID(ctx context.Context, userID int64) ([]workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts WHERE user_id = ? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// FindAllWithTrashedItems возвращает активные тренировки, в которых есть упражнения или подходы в корзине
+func (r *Repository) FindAllWithTrashedItems(ctx context.Context) ([]workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts
+		WHERE deleted_at IS NULL
+		  AND EXISTS (
+			SELECT 1 FROM json_each(workouts.exercises) AS e
+			WHERE json_extract(e.value, '$.deleted_at') IS NOT NULL
+			   OR EXISTS (SELECT 1 FROM json_each(e.value, '$.sets') AS s WHERE json_extract(s.value, '$.deleted_at') IS NOT NULL)
+		  )
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// FindOne ищет активную тренировку по ID
+func (r *Repository) FindOne(ctx context.Context, id int64) (workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts WHERE id = ? AND deleted_at IS NULL
+	`
+	return r.findOne(ctx, q, id)
+}
+
+// FindOneTrashed ищет тренировку в корзине по ID
+func (r *Repository) FindOneTrashed(ctx context.Context, id int64) (workout.Workout, error) {
+	q := `
+		SELECT ` + workoutColumns + ` FROM workouts WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	return r.findOne(ctx, q, id)
+}
+
+func (r *Repository) findOne(ctx context.Context, q string, id int64) (workout.Workout, error) {
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	w, err := scanWorkout(r.client.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return workout.Workout{}, apperror.NotFound("workout %d not found", id)
+		}
+		return workout.Workout{}, err
+	}
+
+	return w, nil
+}
+
+// ExistsByStartTime проверяет, есть ли у пользователя тренировка с таким временем начала
+func (r *Repository) ExistsByStartTime(ctx context.Context, userID, startTime int64) (bool, error) {
+	q := `
+		SELECT EXISTS(SELECT 1 FROM workouts WHERE user_id = ? AND start_time = ? AND deleted_at IS NULL)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	var exists bool
+	if err := r.client.QueryRowContext(ctx, q, userID, startTime).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Update обновляет информацию о тренировке
+func (r *Repository) Update(ctx context.Context, workout workout.Workout) error {
+	q := `
+		UPDATE workouts
+		SET user_id = ?, start_time = ?, kind = ?, exercises = ?, "groups" = ?, cardio = ?
+		WHERE id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	exercises, groups, cardio, err := encode(workout)
+	if err != nil {
+		return err
+	}
+	if _, err := r.client.ExecContext(ctx, q, workout.UserID, workout.StartTime, kindOf(workout), exercises, groups, cardio, workout.ID); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// Trash перемещает тренировку в корзину
+func (r *Repository) Trash(ctx context.Context, id int64, deletedAt int64) error {
+	q := `
+		UPDATE workouts SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL
+	`
+	return r.setDeletedAt(ctx, q, deletedAt, id)
+}
+
+// Restore возвращает тренировку из корзины
+func (r *Repository) Restore(ctx context.Context, id int64) error {
+	q := `
+		UPDATE workouts SET deleted_at = ? WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	return r.setDeletedAt(ctx, q, nil, id)
+}
+
+// setDeletedAt выполняет Trash/Restore и возвращает apperror.ErrNotFound, если тренировка не найдена
+func (r *Repository) setDeletedAt(ctx context.Context, q string, deletedAt interface{}, id int64) error {
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	res, err := r.client.ExecContext(ctx, q, deletedAt, id)
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperror.NotFound("workout %d not found", id)
+	}
+
+	return nil
+}
+
+// PurgeTrashed безвозвратно удаляет тренировки, перемещённые в корзину раньше before
+func (r *Repository) PurgeTrashed(ctx context.Context, before int64) (int64, error) {
+	q := `
+		DELETE FROM workouts WHERE deleted_at IS NOT NULL AND deleted_at < ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	res, err := r.client.ExecContext(ctx, q, before)
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Delete удаляет тренировку по ID
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	q := `
+		DELETE FROM workouts
+		WHERE id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if _, err := r.client.ExecContext(ctx, q, id); err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client sqlite.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
@@ -0,0 +1,87 @@
+package workout
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ActivityTotals итоги по одному виду кардио-активности
+type ActivityTotals struct {
+	Workouts       int     `json:"workouts"`
+	DistanceMeters float64 `json:"distance_meters"`
+	MovingTimeSec  int64   `json:"moving_time_sec"`
+	ElevationGainM float64 `json:"elevation_gain_m"`
+}
+
+// WeekTotals итоги за календарную (ISO) неделю
+type WeekTotals struct {
+	Week             string                          `json:"week"`       // Неделя в формате 2024-W05
+	StartDate        string                          `json:"start_date"` // Понедельник недели
+	Workouts         int                             `json:"workouts"`
+	StrengthWorkouts int                             `json:"strength_workouts"`
+	CardioWorkouts   int                             `json:"cardio_workouts"`
+	Sets             int                             `json:"sets"`
+	Reps             int                             `json:"reps"`
+	Volume           float64                         `json:"volume"`
+	DistanceMeters   float64                         `json:"distance_meters"`
+	MovingTimeSec    int64                           `json:"moving_time_sec"`
+	ElevationGainM   float64                         `json:"elevation_gain_m"`
+	Activities       map[ActivityType]ActivityTotals `json:"activities,omitempty"`
+}
+
+// WeeklyTotals группирует тренировки по ISO-неделям и считает итоги силовой и кардио-нагрузки.
+// Недели возвращаются в порядке убывания, учитываются только тренировки начиная с since.
+func WeeklyTotals(workouts []Workout, since time.Time, loc *time.Location) []WeekTotals {
+	weeks := make(map[string]*WeekTotals)
+
+	for _, w := range workouts {
+		start := time.Unix(w.StartTime, 0).In(loc)
+		if start.Before(since) {
+			continue
+		}
+
+		year, week := start.ISOWeek()
+		key := fmt.Sprintf("%04d-W%02d", year, week)
+		t, ok := weeks[key]
+		if !ok {
+			monday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+			t = &WeekTotals{Week: key, StartDate: monday.Format("2006-01-02")}
+			weeks[key] = t
+		}
+
+		t.Workouts++
+		if w.Kind == KindCardio && w.Cardio != nil {
+			t.CardioWorkouts++
+			t.DistanceMeters += w.Cardio.DistanceMeters
+			t.MovingTimeSec += w.Cardio.MovingTimeSec
+			t.ElevationGainM += w.Cardio.ElevationGainM
+
+			if t.Activities == nil {
+				t.Activities = make(map[ActivityType]ActivityTotals)
+			}
+			a := t.Activities[w.Cardio.Activity]
+			a.Workouts++
+			a.DistanceMeters += w.Cardio.DistanceMeters
+			a.MovingTimeSec += w.Cardio.MovingTimeSec
+			a.ElevationGainM += w.Cardio.ElevationGainM
+			t.Activities[w.Cardio.Activity] = a
+		} else {
+			t.StrengthWorkouts++
+		}
+
+		// Подходы учитываются и в кардио-тренировках, если к ним добавлены упражнения
+		summary := Summarize(w)
+		t.Sets += summary.Sets
+		t.Reps += summary.Reps
+		t.Volume += summary.Volume
+	}
+
+	result := make([]WeekTotals, 0, len(weeks))
+	for _, t := range weeks {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Week > result[j].Week })
+
+	return result
+}
@@ -8,4 +8,13 @@ type Repository interface {
 	Update(ctx context.Context, workout Workout) error
 	Delete(ctx context.Context, id int64) error
 	FindAllByUserID(ctx context.Context, id int64) (w []Workout, err error)
+	ExistsByStartTime(ctx context.Context, userID, startTime int64) (bool, error)
+
+	// Корзина: FindOne и FindAllByUserID не возвращают тренировки, перемещённые в корзину
+	Trash(ctx context.Context, id int64, deletedAt int64) error
+	Restore(ctx context.Context, id int64) error
+	FindOneTrashed(ctx context.Context, id int64) (Workout, error)
+	FindTrashedByUserID(ctx context.Context, userID int64) ([]Workout, error)
+	FindAllWithTrashedItems(ctx context.Context) ([]Workout, error)
+	PurgeTrashed(ctx context.Context, before int64) (int64, error)
 }
@@ -0,0 +1,422 @@
+package workout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/uow"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WithoutTrashed возвращает копию тренировки без упражнений и подходов из корзины.
+// Упражнения из корзины также исключаются из групп, группы с недостаточным числом упражнений скрываются.
+func (w Workout) WithoutTrashed() Workout {
+	exercises := make([]exercise.Exercise, 0, len(w.Exercises))
+	trashed := make(map[int64]bool)
+	for _, ex := range w.Exercises {
+		if ex.DeletedAt != nil {
+			trashed[ex.ID] = true
+			continue
+		}
+		sets := make([]exercise.ExerciseSet, 0, len(ex.Sets))
+		for _, set := range ex.Sets {
+			if set.DeletedAt == nil {
+				sets = append(sets, set)
+			}
+		}
+		ex.Sets = sets
+		exercises = append(exercises, ex)
+	}
+	w.Exercises = exercises
+
+	groups := make([]exercise.Group, 0, len(w.Groups))
+	for _, g := range w.Groups {
+		ids := make([]int64, 0, len(g.ExerciseIDs))
+		for _, id := range g.ExerciseIDs {
+			if !trashed[id] {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) < g.Type.MinExercises() {
+			continue
+		}
+		g.ExerciseIDs = ids
+		groups = append(groups, g)
+	}
+	w.Groups = groups
+
+	return w
+}
+
+// PurgeTrashed безвозвратно удаляет упражнения и подходы, перемещённые в корзину раньше before.
+// Возвращает true, если тренировка изменилась.
+func (w *Workout) PurgeTrashed(before int64) bool {
+	changed := false
+	exercises := make([]exercise.Exercise, 0, len(w.Exercises))
+	for _, ex := range w.Exercises {
+		if ex.DeletedAt != nil && *ex.DeletedAt < before {
+			w.RemoveExerciseFromGroups(ex.ID)
+			changed = true
+			continue
+		}
+		sets := make([]exercise.ExerciseSet, 0, len(ex.Sets))
+		for _, set := range ex.Sets {
+			if set.DeletedAt != nil && *set.DeletedAt < before {
+				changed = true
+				continue
+			}
+			sets = append(sets, set)
+		}
+		ex.Sets = sets
+		exercises = append(exercises, ex)
+	}
+	w.Exercises = exercises
+	return changed
+}
+
+// TrashedExercise упражнение в корзине
+type TrashedExercise struct {
+	WorkoutID int64             `json:"workout_id"`
+	Exercise  exercise.Exercise `json:"exercise"`
+	PurgeAt   int64             `json:"purge_at"`
+}
+
+// TrashedSet подход в корзине
+type TrashedSet struct {
+	WorkoutID  int64                `json:"workout_id"`
+	ExerciseID int64                `json:"exercise_id"`
+	Set        exercise.ExerciseSet `json:"set"`
+	PurgeAt    int64                `json:"purge_at"`
+}
+
+// TrashedWorkout тренировка в корзине
+type TrashedWorkout struct {
+	Workout
+	PurgeAt int64 `json:"purge_at"`
+}
+
+// Trash содержимое корзины пользователя. PurgeAt время безвозвратного удаления (Unix).
+type Trash struct {
+	Workouts  []TrashedWorkout  `json:"workouts"`
+	Exercises []TrashedExercise `json:"exercises"`
+	Sets      []TrashedSet      `json:"sets"`
+}
+
+// CollectTrash собирает корзину из тренировок в корзине и упражнений/подходов в корзине активных тренировок
+func CollectTrash(trashed, active []Workout, retention time.Duration) Trash {
+	purgeAt := func(deletedAt int64) int64 { return deletedAt + int64(retention/time.Second) }
+
+	t := Trash{Workouts: []TrashedWorkout{}, Exercises: []TrashedExercise{}, Sets: []TrashedSet{}}
+	for _, w := range trashed {
+		t.Workouts = append(t.Workouts, TrashedWorkout{Workout: w, PurgeAt: purgeAt(*w.DeletedAt)})
+	}
+	for _, w := range active {
+		for _, ex := range w.Exercises {
+			if ex.DeletedAt != nil {
+				t.Exercises = append(t.Exercises, TrashedExercise{WorkoutID: w.ID, Exercise: ex, PurgeAt: purgeAt(*ex.DeletedAt)})
+				continue
+			}
+			for _, set := range ex.Sets {
+				if set.DeletedAt != nil {
+					t.Sets = append(t.Sets, TrashedSet{WorkoutID: w.ID, ExerciseID: ex.ID, Set: set, PurgeAt: purgeAt(*set.DeletedAt)})
+				}
+			}
+		}
+	}
+	return t
+}
+
+// includeTrashed возвращает значение параметра запроса include_trashed
+func includeTrashed(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("include_trashed")
+	if value == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, apperror.NewAppError(err, "Неверное значение include_trashed", "Ожидается true или false", http.StatusBadRequest)
+	}
+	return include, nil
+}
+
+// ownWorkout проверяет, что тренировка принадлежит пользователю из контекста запроса
+func (h *handler) ownWorkout(ctx context.Context, workout Workout) error {
+	username, ok := ctx.Value("username").(string)
+	if !ok {
+		logging.FromContext(ctx).Error("Ошибка извлечения username из контекста")
+		return apperror.NewAppError(nil, "Ошибка аутентификации", "Не удалось получить пользователя", http.StatusUnauthorized)
+	}
+
+	user, err := h.userRepository.FindOne(ctx, username)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("Ошибка получения пользователя по username: %v", err)
+		return apperror.NewAppError(err, "Ошибка проверки доступа к тренировке", "Ошибка получения пользователя", http.StatusInternalServerError)
+	}
+	if workout.UserID != user.ID {
+		return apperror.NewAppError(nil, "Тренировка не найдена", "Тренировка принадлежит другому пользователю", http.StatusNotFound)
+	}
+	return nil
+}
+
+// GetTrash возвращает содержимое корзины пользователя
+func (h *handler) GetTrash(w http.ResponseWriter, r *http.Request) error {
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		logging.FromContext(r.Context()).Error("Ошибка извлечения username из контекста")
+		return apperror.NewAppError(nil, "Ошибка аутентификации", "Не удалось получить пользователя", http.StatusUnauthorized)
+	}
+
+	ctx := r.Context()
+	user, err := h.userRepository.FindOne(ctx, username)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения пользователя по username: %v", err)
+		return apperror.NewAppError(err, "Ошибка при получении корзины", "Ошибка получения пользователя", http.StatusInternalServerError)
+	}
+
+	trashed, err := h.repository.FindTrashedByUserID(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения тренировок из корзины: %v", err)
+		return apperror.NewAppError(err, "Ошибка при получении корзины", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	}
+	active, err := h.repository.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения тренировок: %v", err)
+		return apperror.NewAppError(err, "Ошибка при получении корзины", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(CollectTrash(trashed, active, h.trashRetention)); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// RestoreWorkout возвращает тренировку из корзины
+func (h *handler) RestoreWorkout(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	workout, err := h.repository.FindOneTrashed(ctx, workoutID)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки из корзины: %v", err)
+		return apperror.NewAppError(err, "Тренировка не найдена в корзине", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	}
+	if err := h.ownWorkout(ctx, workout); err != nil {
+		return err
+	}
+
+	before := audit.Snapshot(workout)
+	if err := h.repository.Restore(ctx, workoutID); err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка восстановления тренировки: %v", err)
+		return apperror.NewAppError(err, "Ошибка при восстановлении тренировки", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	}
+	workout.DeletedAt = nil
+	h.record(r, audit.ActionRestore, before, workout)
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(workout.WithoutTrashed()); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// RestoreExercise возвращает упражнение из корзины в тренировку
+func (h *handler) RestoreExercise(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
+	}
+	exerciseID, err := paramID(r, "exercise_id")
+	if err != nil {
+		return err
+	}
+
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
+
+		// Упражнение из корзины не возвращается в тренировку, ставшую кардио
+		if workout.Kind == KindCardio {
+			return apperror.NewAppError(nil, "В кардио-тренировку нельзя вернуть упражнение", "Упражнения допустимы только в силовой тренировке", http.StatusConflict)
+		}
+
+		found := false
+		for i := range workout.Exercises {
+			if workout.Exercises[i].ID == exerciseID && workout.Exercises[i].DeletedAt != nil {
+				workout.Exercises[i].DeletedAt = nil
+				found = true
+				break
+			}
+		}
+		if !found {
+			return apperror.NewAppError(nil, "Упражнение не найдено в корзине", "Ошибка поиска упражнения", http.StatusNotFound)
+		}
+
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при восстановлении упражнения", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.record(r, audit.ActionRestore, before, workout)
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(workout.WithoutTrashed()); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// RestoreSet возвращает подход из корзины. Упражнение подхода должно быть активным.
+func (h *handler) RestoreSet(w http.ResponseWriter, r *http.Request) error {
+	workoutID, err := paramID(r, "workout_id")
+	if err != nil {
+		return err
+	}
+	exerciseID, err := paramID(r, "exercise_id")
+	if err != nil {
+		return err
+	}
+	setID, err := paramID(r, "set_id")
+	if err != nil {
+		return err
+	}
+
+	var workout Workout
+	var before json.RawMessage
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		workout, err = h.repository.FindOne(ctx, workoutID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения тренировки: %v", err)
+			return apperror.NewAppError(err, "Тренировка не найдена", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		if err := h.ownWorkout(ctx, workout); err != nil {
+			return err
+		}
+		before = audit.Snapshot(workout)
+
+		ex, ok := workout.FindExercise(exerciseID)
+		if !ok {
+			return apperror.NewAppError(nil, "Упражнение не найдено", "Упражнение отсутствует или находится в корзине", http.StatusNotFound)
+		}
+		found := false
+		for i := range ex.Sets {
+			if ex.Sets[i].ID == setID && ex.Sets[i].DeletedAt != nil {
+				ex.Sets[i].DeletedAt = nil
+				found = true
+				break
+			}
+		}
+		if !found {
+			return apperror.NewAppError(nil, "Подход не найден в корзине", "Ошибка поиска подхода", http.StatusNotFound)
+		}
+
+		if err := h.repository.Update(ctx, workout); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка обновления тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при восстановлении подхода", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.record(r, audit.ActionRestore, before, workout)
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(workout.WithoutTrashed()); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// PurgeTrash безвозвратно удаляет из корзины всё, что находится в ней дольше retention.
+// Тренировка с упражнениями и подходами в корзине перечитывается и сохраняется в одной единице работы,
+// чтобы не затереть параллельное восстановление или правку.
+func PurgeTrash(ctx context.Context, repo Repository, unitOfWork uow.UnitOfWork, retention time.Duration, now time.Time) (int, error) {
+	before := now.Add(-retention).Unix()
+
+	purged, err := repo.PurgeTrashed(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	workouts, err := repo.FindAllWithTrashedItems(ctx)
+	if err != nil {
+		return int(purged), err
+	}
+	for _, candidate := range workouts {
+		err := unitOfWork.Do(ctx, func(ctx context.Context) error {
+			w, err := repo.FindOne(ctx, candidate.ID)
+			if errors.Is(err, apperror.ErrNotFound) {
+				// Тренировка удалена после выборки
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if !w.PurgeTrashed(before) {
+				return nil
+			}
+			if err := repo.Update(ctx, w); err != nil {
+				return err
+			}
+			purged++
+			return nil
+		})
+		if err != nil {
+			return int(purged), err
+		}
+	}
+
+	return int(purged), nil
+}
+
+// StartTrashPurge запускает периодическую очистку корзины до отмены контекста; горутина учитывается в wg
+func StartTrashPurge(ctx context.Context, wg *sync.WaitGroup, repo Repository, unitOfWork uow.UnitOfWork, logger *logging.Logger, retention, interval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := PurgeTrash(ctx, repo, unitOfWork, retention, time.Now()); err != nil {
+				logger.Errorf("Ошибка очистки корзины: %v", err)
+			} else if n > 0 {
+				logger.Infof("Из корзины удалено записей: %d", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
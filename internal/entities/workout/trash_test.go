@@ -0,0 +1,206 @@
+package workout_test
+
+import (
+	"context"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/internal/entities/workout/memory"
+	"fit-journal/pkg/uow"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func at(unix int64) *int64 { return &unix }
+
+// sample тренировка с суперсетом из упражнений 1 и 2 и отдельным упражнением 3
+func sample(exerciseDeleted, setDeleted *int64) workout.Workout {
+	return workout.Workout{
+		ID:     1,
+		UserID: 1,
+		Kind:   workout.KindStrength,
+		Exercises: []exercise.Exercise{
+			{ID: 1, Name: "Squat", Sets: []exercise.ExerciseSet{{ID: 11, Reps: 5}, {ID: 12, Reps: 5, DeletedAt: setDeleted}}},
+			{ID: 2, Name: "Lunge", DeletedAt: exerciseDeleted, Sets: []exercise.ExerciseSet{{ID: 21, Reps: 8}}},
+			{ID: 3, Name: "Plank", Sets: []exercise.ExerciseSet{}},
+		},
+		Groups: []exercise.Group{{ID: 1, Type: exercise.GroupSuperset, ExerciseIDs: []int64{1, 2}}},
+	}
+}
+
+// ids возвращает ID упражнений и подходов тренировки в порядке обхода
+func ids(w workout.Workout) []int64 {
+	var result []int64
+	for _, ex := range w.Exercises {
+		result = append(result, ex.ID)
+		for _, set := range ex.Sets {
+			result = append(result, set.ID)
+		}
+	}
+	return result
+}
+
+func TestWithoutTrashed(t *testing.T) {
+	tests := []struct {
+		name       string
+		workout    workout.Workout
+		wantIDs    []int64
+		wantGroups int
+	}{
+		{"nothing trashed", sample(nil, nil), []int64{1, 11, 12, 2, 21, 3}, 1},
+		{"trashed set", sample(nil, at(100)), []int64{1, 11, 2, 21, 3}, 1},
+		// Суперсет без второго упражнения теряет смысл и скрывается
+		{"trashed exercise", sample(at(100), nil), []int64{1, 11, 12, 3}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := ids(tt.workout)
+			got := tt.workout.WithoutTrashed()
+			if !reflect.DeepEqual(ids(got), tt.wantIDs) || len(got.Groups) != tt.wantGroups {
+				t.Errorf("WithoutTrashed = %v with %d groups, want %v with %d", ids(got), len(got.Groups), tt.wantIDs, tt.wantGroups)
+			}
+			if !reflect.DeepEqual(ids(tt.workout), original) {
+				t.Error("WithoutTrashed modified the original workout")
+			}
+		})
+	}
+}
+
+func TestPurgeTrashedItems(t *testing.T) {
+	tests := []struct {
+		name        string
+		workout     workout.Workout
+		before      int64
+		wantChanged bool
+		wantIDs     []int64
+		wantGroups  int
+	}{
+		{"nothing trashed", sample(nil, nil), 200, false, []int64{1, 11, 12, 2, 21, 3}, 1},
+		{"retention not expired", sample(at(100), at(100)), 100, false, []int64{1, 11, 12, 2, 21, 3}, 1},
+		{"expired set", sample(nil, at(100)), 200, true, []int64{1, 11, 2, 21, 3}, 1},
+		// Удалённое упражнение убирается и из групп
+		{"expired exercise", sample(at(100), nil), 200, true, []int64{1, 11, 12, 3}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tt.workout
+			changed := w.PurgeTrashed(tt.before)
+			if changed != tt.wantChanged || !reflect.DeepEqual(ids(w), tt.wantIDs) || len(w.Groups) != tt.wantGroups {
+				t.Errorf("PurgeTrashed = %v, %v with %d groups; want %v, %v with %d",
+					changed, ids(w), len(w.Groups), tt.wantChanged, tt.wantIDs, tt.wantGroups)
+			}
+		})
+	}
+}
+
+func TestCollectTrash(t *testing.T) {
+	trashed := sample(nil, nil)
+	trashed.ID, trashed.DeletedAt = 2, at(300)
+	active := sample(at(100), at(200))
+
+	trash := workout.CollectTrash([]workout.Workout{trashed}, []workout.Workout{active}, time.Hour)
+
+	if len(trash.Workouts) != 1 || trash.Workouts[0].ID != 2 || trash.Workouts[0].PurgeAt != 300+3600 {
+		t.Errorf("workouts = %+v", trash.Workouts)
+	}
+	if len(trash.Exercises) != 1 || trash.Exercises[0].Exercise.ID != 2 || trash.Exercises[0].PurgeAt != 100+3600 {
+		t.Errorf("exercises = %+v", trash.Exercises)
+	}
+	// Подходы удалённого упражнения в корзине упражнения, отдельно не перечисляются
+	if len(trash.Sets) != 1 || trash.Sets[0].Set.ID != 12 || trash.Sets[0].ExerciseID != 1 || trash.Sets[0].PurgeAt != 200+3600 {
+		t.Errorf("sets = %+v", trash.Sets)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(10000, 0)
+	repo := memory.NewRepository()
+
+	create := func(w workout.Workout) int64 {
+		t.Helper()
+		id, err := repo.Create(ctx, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	expired := create(sample(nil, nil))
+	recent := create(sample(nil, nil))
+	withItems := create(sample(at(100), at(now.Unix())))
+	if err := repo.Trash(ctx, expired, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Trash(ctx, recent, now.Unix()); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := workout.PurgeTrash(ctx, repo, uow.Serial(), time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Тренировка из корзины и тренировка, из которой удалено упражнение
+	if purged != 2 {
+		t.Errorf("PurgeTrash = %d, want 2", purged)
+	}
+	if _, err := repo.FindOneTrashed(ctx, expired); err == nil {
+		t.Error("expired workout is still in the trash")
+	}
+	if _, err := repo.FindOneTrashed(ctx, recent); err != nil {
+		t.Errorf("recently trashed workout was purged: %v", err)
+	}
+	w, err := repo.FindOne(ctx, withItems)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids(w), []int64{1, 11, 12, 3}) {
+		t.Errorf("workout items after purge = %v", ids(w))
+	}
+}
+
+// staleRepository отдаёт выборку тренировок с корзиной, после чего тренировку успевают изменить
+type staleRepository struct {
+	*memory.Repository
+	afterFind func()
+}
+
+func (r staleRepository) FindAllWithTrashedItems(ctx context.Context) ([]workout.Workout, error) {
+	workouts, err := r.Repository.FindAllWithTrashedItems(ctx)
+	r.afterFind()
+	return workouts, err
+}
+
+func TestPurgeTrashKeepsConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(10000, 0)
+	inner := memory.NewRepository()
+	id, err := inner.Create(ctx, sample(at(100), at(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Между выборкой и очисткой подход 12 восстановлен из корзины
+	repo := staleRepository{Repository: inner, afterFind: func() {
+		w, err := inner.FindOne(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Exercises[0].Sets[1].DeletedAt = nil
+		if err := inner.Update(ctx, w); err != nil {
+			t.Fatal(err)
+		}
+	}}
+
+	if _, err := workout.PurgeTrash(ctx, repo, uow.Serial(), time.Hour, now); err != nil {
+		t.Fatal(err)
+	}
+	w, err := inner.FindOne(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids(w), []int64{1, 11, 12, 3}) {
+		t.Errorf("workout items after purge = %v, want the restored set 12 kept", ids(w))
+	}
+}
@@ -0,0 +1,23 @@
+package db
+
+import (
+	auditDB "fit-journal/internal/audit/db"
+	metricDB "fit-journal/internal/entities/metric/db"
+	"fit-journal/internal/entities/storagetest"
+	userDB "fit-journal/internal/entities/user/db"
+	workoutDB "fit-journal/internal/entities/workout/db"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunErasure(t, func(t *testing.T) storagetest.Repositories {
+		client := storagetest.Postgres(t)
+		return storagetest.Repositories{
+			Users:    userDB.NewRepository(client),
+			Workouts: workoutDB.NewRepository(client),
+			Metrics:  metricDB.NewRepository(client),
+			Audit:    auditDB.NewRepository(client),
+			Erasure:  NewRepository(client),
+		}
+	})
+}
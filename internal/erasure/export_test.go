@@ -0,0 +1,8 @@
+package erasure
+
+import "time"
+
+// SetNow подменяет часы сервиса в тестах
+func (s *Service) SetNow(now func() time.Time) {
+	s.now = now
+}
@@ -0,0 +1,152 @@
+package erasure
+
+import (
+	"encoding/json"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/auth"
+	"fit-journal/internal/entities/user"
+	"fit-journal/internal/handlers"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+)
+
+const (
+	erasureURL       = "/users/erasure"
+	erasureExportURL = "/users/erasure/export"
+)
+
+type handler struct {
+	service        *Service
+	userRepository user.Repository
+	auth           *auth.Service
+}
+
+func NewHandler(service *Service, userRepo user.Repository, authService *auth.Service) handlers.Handler {
+	return &handler{
+		service:        service,
+		userRepository: userRepo,
+		auth:           authService,
+	}
+}
+
+func (h *handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, erasureURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.GetRequest)))
+	router.HandlerFunc(http.MethodPost, erasureURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.CreateRequest)))
+	router.HandlerFunc(http.MethodDelete, erasureURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.CancelRequest)))
+	router.HandlerFunc(http.MethodGet, erasureExportURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.DownloadExport)))
+}
+
+// currentUser возвращает пользователя, от имени которого выполняется запрос
+func (h *handler) currentUser(r *http.Request) (user.User, error) {
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		return user.User{}, apperror.NewAppError(nil, "Invalid or missing username", "", http.StatusUnauthorized)
+	}
+
+	u, err := h.userRepository.FindOne(r.Context(), username)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return user.User{}, apperror.NewAppError(err, "User not found", "", http.StatusInternalServerError)
+	}
+	return u, nil
+}
+
+// requestResponse описание запроса на удаление для клиента
+type requestResponse struct {
+	Request
+	ExportURL string `json:"export_url"`
+}
+
+func writeRequest(w http.ResponseWriter, status int, req Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(requestResponse{Request: req, ExportURL: erasureExportURL})
+}
+
+// GetRequest возвращает текущий запрос на удаление
+func (h *handler) GetRequest(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := h.service.Status(r.Context(), u.ID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return apperror.NewAppError(err, "No pending deletion request", "", http.StatusNotFound)
+		}
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to fetch deletion request", "", http.StatusInternalServerError)
+	}
+
+	return writeRequest(w, http.StatusOK, req)
+}
+
+// CreateRequest планирует удаление аккаунта после срока ожидания
+func (h *handler) CreateRequest(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := h.service.Schedule(r.Context(), u)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to schedule account deletion", "", http.StatusInternalServerError)
+	}
+
+	return writeRequest(w, http.StatusAccepted, req)
+}
+
+// CancelRequest отменяет запрос на удаление
+func (h *handler) CancelRequest(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.Cancel(r.Context(), u.ID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return apperror.NewAppError(err, "No pending deletion request", "", http.StatusNotFound)
+		}
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to cancel account deletion", "", http.StatusInternalServerError)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// DownloadExport отдаёт архив с выгрузкой, сформированный при создании запроса
+func (h *handler) DownloadExport(w http.ResponseWriter, r *http.Request) error {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := h.service.Status(r.Context(), u.ID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return apperror.NewAppError(err, "No pending deletion request", "", http.StatusNotFound)
+		}
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to fetch deletion request", "", http.StatusInternalServerError)
+	}
+
+	f, err := os.Open(req.ExportPath)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Export bundle is not available", "use GET /export?format=zip instead", http.StatusNotFound)
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("fit-journal-%s-erasure.zip", u.Username)))
+	w.WriteHeader(http.StatusOK)
+	_, err = f.WriteTo(w)
+	return err
+}
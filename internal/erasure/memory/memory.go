@@ -0,0 +1,122 @@
+// Package memory хранит запросы на удаление аккаунтов в памяти процесса для режима --storage=memory
+package memory
+
+import (
+	"context"
+	"fit-journal/internal/apperror"
+	auditMemory "fit-journal/internal/audit/memory"
+	metricMemory "fit-journal/internal/entities/metric/memory"
+	userMemory "fit-journal/internal/entities/user/memory"
+	workoutMemory "fit-journal/internal/entities/workout/memory"
+	"fit-journal/internal/erasure"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Repository запросы на удаление. EraseUser удаляет данные пользователя из остальных репозиториев в памяти.
+type Repository struct {
+	users    *userMemory.Repository
+	workouts *workoutMemory.Repository
+	metrics  *metricMemory.Repository
+	audit    *auditMemory.Repository
+
+	mu       sync.RWMutex
+	requests map[int64]erasure.Request
+}
+
+// NewRepository создает пустой репозиторий поверх репозиториев с данными пользователей
+func NewRepository(users *userMemory.Repository, workouts *workoutMemory.Repository, metrics *metricMemory.Repository, audit *auditMemory.Repository) *Repository {
+	return &Repository{
+		users:    users,
+		workouts: workouts,
+		metrics:  metrics,
+		audit:    audit,
+		requests: make(map[int64]erasure.Request),
+	}
+}
+
+// Create сохраняет запрос на удаление
+func (r *Repository) Create(_ context.Context, req erasure.Request) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.requests[req.UserID]; ok {
+		return apperror.Conflict("deletion request for user %d already exists", req.UserID)
+	}
+	req.RequestedAt, req.EraseAfter = time.Unix(req.RequestedAt.Unix(), 0), time.Unix(req.EraseAfter.Unix(), 0)
+	r.requests[req.UserID] = req
+	return nil
+}
+
+// FindByUserID возвращает запрос на удаление пользователя
+func (r *Repository) FindByUserID(_ context.Context, userID int64) (erasure.Request, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req, ok := r.requests[userID]
+	if !ok {
+		return erasure.Request{}, erasure.ErrNotFound
+	}
+	return req, nil
+}
+
+// Delete отменяет запрос на удаление
+func (r *Repository) Delete(_ context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.requests[userID]; !ok {
+		return erasure.ErrNotFound
+	}
+	delete(r.requests, userID)
+	return nil
+}
+
+// FindDue возвращает запросы, срок ожидания которых истёк, по возрастанию ID пользователя
+func (r *Repository) FindDue(_ context.Context, now time.Time) ([]erasure.Request, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	requests := make([]erasure.Request, 0)
+	for _, req := range r.requests {
+		if req.EraseAfter.Unix() <= now.Unix() {
+			requests = append(requests, req)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].UserID < requests[j].UserID })
+	return requests, nil
+}
+
+// EnqueueSoftDeleted создаёт запросы на удаление для пользователей, помеченных удалёнными
+func (r *Repository) EnqueueSoftDeleted(_ context.Context, requestedAt, eraseAfter time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var created int64
+	for _, id := range r.users.DeletedIDs() {
+		if _, ok := r.requests[id]; ok {
+			continue
+		}
+		r.requests[id] = erasure.Request{
+			UserID:      id,
+			RequestedAt: time.Unix(requestedAt.Unix(), 0),
+			EraseAfter:  time.Unix(eraseAfter.Unix(), 0),
+		}
+		created++
+	}
+	return created, nil
+}
+
+// EraseUser удаляет метрики, тренировки, журнал аудита, запрос на удаление и самого пользователя
+func (r *Repository) EraseUser(_ context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics.DeleteByUserID(userID)
+	r.workouts.DeleteByUserID(userID)
+	r.audit.DeleteByOwner(userID)
+	delete(r.requests, userID)
+	r.users.Erase(userID)
+	return nil
+}
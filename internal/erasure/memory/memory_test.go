@@ -0,0 +1,23 @@
+package memory
+
+import (
+	auditMemory "fit-journal/internal/audit/memory"
+	metricMemory "fit-journal/internal/entities/metric/memory"
+	"fit-journal/internal/entities/storagetest"
+	userMemory "fit-journal/internal/entities/user/memory"
+	workoutMemory "fit-journal/internal/entities/workout/memory"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunErasure(t, func(t *testing.T) storagetest.Repositories {
+		users, workouts, metrics, auditLog := userMemory.NewRepository(), workoutMemory.NewRepository(), metricMemory.NewRepository(), auditMemory.NewRepository()
+		return storagetest.Repositories{
+			Users:    users,
+			Workouts: workouts,
+			Metrics:  metrics,
+			Audit:    auditLog,
+			Erasure:  NewRepository(users, workouts, metrics, auditLog),
+		}
+	})
+}
@@ -0,0 +1,11 @@
+package erasure
+
+import "time"
+
+// Request запрос пользователя на полное удаление аккаунта и всех его данных
+type Request struct {
+	UserID      int64     `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+	EraseAfter  time.Time `json:"erase_after"` // После этого момента данные удаляются безвозвратно
+	ExportPath  string    `json:"-"`           // Архив с выгрузкой данных, доступный до удаления
+}
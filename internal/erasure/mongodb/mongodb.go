@@ -0,0 +1,167 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/erasure"
+	"fit-journal/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type Repository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// document запрос на удаление в коллекции deletion_requests; _id равен ID пользователя
+type document struct {
+	UserID      int64  `bson:"_id"`
+	RequestedAt int64  `bson:"requested_at"`
+	EraseAfter  int64  `bson:"erase_after"`
+	ExportPath  string `bson:"export_path"`
+}
+
+func (d document) request() erasure.Request {
+	return erasure.Request{
+		UserID:      d.UserID,
+		RequestedAt: time.Unix(d.RequestedAt, 0),
+		EraseAfter:  time.Unix(d.EraseAfter, 0),
+		ExportPath:  d.ExportPath,
+	}
+}
+
+// Create сохраняет запрос на удаление
+func (r *Repository) Create(ctx context.Context, req erasure.Request) error {
+	doc := document{
+		UserID:      req.UserID,
+		RequestedAt: req.RequestedAt.Unix(),
+		EraseAfter:  req.EraseAfter.Unix(),
+		ExportPath:  req.ExportPath,
+	}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperror.Conflict("deletion request for user %d already exists", req.UserID)
+		}
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// FindByUserID возвращает запрос на удаление пользователя
+func (r *Repository) FindByUserID(ctx context.Context, userID int64) (erasure.Request, error) {
+	var d document
+	if err := r.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&d); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return erasure.Request{}, erasure.ErrNotFound
+		}
+		return erasure.Request{}, err
+	}
+	return d.request(), nil
+}
+
+// Delete отменяет запрос на удаление
+func (r *Repository) Delete(ctx context.Context, userID int64) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return erasure.ErrNotFound
+	}
+	return nil
+}
+
+// FindDue возвращает запросы, срок ожидания которых истёк, по возрастанию ID пользователя
+func (r *Repository) FindDue(ctx context.Context, now time.Time) ([]erasure.Request, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"erase_after": bson.M{"$lte": now.Unix()}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	requests := make([]erasure.Request, 0)
+	for cursor.Next(ctx) {
+		var d document
+		if err := cursor.Decode(&d); err != nil {
+			return nil, err
+		}
+		requests = append(requests, d.request())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// EnqueueSoftDeleted создаёт запросы на удаление для аккаунтов с is_deleted = true,
+// не трогая уже существующие запросы
+func (r *Repository) EnqueueSoftDeleted(ctx context.Context, requestedAt, eraseAfter time.Time) (int64, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.db.Collection("users").Find(ctx, bson.M{"is_deleted": true}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var enqueued int64
+	for cursor.Next(ctx) {
+		var u struct {
+			ID int64 `bson:"_id"`
+		}
+		if err := cursor.Decode(&u); err != nil {
+			return enqueued, err
+		}
+		update := bson.M{"$setOnInsert": document{
+			UserID:      u.ID,
+			RequestedAt: requestedAt.Unix(),
+			EraseAfter:  eraseAfter.Unix(),
+		}}
+		res, err := r.collection.UpdateOne(ctx, bson.M{"_id": u.ID}, update, options.Update().SetUpsert(true))
+		if err != nil {
+			logging.FromContext(ctx).Error(err)
+			return enqueued, err
+		}
+		enqueued += res.UpsertedCount
+	}
+	return enqueued, cursor.Err()
+}
+
+// EraseUser удаляет метрики, тренировки, журнал аудита, запрос на удаление и самого пользователя.
+// Транзакции в MongoDB требуют набора реплик, поэтому удаление идёт по коллекциям, а запрос
+// на удаление удаляется последним: после сбоя он остаётся в FindDue и следующий проход завершит работу.
+func (r *Repository) EraseUser(ctx context.Context, userID int64) error {
+	steps := []struct {
+		collection string
+		filter     bson.M
+	}{
+		{"metrics", bson.M{"user_id": userID}},
+		{"workouts", bson.M{"user_id": userID}},
+		{"audit_log", bson.M{"owner_id": userID}},
+		{"users", bson.M{"_id": userID}},
+		{"deletion_requests", bson.M{"_id": userID}},
+	}
+
+	for _, step := range steps {
+		if _, err := r.db.Collection(step.collection).DeleteMany(ctx, step.filter); err != nil {
+			logging.FromContext(ctx).Error(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(db *mongo.Database) *Repository {
+	return &Repository{
+		db:         db,
+		collection: db.Collection("deletion_requests"),
+	}
+}
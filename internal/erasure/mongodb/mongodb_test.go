@@ -0,0 +1,23 @@
+package mongodb
+
+import (
+	auditMongo "fit-journal/internal/audit/mongodb"
+	metricMongo "fit-journal/internal/entities/metric/mongodb"
+	"fit-journal/internal/entities/storagetest"
+	userMongo "fit-journal/internal/entities/user/mongodb"
+	workoutMongo "fit-journal/internal/entities/workout/mongodb"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunErasure(t, func(t *testing.T) storagetest.Repositories {
+		db := storagetest.MongoDB(t)
+		return storagetest.Repositories{
+			Users:    userMongo.NewRepository(db),
+			Workouts: workoutMongo.NewRepository(db),
+			Metrics:  metricMongo.NewRepository(db),
+			Audit:    auditMongo.NewRepository(db),
+			Erasure:  NewRepository(db),
+		}
+	})
+}
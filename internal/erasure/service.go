@@ -0,0 +1,217 @@
+// Package erasure реализует полное удаление аккаунта по запросу пользователя.
+//
+// Запрос на удаление можно отменить, пока не истёк срок ожидания (config.ErasureConfig.GracePeriod).
+// При создании запроса формируется zip-архив с выгрузкой всех данных (формат пакета export,
+// включая корзину и журнал аудита), который пользователь может скачать до удаления. После истечения срока фоновая задача
+// безвозвратно удаляет метрики, тренировки, загрузки и самого пользователя, а затем и архив.
+// Сессий на сервере нет: JWT перестаёт работать, как только пользователь удалён.
+package erasure
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/config"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/internal/entities/user"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/internal/export"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Hook вызывается после удаления пользователя, чтобы очистить данные вне базы
+type Hook func(ctx context.Context, userID int64)
+
+type Service struct {
+	repository        Repository
+	workoutRepository workout.Repository
+	metricRepository  metric.Repository
+	auditRepository   audit.Repository
+	logger            *logging.Logger
+	cfg               config.ErasureConfig
+	now               func() time.Time
+
+	mu    sync.Mutex
+	hooks []Hook
+}
+
+func NewService(repo Repository, workoutRepo workout.Repository, metricRepo metric.Repository, auditRepo audit.Repository, logger *logging.Logger, cfg config.ErasureConfig) *Service {
+	return &Service{
+		repository:        repo,
+		workoutRepository: workoutRepo,
+		metricRepository:  metricRepo,
+		auditRepository:   auditRepo,
+		logger:            logger,
+		cfg:               cfg,
+		now:               time.Now,
+	}
+}
+
+// OnErase регистрирует обработчик, вызываемый после удаления пользователя
+func (s *Service) OnErase(hook Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, hook)
+}
+
+// Schedule создаёт запрос на удаление и архив с выгрузкой. Повторный вызов, в том числе одновременный,
+// возвращает существующий запрос.
+func (s *Service) Schedule(ctx context.Context, u user.User) (Request, error) {
+	existing, err := s.repository.FindByUserID(ctx, u.ID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return Request{}, err
+	}
+
+	now := s.now()
+	exportPath, err := s.writeBundle(ctx, u, now)
+	if err != nil {
+		return Request{}, fmt.Errorf("failed to write export bundle: %w", err)
+	}
+
+	req := Request{
+		UserID:      u.ID,
+		RequestedAt: now,
+		EraseAfter:  now.Add(s.cfg.GracePeriod),
+		ExportPath:  exportPath,
+	}
+	if err := s.repository.Create(ctx, req); err != nil {
+		s.removeBundle(exportPath)
+		// Одновременный запрос успел создать свой запрос на удаление вместе с архивом
+		if errors.Is(err, apperror.ErrConflict) {
+			return s.repository.FindByUserID(ctx, u.ID)
+		}
+		return Request{}, err
+	}
+
+	s.logger.Infof("Erasure of user %d scheduled after %s", u.ID, req.EraseAfter.Format(time.RFC3339))
+	return req, nil
+}
+
+// ScheduleErasure реализует user.ErasureScheduler
+func (s *Service) ScheduleErasure(ctx context.Context, u user.User) (time.Time, error) {
+	req, err := s.Schedule(ctx, u)
+	return req.EraseAfter, err
+}
+
+// Status возвращает запрос на удаление пользователя или ErrNotFound
+func (s *Service) Status(ctx context.Context, userID int64) (Request, error) {
+	return s.repository.FindByUserID(ctx, userID)
+}
+
+// Cancel отменяет запрос на удаление и удаляет архив с выгрузкой
+func (s *Service) Cancel(ctx context.Context, userID int64) error {
+	req, err := s.repository.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repository.Delete(ctx, userID); err != nil {
+		return err
+	}
+	s.removeBundle(req.ExportPath)
+
+	s.logger.Infof("Erasure of user %d cancelled", userID)
+	return nil
+}
+
+// RunDue удаляет пользователей, срок отмены запросов которых истёк
+func (s *Service) RunDue(ctx context.Context) error {
+	requests, err := s.repository.FindDue(ctx, s.now())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, req := range requests {
+		if err := s.repository.EraseUser(ctx, req.UserID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to erase user %d: %w", req.UserID, err))
+			continue
+		}
+		s.removeBundle(req.ExportPath)
+
+		s.mu.Lock()
+		hooks := append([]Hook(nil), s.hooks...)
+		s.mu.Unlock()
+		for _, hook := range hooks {
+			hook(ctx, req.UserID)
+		}
+
+		s.logger.Infof("User %d erased", req.UserID)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Start ставит в очередь ранее мягко удалённые аккаунты и запускает фоновое удаление
+// с интервалом CheckInterval до отмены контекста. Фоновая горутина учитывается в wg.
+func (s *Service) Start(ctx context.Context, wg *sync.WaitGroup) {
+	now := s.now()
+	if n, err := s.repository.EnqueueSoftDeleted(ctx, now, now.Add(s.cfg.GracePeriod)); err != nil {
+		s.logger.Errorf("Failed to enqueue soft-deleted users: %v", err)
+	} else if n > 0 {
+		s.logger.Infof("Scheduled erasure of %d soft-deleted users", n)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.cfg.CheckInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.RunDue(ctx); err != nil {
+				s.logger.Errorf("Erasure run failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// writeBundle сохраняет архив с выгрузкой данных пользователя в каталог ExportDir.
+// Имя архива уникально, чтобы одновременные запросы не перезаписали архивы друг друга.
+func (s *Service) writeBundle(ctx context.Context, u user.User, now time.Time) (string, error) {
+	doc, err := export.CollectAll(ctx, u, s.workoutRepository, s.metricRepository, s.auditRepository)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.cfg.ExportDir, 0o700); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(s.cfg.ExportDir, fmt.Sprintf("user-%d-%d-*.zip", u.ID, now.Unix()))
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+
+	if err := export.WriteArchive(f, doc); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (s *Service) removeBundle(path string) {
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.Errorf("Failed to remove export bundle %s: %v", path, err)
+	}
+}
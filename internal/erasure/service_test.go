@@ -0,0 +1,245 @@
+package erasure_test
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/audit"
+	auditMemory "fit-journal/internal/audit/memory"
+	"fit-journal/internal/config"
+	"fit-journal/internal/entities/metric"
+	metricMemory "fit-journal/internal/entities/metric/memory"
+	"fit-journal/internal/entities/user"
+	userMemory "fit-journal/internal/entities/user/memory"
+	"fit-journal/internal/entities/workout"
+	workoutMemory "fit-journal/internal/entities/workout/memory"
+	"fit-journal/internal/erasure"
+	erasureMemory "fit-journal/internal/erasure/memory"
+	"fit-journal/internal/export"
+	"fit-journal/pkg/logging"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const gracePeriod = 24 * time.Hour
+
+type fixture struct {
+	service  *erasure.Service
+	users    *userMemory.Repository
+	workouts *workoutMemory.Repository
+	metrics  *metricMemory.Repository
+	audit    *auditMemory.Repository
+	now      time.Time
+	erased   []int64 // Пользователи, переданные в OnErase
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		users:    userMemory.NewRepository(),
+		workouts: workoutMemory.NewRepository(),
+		metrics:  metricMemory.NewRepository(),
+		audit:    auditMemory.NewRepository(),
+		now:      time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	repo := erasureMemory.NewRepository(f.users, f.workouts, f.metrics, f.audit)
+	f.service = erasure.NewService(repo, f.workouts, f.metrics, f.audit, logging.GetLogger(),
+		config.ErasureConfig{GracePeriod: gracePeriod, CheckInterval: time.Hour, ExportDir: t.TempDir()})
+	f.service.SetNow(func() time.Time { return f.now })
+	f.service.OnErase(func(_ context.Context, userID int64) { f.erased = append(f.erased, userID) })
+	return f
+}
+
+// addUser создаёт пользователя с тренировкой и метрикой
+func (f *fixture) addUser(t *testing.T, username string) user.User {
+	t.Helper()
+	ctx := context.Background()
+	if err := f.users.Create(ctx, user.User{Username: username, PasswordHash: "hash"}); err != nil {
+		t.Fatal(err)
+	}
+	u, err := f.users.FindOne(ctx, username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.workouts.Create(ctx, workout.Workout{UserID: u.ID, StartTime: f.now.Unix(), Kind: workout.KindStrength}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.metrics.Create(ctx, metric.Metric{UserID: u.ID, Day: "2024-03-01", Weight: "80"}); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// hasData проверяет, что у пользователя остались учётная запись, тренировки и метрики
+func (f *fixture) hasData(t *testing.T, u user.User) bool {
+	t.Helper()
+	ctx := context.Background()
+	_, err := f.users.FindOne(ctx, u.Username)
+	workouts, _ := f.workouts.FindAllByUserID(ctx, u.ID)
+	metrics, _ := f.metrics.FindAllByUserID(ctx, u.ID)
+	return err == nil && len(workouts) > 0 && len(metrics) > 0
+}
+
+func TestScheduleAndCancel(t *testing.T) {
+	f := newFixture(t)
+	u := f.addUser(t, "alice")
+	ctx := context.Background()
+
+	req, err := f.service.Schedule(ctx, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !req.EraseAfter.Equal(f.now.Add(gracePeriod)) {
+		t.Errorf("EraseAfter = %s, want %s", req.EraseAfter, f.now.Add(gracePeriod))
+	}
+	if _, err := os.Stat(req.ExportPath); err != nil {
+		t.Fatalf("export bundle: %v", err)
+	}
+
+	// Повторный запрос не создаёт новый архив
+	f.now = f.now.Add(time.Hour)
+	again, err := f.service.Schedule(ctx, u)
+	if err != nil || again.ExportPath != req.ExportPath || !again.EraseAfter.Equal(req.EraseAfter) {
+		t.Fatalf("Schedule again = %+v, %v; want existing request", again, err)
+	}
+
+	if err := f.service.Cancel(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(req.ExportPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("export bundle was not removed: %v", err)
+	}
+	if _, err := f.service.Status(ctx, u.ID); !errors.Is(err, erasure.ErrNotFound) {
+		t.Errorf("Status after cancel = %v, want ErrNotFound", err)
+	}
+	if err := f.service.Cancel(ctx, u.ID); !errors.Is(err, erasure.ErrNotFound) {
+		t.Errorf("second Cancel = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		erased  bool
+	}{
+		{"grace period not expired", gracePeriod - time.Second, false},
+		{"grace period expires", gracePeriod, true},
+		{"long overdue", 3 * gracePeriod, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			alice := f.addUser(t, "alice")
+			bob := f.addUser(t, "bob")
+			ctx := context.Background()
+
+			req, err := f.service.Schedule(ctx, alice)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f.now = f.now.Add(tt.elapsed)
+			if err := f.service.RunDue(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if f.hasData(t, alice) == tt.erased {
+				t.Errorf("alice data kept = %v, want erased = %v", !tt.erased, tt.erased)
+			}
+			if !f.hasData(t, bob) {
+				t.Error("data of another user was erased")
+			}
+			if (len(f.erased) == 1 && f.erased[0] == alice.ID) != tt.erased {
+				t.Errorf("OnErase calls = %v", f.erased)
+			}
+			if _, err := os.Stat(req.ExportPath); errors.Is(err, os.ErrNotExist) != tt.erased {
+				t.Errorf("export bundle removed = %v, want %v", errors.Is(err, os.ErrNotExist), tt.erased)
+			}
+			if _, err := f.service.Status(ctx, alice.ID); errors.Is(err, erasure.ErrNotFound) != tt.erased {
+				t.Errorf("Status = %v", err)
+			}
+		})
+	}
+}
+
+func TestScheduleConcurrently(t *testing.T) {
+	f := newFixture(t)
+	u := f.addUser(t, "alice")
+
+	// Одновременные запросы на удаление получают один и тот же запрос, а не ошибку
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make([]erasure.Request, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = f.service.Schedule(context.Background(), u)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("Schedule %d: %v", i, errs[i])
+		}
+		if results[i].ExportPath != results[0].ExportPath || !results[i].EraseAfter.Equal(results[0].EraseAfter) {
+			t.Errorf("Schedule %d = %+v, want %+v", i, results[i], results[0])
+		}
+	}
+	// Архивы проигравших запросов удалены, архив запроса на месте
+	bundles, err := filepath.Glob(filepath.Join(filepath.Dir(results[0].ExportPath), "*.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundles) != 1 || bundles[0] != results[0].ExportPath {
+		t.Errorf("export bundles = %v, want only %s", bundles, results[0].ExportPath)
+	}
+}
+
+func TestBundleIncludesTrashAndAudit(t *testing.T) {
+	f := newFixture(t)
+	u := f.addUser(t, "alice")
+	ctx := context.Background()
+
+	trashed, err := f.workouts.Create(ctx, workout.Workout{UserID: u.ID, StartTime: f.now.Unix() - 3600, Kind: workout.KindStrength})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.workouts.Trash(ctx, trashed, f.now.Unix()); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.audit.Create(ctx, audit.Entry{At: f.now, Actor: "alice", OwnerID: u.ID, Resource: audit.ResourceWorkout, ResourceID: trashed, Action: audit.ActionDelete}); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := f.service.Schedule(ctx, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := os.Open(req.ExportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bundle.Close()
+	doc, err := export.ReadDocument(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inTrash bool
+	for _, w := range doc.Workouts {
+		inTrash = inTrash || (w.ID == trashed && w.DeletedAt != nil)
+	}
+	if len(doc.Workouts) != 2 || !inTrash {
+		t.Errorf("bundle workouts = %+v, want the active and the trashed workout", doc.Workouts)
+	}
+	if len(doc.Audit) != 1 || doc.Audit[0].ResourceID != trashed {
+		t.Errorf("bundle audit = %+v, want the owner's entry", doc.Audit)
+	}
+}
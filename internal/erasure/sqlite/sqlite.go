@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/erasure"
+	"fit-journal/pkg/client/sqlite"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Repository struct {
+	client sqlite.Client
+}
+
+// formatQuery убирает переносы строк и табуляции из SQL-запроса для удобства логирования
+func formatQuery(q string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
+}
+
+// Create сохраняет запрос на удаление
+func (r *Repository) Create(ctx context.Context, req erasure.Request) error {
+	q := `
+		INSERT INTO deletion_requests
+			(user_id, requested_at, erase_after, export_path)
+		VALUES
+			(?, ?, ?, ?)
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	if _, err := r.client.ExecContext(ctx, q, req.UserID, req.RequestedAt.Unix(), req.EraseAfter.Unix(), req.ExportPath); err != nil {
+		if sqlite.UniqueViolation(err) {
+			return apperror.Conflict("deletion request for user %d already exists", req.UserID)
+		}
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// FindByUserID возвращает запрос на удаление пользователя
+func (r *Repository) FindByUserID(ctx context.Context, userID int64) (erasure.Request, error) {
+	q := `
+		SELECT user_id, requested_at, erase_after, export_path FROM deletion_requests WHERE user_id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	var req erasure.Request
+	var requestedAt, eraseAfter int64
+	err := r.client.QueryRowContext(ctx, q, userID).Scan(&req.UserID, &requestedAt, &eraseAfter, &req.ExportPath)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return erasure.Request{}, erasure.ErrNotFound
+		}
+		return erasure.Request{}, err
+	}
+	req.RequestedAt, req.EraseAfter = time.Unix(requestedAt, 0), time.Unix(eraseAfter, 0)
+
+	return req, nil
+}
+
+// Delete отменяет запрос на удаление
+func (r *Repository) Delete(ctx context.Context, userID int64) error {
+	q := `
+		DELETE FROM deletion_requests WHERE user_id = ?
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	res, err := r.client.ExecContext(ctx, q, userID)
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return erasure.ErrNotFound
+	}
+
+	return nil
+}
+
+// FindDue возвращает запросы, срок ожидания которых истёк, по возрастанию ID пользователя
+func (r *Repository) FindDue(ctx context.Context, now time.Time) ([]erasure.Request, error) {
+	q := `
+		SELECT user_id, requested_at, erase_after, export_path FROM deletion_requests WHERE erase_after <= ?
+		ORDER BY user_id
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	rows, err := r.client.QueryContext(ctx, q, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]erasure.Request, 0)
+	for rows.Next() {
+		var req erasure.Request
+		var requestedAt, eraseAfter int64
+		if err := rows.Scan(&req.UserID, &requestedAt, &eraseAfter, &req.ExportPath); err != nil {
+			return nil, err
+		}
+		req.RequestedAt, req.EraseAfter = time.Unix(requestedAt, 0), time.Unix(eraseAfter, 0)
+		requests = append(requests, req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// EnqueueSoftDeleted создаёт запросы на удаление для аккаунтов с is_deleted = 1
+func (r *Repository) EnqueueSoftDeleted(ctx context.Context, requestedAt, eraseAfter time.Time) (int64, error) {
+	q := `
+		INSERT INTO deletion_requests (user_id, requested_at, erase_after, export_path)
+		SELECT id, ?, ?, '' FROM users WHERE is_deleted = 1
+		ON CONFLICT (user_id) DO NOTHING
+	`
+	logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+
+	res, err := r.client.ExecContext(ctx, q, requestedAt.Unix(), eraseAfter.Unix())
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// EraseUser удаляет метрики, тренировки, журнал аудита, запрос на удаление и самого пользователя в одной транзакции
+func (r *Repository) EraseUser(ctx context.Context, userID int64) error {
+	queries := []string{
+		`DELETE FROM metrics WHERE user_id = ?`,
+		`DELETE FROM workouts WHERE user_id = ?`,
+		`DELETE FROM audit_log WHERE owner_id = ?`,
+		`DELETE FROM deletion_requests WHERE user_id = ?`,
+		`DELETE FROM users WHERE id = ?`,
+	}
+
+	tx, err := r.client.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, q := range queries {
+		logging.FromContext(ctx).Trace(fmt.Sprintf("SQL Query: %s", formatQuery(q)))
+		if _, err := tx.ExecContext(ctx, q, userID); err != nil {
+			logging.FromContext(ctx).Error(err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// NewRepository создает новый экземпляр репозитория
+func NewRepository(client sqlite.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	auditSQLite "fit-journal/internal/audit/sqlite"
+	metricSQLite "fit-journal/internal/entities/metric/sqlite"
+	"fit-journal/internal/entities/storagetest"
+	userSQLite "fit-journal/internal/entities/user/sqlite"
+	workoutSQLite "fit-journal/internal/entities/workout/sqlite"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.RunErasure(t, func(t *testing.T) storagetest.Repositories {
+		client := storagetest.SQLite(t)
+		return storagetest.Repositories{
+			Users:    userSQLite.NewRepository(client),
+			Workouts: workoutSQLite.NewRepository(client),
+			Metrics:  metricSQLite.NewRepository(client),
+			Audit:    auditSQLite.NewRepository(client),
+			Erasure:  NewRepository(client),
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package erasure
+
+import (
+	"context"
+	"fit-journal/internal/apperror"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound запрос на удаление не найден; errors.Is также сопоставляет его с apperror.ErrNotFound
+var ErrNotFound = fmt.Errorf("deletion request %w", apperror.ErrNotFound)
+
+type Repository interface {
+	// Create сохраняет запрос; если у пользователя уже есть запрос, возвращает ошибку errors.Is apperror.ErrConflict
+	Create(ctx context.Context, req Request) error
+	FindByUserID(ctx context.Context, userID int64) (Request, error)
+	Delete(ctx context.Context, userID int64) error
+	// FindDue возвращает запросы с EraseAfter не позже now по возрастанию UserID
+	FindDue(ctx context.Context, now time.Time) ([]Request, error)
+	// EnqueueSoftDeleted ставит в очередь аккаунты, удалённые до появления запросов на удаление
+	EnqueueSoftDeleted(ctx context.Context, requestedAt, eraseAfter time.Time) (int64, error)
+	// EraseUser безвозвратно удаляет пользователя и все связанные с ним данные
+	EraseUser(ctx context.Context, userID int64) error
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/auth"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/internal/entities/user"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/internal/handlers"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+const (
+	exportURL = "/export"
+)
+
+type handler struct {
+	userRepository    user.Repository
+	workoutRepository workout.Repository
+	metricRepository  metric.Repository
+	auth              *auth.Service
+}
+
+func NewHandler(userRepo user.Repository, workoutRepo workout.Repository, metricRepo metric.Repository, authService *auth.Service) handlers.Handler {
+	return &handler{
+		userRepository:    userRepo,
+		workoutRepository: workoutRepo,
+		metricRepository:  metricRepo,
+		auth:              authService,
+	}
+}
+
+func (h *handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, exportURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.Export)))
+}
+
+// Collect собирает выгрузку всех данных пользователя
+func Collect(ctx context.Context, u user.User, workoutRepo workout.Repository, metricRepo metric.Repository) (Document, error) {
+	workouts, err := workoutRepo.FindAllByUserID(ctx, u.ID)
+	if err != nil {
+		return Document{}, err
+	}
+	for i := range workouts {
+		workouts[i] = workouts[i].WithoutTrashed()
+	}
+	metrics, err := metricRepo.FindAllByUserID(ctx, u.ID)
+	if err != nil {
+		return Document{}, err
+	}
+	return NewDocument(u, workouts, metrics, time.Now()), nil
+}
+
+// CollectAll собирает выгрузку для архива перед удалением аккаунта. В отличие от Collect,
+// в неё попадают тренировки, упражнения и подходы из корзины и журнал аудита пользователя.
+func CollectAll(ctx context.Context, u user.User, workoutRepo workout.Repository, metricRepo metric.Repository, auditRepo audit.Repository) (Document, error) {
+	workouts, err := workoutRepo.FindAllByUserID(ctx, u.ID)
+	if err != nil {
+		return Document{}, err
+	}
+	trashed, err := workoutRepo.FindTrashedByUserID(ctx, u.ID)
+	if err != nil {
+		return Document{}, err
+	}
+	metrics, err := metricRepo.FindAllByUserID(ctx, u.ID)
+	if err != nil {
+		return Document{}, err
+	}
+	entries, err := auditRepo.Find(ctx, audit.Filter{OwnerID: u.ID})
+	if err != nil {
+		return Document{}, err
+	}
+
+	doc := NewDocument(u, append(workouts, trashed...), metrics, time.Now())
+	doc.Audit = entries
+	return doc, nil
+}
+
+// Export отдаёт данные пользователя в формате json (по умолчанию), csv или zip.
+// CSV содержит один раздел выгрузки, выбранный параметром section: sets (по умолчанию) или metrics.
+// Профиль в CSV не выгружается, он есть в json и zip.
+func (h *handler) Export(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "zip" {
+		return apperror.NewAppError(nil, "Unsupported export format", "format must be json, csv or zip", http.StatusBadRequest)
+	}
+	section := query.Get("section")
+	switch {
+	case format != "csv" && section != "":
+		return apperror.NewAppError(nil, "The section parameter applies only to CSV export", "json and zip always contain all sections", http.StatusBadRequest)
+	case format == "csv" && section == "":
+		section = SectionSets
+	case format == "csv" && section != SectionSets && section != SectionMetrics:
+		return apperror.NewAppError(nil, fmt.Sprintf("Section %q is not available in CSV", section), "use section=sets or section=metrics, or format=json or zip for the profile", http.StatusBadRequest)
+	}
+
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		return apperror.NewAppError(nil, "Invalid or missing username", "", http.StatusUnauthorized)
+	}
+
+	u, err := h.userRepository.FindOne(r.Context(), username)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to fetch user", "", http.StatusInternalServerError)
+	}
+
+	doc, err := Collect(r.Context(), u, h.workoutRepository, h.metricRepository)
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err)
+		return apperror.NewAppError(err, "Failed to collect export data", "", http.StatusInternalServerError)
+	}
+
+	filename := fmt.Sprintf("fit-journal-%s-%s", u.Username, doc.ExportedAt.Format("20060102"))
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"-"+section+".csv"))
+		w.WriteHeader(http.StatusOK)
+		if section == SectionMetrics {
+			return WriteMetricsCSV(w, doc.Metrics)
+		}
+		return WriteSetsCSV(w, doc.Workouts)
+	case "zip":
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+		w.WriteHeader(http.StatusOK)
+		return WriteArchive(w, doc)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+		w.WriteHeader(http.StatusOK)
+		return json.NewEncoder(w).Encode(doc)
+	}
+}
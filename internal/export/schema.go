@@ -0,0 +1,286 @@
+// Package export выгружает данные пользователя в переносимом виде.
+//
+// Формат JSON (schema_version 1):
+//
+//	{
+//	  "schema_version": 1,
+//	  "exported_at": "2024-03-04T18:30:00Z",
+//	  "profile":  {"username": "...", "birth_date": "2006-01-02", "height": "..."},
+//	  "workouts": [Workout, ...],  // как в ответах /workouts: упражнения, подходы, группы, кардио
+//	  "metrics":  [Metric, ...],   // как в модели metric.Metric
+//	  "audit":    [Entry, ...]     // как в модели audit.Entry, только в архиве перед удалением аккаунта
+//	}
+//
+// GET /export выгружает только активные данные. Архив перед удалением аккаунта (см. CollectAll)
+// содержит и корзину: тренировки, упражнения и подходы из неё отмечены полем deleted_at.
+//
+// CSV содержит один раздел выгрузки. Раздел sets — одна строка на подход (колонки SetColumns),
+// кардио-тренировки и тренировки без подходов представлены одной строкой с пустыми колонками подхода.
+// Раздел metrics — одна строка на метрику (колонки MetricColumns). Профиль в CSV не выгружается.
+//
+// Архив zip содержит export.json, sets.csv и metrics.csv. Журнал аудита есть только в export.json.
+//
+// Поля только добавляются; при удалении или изменении смысла поля SchemaVersion увеличивается,
+// а импорт продолжает принимать все версии не выше текущей.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/internal/entities/user"
+	"fit-journal/internal/entities/workout"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// SchemaVersion текущая версия формата выгрузки
+const SchemaVersion = 1
+
+// Profile данные профиля без служебных полей и хэша пароля
+type Profile struct {
+	Username  string `json:"username"`
+	BirthDate string `json:"birth_date,omitempty"`
+	Height    string `json:"height,omitempty"`
+}
+
+// Document полная выгрузка данных пользователя
+type Document struct {
+	SchemaVersion int               `json:"schema_version"`
+	ExportedAt    time.Time         `json:"exported_at"`
+	Profile       Profile           `json:"profile"`
+	Workouts      []workout.Workout `json:"workouts"`
+	Metrics       []metric.Metric   `json:"metrics"`
+	Audit         []audit.Entry     `json:"audit,omitempty"` // Журнал изменений данных пользователя, см. CollectAll
+}
+
+// NewDocument собирает выгрузку из данных пользователя
+func NewDocument(u user.User, workouts []workout.Workout, metrics []metric.Metric, now time.Time) Document {
+	if workouts == nil {
+		workouts = []workout.Workout{}
+	}
+	if metrics == nil {
+		metrics = []metric.Metric{}
+	}
+	return Document{
+		SchemaVersion: SchemaVersion,
+		ExportedAt:    now.UTC(),
+		Profile:       Profile{Username: u.Username, BirthDate: u.BirthDate, Height: u.Height},
+		Workouts:      workouts,
+		Metrics:       metrics,
+	}
+}
+
+// Разделы выгрузки в формате CSV
+const (
+	SectionSets    = "sets"
+	SectionMetrics = "metrics"
+)
+
+// SetColumns колонки CSV с подходами
+var SetColumns = []string{
+	"schema_version", "workout_id", "start_time", "kind",
+	"group_id", "group_type", "exercise_id", "catalog_id", "exercise_name",
+	"set_id", "set_index", "reps", "weight",
+	"activity", "distance_meters", "moving_time_sec", "elevation_gain_m", "avg_hr", "max_hr",
+	"deleted_at",
+}
+
+// MetricColumns колонки CSV с метриками
+var MetricColumns = []string{"schema_version", "metric_id", "day", "weight", "calories_consumed"}
+
+func formatInt(v int64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// formatDeletedAt возвращает время перемещения в корзину первого из переданных объектов, который в ней находится
+func formatDeletedAt(deletedAt ...*int64) string {
+	for _, at := range deletedAt {
+		if at != nil {
+			return time.Unix(*at, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+func formatFloat(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// WriteSetsCSV пишет тренировки в CSV, по строке на подход
+func WriteSetsCSV(w io.Writer, workouts []workout.Workout) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(SetColumns); err != nil {
+		return err
+	}
+
+	version := strconv.Itoa(SchemaVersion)
+	for _, wk := range workouts {
+		base := []string{
+			version,
+			strconv.FormatInt(wk.ID, 10),
+			time.Unix(wk.StartTime, 0).UTC().Format(time.RFC3339),
+			string(wk.Kind),
+		}
+		cardio := make([]string, 6)
+		if wk.Cardio != nil {
+			c := wk.Cardio
+			cardio = []string{
+				string(c.Activity), formatFloat(c.DistanceMeters), formatInt(c.MovingTimeSec),
+				formatFloat(c.ElevationGainM), formatInt(int64(c.AvgHR)), formatInt(int64(c.MaxHR)),
+			}
+		}
+
+		rows := 0
+		for _, ex := range wk.Exercises {
+			var groupID, groupType string
+			if g, ok := wk.GroupOf(ex.ID); ok {
+				groupID, groupType = strconv.FormatInt(g.ID, 10), string(g.Type)
+			}
+			for i, set := range ex.Sets {
+				row := append(append([]string{}, base...),
+					groupID, groupType,
+					strconv.FormatInt(ex.ID, 10), formatInt(ex.CatalogID), ex.Name,
+					strconv.FormatInt(set.ID, 10), strconv.Itoa(i+1), strconv.Itoa(set.Reps), strconv.FormatFloat(set.Weight, 'f', -1, 64),
+				)
+				if err := cw.Write(append(append(row, cardio...), formatDeletedAt(set.DeletedAt, ex.DeletedAt, wk.DeletedAt))); err != nil {
+					return err
+				}
+				rows++
+			}
+		}
+		if rows == 0 {
+			row := append(append([]string{}, base...), make([]string, 9)...)
+			if err := cw.Write(append(append(row, cardio...), formatDeletedAt(wk.DeletedAt))); err != nil {
+				return err
+			}
+		}
+
+		// Сбрасываем буфер после каждой тренировки, чтобы ответ уходил клиенту по частям
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMetricsCSV пишет метрики в CSV
+func WriteMetricsCSV(w io.Writer, metrics []metric.Metric) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(MetricColumns); err != nil {
+		return err
+	}
+
+	version := strconv.Itoa(SchemaVersion)
+	for _, m := range metrics {
+		if err := cw.Write([]string{version, strconv.FormatInt(m.ID, 10), m.Day, m.Weight, m.CaloriesConsumed}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Имена файлов внутри архива выгрузки
+const (
+	ArchiveJSON       = "export.json"
+	ArchiveSetsCSV    = "sets.csv"
+	ArchiveMetricsCSV = "metrics.csv"
+)
+
+// WriteArchive пишет zip-архив с JSON-документом и CSV-файлами
+func WriteArchive(w io.Writer, doc Document) error {
+	zw := zip.NewWriter(w)
+
+	f, err := zw.Create(ArchiveJSON)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(doc); err != nil {
+		return err
+	}
+
+	if f, err = zw.Create(ArchiveSetsCSV); err != nil {
+		return err
+	}
+	if err := WriteSetsCSV(f, doc.Workouts); err != nil {
+		return err
+	}
+
+	if f, err = zw.Create(ArchiveMetricsCSV); err != nil {
+		return err
+	}
+	if err := WriteMetricsCSV(f, doc.Metrics); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// MaxDocumentSize наибольший размер JSON-документа, в том числе распакованного из архива
+const MaxDocumentSize = 128 << 20
+
+// ErrDocumentTooLarge документ больше MaxDocumentSize
+var ErrDocumentTooLarge = fmt.Errorf("export document exceeds %d bytes", MaxDocumentSize)
+
+// readLimited читает не больше MaxDocumentSize байт, иначе возвращает ErrDocumentTooLarge
+func readLimited(r io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, MaxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > MaxDocumentSize {
+		return nil, ErrDocumentTooLarge
+	}
+	return content, nil
+}
+
+// ReadDocument читает выгрузку из JSON или zip-архива, созданного WriteArchive.
+// Документ больше MaxDocumentSize, в том числе после распаковки, отклоняется с ErrDocumentTooLarge.
+func ReadDocument(r io.Reader) (Document, error) {
+	content, err := readLimited(r)
+	if err != nil {
+		return Document{}, err
+	}
+
+	if bytes.HasPrefix(content, []byte("PK\x03\x04")) {
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return Document{}, fmt.Errorf("invalid archive: %w", err)
+		}
+		f, err := zr.Open(ArchiveJSON)
+		if err != nil {
+			return Document{}, fmt.Errorf("archive does not contain %s: %w", ArchiveJSON, err)
+		}
+		defer f.Close()
+		// Размер в заголовке архива не проверяется: он может не совпадать с распакованными данными
+		if content, err = readLimited(f); err != nil {
+			return Document{}, err
+		}
+	}
+
+	var doc Document
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return Document{}, fmt.Errorf("invalid export document: %w", err)
+	}
+	if doc.SchemaVersion < 1 || doc.SchemaVersion > SchemaVersion {
+		return Document{}, fmt.Errorf("unsupported schema version %d", doc.SchemaVersion)
+	}
+
+	return doc, nil
+}
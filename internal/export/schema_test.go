@@ -0,0 +1,187 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/internal/entities/user"
+	"fit-journal/internal/entities/workout"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// strength силовая тренировка с суперсетом из двух упражнений
+var strength = workout.Workout{
+	ID:        1,
+	UserID:    7,
+	StartTime: 1700000000,
+	Kind:      workout.KindStrength,
+	Exercises: []exercise.Exercise{
+		{ID: 10, CatalogID: 3, Name: "Squat", Sets: []exercise.ExerciseSet{{ID: 100, Reps: 5, Weight: 102.5}, {ID: 101, Reps: 3, Weight: 110}}},
+		{ID: 11, Name: "Lunge, walking", Sets: []exercise.ExerciseSet{{ID: 110, Reps: 8}}},
+	},
+	Groups: []exercise.Group{{ID: 20, Type: exercise.GroupSuperset, ExerciseIDs: []int64{10, 11}}},
+}
+
+// run кардио-тренировка без упражнений
+var run = workout.Workout{
+	ID:        2,
+	UserID:    7,
+	StartTime: 1700086400,
+	Kind:      workout.KindCardio,
+	Exercises: []exercise.Exercise{},
+	Groups:    []exercise.Group{},
+	Cardio:    &workout.Cardio{Activity: workout.ActivityRun, DistanceMeters: 5000, MovingTimeSec: 1500, AvgHR: 150},
+}
+
+// trashedItems тренировка strength со вторым подходом приседа и выпадами в корзине
+func trashedItems() workout.Workout {
+	setDeleted, exerciseDeleted := int64(1700002800), int64(1700006400)
+	w := strength
+	w.Exercises = []exercise.Exercise{strength.Exercises[0], strength.Exercises[1]}
+	w.Exercises[0].Sets = []exercise.ExerciseSet{strength.Exercises[0].Sets[0], strength.Exercises[0].Sets[1]}
+	w.Exercises[0].Sets[1].DeletedAt = &setDeleted
+	w.Exercises[1].DeletedAt = &exerciseDeleted
+	return w
+}
+
+func readCSV(t *testing.T, data string) [][]string {
+	t.Helper()
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v\n%s", err, data)
+	}
+	return records
+}
+
+func TestWriteSetsCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		workouts []workout.Workout
+		want     [][]string
+	}{
+		{"no workouts", nil, nil},
+		{"strength with superset", []workout.Workout{strength}, [][]string{
+			{"1", "1", "2023-11-14T22:13:20Z", "strength", "20", "superset", "10", "3", "Squat", "100", "1", "5", "102.5", "", "", "", "", "", "", ""},
+			{"1", "1", "2023-11-14T22:13:20Z", "strength", "20", "superset", "10", "3", "Squat", "101", "2", "3", "110", "", "", "", "", "", "", ""},
+			{"1", "1", "2023-11-14T22:13:20Z", "strength", "20", "superset", "11", "", "Lunge, walking", "110", "1", "8", "0", "", "", "", "", "", "", ""},
+		}},
+		// Кардио без подходов выгружается одной строкой с пустыми колонками подхода
+		{"cardio", []workout.Workout{run}, [][]string{
+			{"1", "2", "2023-11-15T22:13:20Z", "cardio", "", "", "", "", "", "", "", "", "", "run", "5000", "1500", "", "150", "", ""},
+		}},
+		// Подход из корзины отмечен своим временем удаления, подходы упражнения из корзины — временем упражнения
+		{"trashed items", []workout.Workout{trashedItems()}, [][]string{
+			{"1", "1", "2023-11-14T22:13:20Z", "strength", "20", "superset", "10", "3", "Squat", "100", "1", "5", "102.5", "", "", "", "", "", "", ""},
+			{"1", "1", "2023-11-14T22:13:20Z", "strength", "20", "superset", "10", "3", "Squat", "101", "2", "3", "110", "", "", "", "", "", "", "2023-11-14T23:00:00Z"},
+			{"1", "1", "2023-11-14T22:13:20Z", "strength", "20", "superset", "11", "", "Lunge, walking", "110", "1", "8", "0", "", "", "", "", "", "", "2023-11-15T00:00:00Z"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSetsCSV(&buf, tt.workouts); err != nil {
+				t.Fatal(err)
+			}
+			records := readCSV(t, buf.String())
+			if !reflect.DeepEqual(records[0], SetColumns) {
+				t.Errorf("header = %v, want %v", records[0], SetColumns)
+			}
+			if got := records[1:]; len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("rows =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMetricsCSV(t *testing.T) {
+	metrics := []metric.Metric{
+		{ID: 1, UserID: 7, Day: "2024-03-01", Weight: "80.5"},
+		{ID: 2, UserID: 7, Day: "2024-03-02", CaloriesConsumed: "2500"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMetricsCSV(&buf, metrics); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		MetricColumns,
+		{"1", "1", "2024-03-01", "80.5", ""},
+		{"1", "2", "2024-03-02", "", "2500"},
+	}
+	if got := readCSV(t, buf.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	u := user.User{ID: 7, Username: "alice", PasswordHash: "hash", BirthDate: "1990-05-01"}
+	doc := NewDocument(u, []workout.Workout{strength, run}, []metric.Metric{{ID: 1, UserID: 7, Day: "2024-03-01", Weight: "80"}},
+		time.Date(2024, 3, 4, 18, 30, 0, 0, time.UTC))
+
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if want := []string{ArchiveJSON, ArchiveSetsCSV, ArchiveMetricsCSV}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("archive files = %v, want %v", names, want)
+	}
+
+	// Хэш пароля в выгрузку не попадает
+	raw, err := zr.Open(ArchiveJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(raw)
+	raw.Close()
+	if bytes.Contains(content, []byte("hash")) {
+		t.Errorf("export contains the password hash: %s", content)
+	}
+
+	// Архив и JSON читаются обратно в тот же документ
+	fromJSON, _ := json.Marshal(doc)
+	for name, data := range map[string][]byte{"zip": buf.Bytes(), "json": fromJSON} {
+		got, err := ReadDocument(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("ReadDocument(%s): %v", name, err)
+		}
+		if !reflect.DeepEqual(got, doc) {
+			t.Errorf("ReadDocument(%s) = %+v, want %+v", name, got, doc)
+		}
+	}
+}
+
+func TestReadDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"not json", "schema_version,workout_id"},
+		{"missing version", `{"workouts": []}`},
+		{"future version", `{"schema_version": 2}`},
+		{"broken archive", "PK\x03\x04broken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadDocument(strings.NewReader(tt.body)); err == nil {
+				t.Error("ReadDocument succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/pkg/validate"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Decode читает из тела запроса ровно одно JSON-значение в dst и проверяет его по тегам validate
+// (см. пакет validate). Неизвестные поля, поля неверного типа и нарушения правил возвращаются
+// как apperror.ValidationErrors, синтаксические ошибки — как apperror.ErrValidation.
+// Пустое тело возвращает io.EOF, превышение лимита тела — *http.MaxBytesError.
+func Decode(r *http.Request, dst interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return err
+		}
+		return apperror.Validation("request body must contain a single JSON value")
+	}
+
+	var violations validate.Errors
+	if err := validate.Struct(dst); errors.As(err, &violations) {
+		fields := make(apperror.ValidationErrors, len(violations))
+		for i, v := range violations {
+			fields[i] = apperror.FieldError{Field: v.Field, Message: v.Message}
+		}
+		return fields
+	}
+	return nil
+}
+
+// decodeError переводит ошибку json.Decoder в доменную ошибку
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, io.EOF), errors.As(err, &maxBytesErr):
+		return err
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperror.Validation("request body contains truncated JSON")
+	case errors.As(err, &syntaxErr):
+		return apperror.Validation("request body contains malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return apperror.Validation("request body must be %s", jsonType(typeErr.Type.Kind()))
+		}
+		return apperror.ValidationErrors{{Field: typeErr.Field, Message: "must be " + jsonType(typeErr.Type.Kind())}}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json не экспортирует тип этой ошибки
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperror.ValidationErrors{{Field: field, Message: "is not allowed"}}
+	}
+	return err
+}
+
+// jsonType называет тип JSON, соответствующий виду типа Go
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	}
+	return "a number"
+}
@@ -0,0 +1,154 @@
+// Package health отдаёт состояние процесса для оркестратора: /healthz (процесс жив)
+// и /readyz (зависимости доступны, можно принимать трафик).
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fit-journal/pkg/logging"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	livenessURL  = "/healthz"
+	readinessURL = "/readyz"
+
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// errShuttingDown причина неготовности во время остановки сервера
+var errShuttingDown = errors.New("server is shutting down")
+
+// Причины неудачной проверки в ответе /readyz. Текст ошибки зависимости может раскрывать
+// адреса и учётные данные, поэтому он пишется только в лог.
+const (
+	reasonTimeout = "check timed out"
+	reasonFailed  = "check failed"
+)
+
+// CheckFunc проверяет одну зависимость; контекст ограничен таймаутом проверки
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      CheckFunc
+}
+
+// CheckResult результат проверки одной зависимости
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report ответ /readyz
+type Report struct {
+	Status string                 `json:"status"`
+	Error  string                 `json:"error,omitempty"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker хранит проверки готовности и признак остановки сервера
+type Checker struct {
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+// NewChecker создаёт пустой набор проверок
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add регистрирует проверку зависимости с собственным таймаутом
+func (c *Checker) Add(name string, timeout time.Duration, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, timeout: timeout, fn: fn})
+}
+
+// SetShuttingDown переводит /readyz в состояние unavailable, чтобы балансировщик перестал слать запросы
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Check выполняет все проверки параллельно
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	if c.shuttingDown.Load() {
+		report.Status, report.Error = StatusUnavailable, errShuttingDown.Error()
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			result := run(ctx, chk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[chk.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(chk)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func run(ctx context.Context, chk check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, chk.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := chk.fn(ctx)
+	result := CheckResult{Status: StatusOK, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		logging.FromContext(ctx).
+			GetLoggerWithField("check", chk.name).
+			WithError(err).
+			Warn("readiness check failed")
+
+		result.Status, result.Error = StatusUnavailable, reasonFailed
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = reasonTimeout
+		}
+	}
+	return result
+}
+
+// Register подключает /healthz и /readyz; они не требуют авторизации
+func (c *Checker) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, livenessURL, c.Liveness)
+	router.HandlerFunc(http.MethodGet, readinessURL, c.Readiness)
+}
+
+// Liveness отвечает 200, пока процесс способен обрабатывать запросы
+func (c *Checker) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": StatusOK})
+}
+
+// Readiness отвечает 200, если все зависимости доступны, иначе 503 со статусами проверок
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
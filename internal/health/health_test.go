@@ -0,0 +1,126 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ok(context.Context) error { return nil }
+
+func failing(context.Context) error {
+	return errors.New("dial tcp 10.0.0.5:5432: password authentication failed for user \"app\"")
+}
+
+func hanging(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name         string
+		checks       map[string]CheckFunc
+		shuttingDown bool
+		wantStatus   int
+		wantReport   string
+		wantChecks   map[string]CheckResult // LatencyMS не сравнивается
+		wantError    string
+	}{
+		{"no checks", nil, false, http.StatusOK, StatusOK, map[string]CheckResult{}, ""},
+		{"all ok", map[string]CheckFunc{"database": ok, "migrations": ok}, false, http.StatusOK, StatusOK, map[string]CheckResult{
+			"database":   {Status: StatusOK},
+			"migrations": {Status: StatusOK},
+		}, ""},
+		// Подробности ошибки не попадают в ответ
+		{"failed check", map[string]CheckFunc{"database": failing, "migrations": ok}, false, http.StatusServiceUnavailable, StatusUnavailable, map[string]CheckResult{
+			"database":   {Status: StatusUnavailable, Error: reasonFailed},
+			"migrations": {Status: StatusOK},
+		}, ""},
+		{"timeout", map[string]CheckFunc{"database": hanging}, false, http.StatusServiceUnavailable, StatusUnavailable, map[string]CheckResult{
+			"database": {Status: StatusUnavailable, Error: reasonTimeout},
+		}, ""},
+		{"shutting down", map[string]CheckFunc{"database": ok}, true, http.StatusServiceUnavailable, StatusUnavailable, map[string]CheckResult{
+			"database": {Status: StatusOK},
+		}, errShuttingDown.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker()
+			for name, fn := range tt.checks {
+				c.Add(name, 20*time.Millisecond, fn)
+			}
+			if tt.shuttingDown {
+				c.SetShuttingDown()
+			}
+			router := httprouter.New()
+			c.Register(router)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", readinessURL, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if strings.Contains(w.Body.String(), "password") {
+				t.Errorf("response leaks the check error: %s", w.Body)
+			}
+			var report Report
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Status != tt.wantReport || report.Error != tt.wantError || len(report.Checks) != len(tt.wantChecks) {
+				t.Fatalf("report = %+v", report)
+			}
+			for name, want := range tt.wantChecks {
+				got := report.Checks[name]
+				got.LatencyMS = 0
+				if got != want {
+					t.Errorf("check %s = %+v, want %+v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestChecksRunInParallel(t *testing.T) {
+	c := NewChecker()
+	for _, name := range []string{"a", "b", "c"} {
+		c.Add(name, 100*time.Millisecond, hanging)
+	}
+
+	start := time.Now()
+	report := c.Check(context.Background())
+	// Последовательно проверки заняли бы 300 мс
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Check took %s", elapsed)
+	}
+	if report.Status != StatusUnavailable || len(report.Checks) != 3 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	c := NewChecker()
+	c.Add("database", time.Second, failing)
+	c.SetShuttingDown()
+	router := httprouter.New()
+	c.Register(router)
+
+	// Живость не зависит от зависимостей и остановки
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", livenessURL, nil))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"status":"ok"}` {
+		t.Errorf("liveness = %d %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+}
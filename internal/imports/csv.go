@@ -0,0 +1,280 @@
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source приложение, из которого выгружен CSV
+type Source string
+
+const (
+	SourceStrong   Source = "strong"
+	SourceHevy     Source = "hevy"
+	SourceFitNotes Source = "fitnotes"
+)
+
+const lbsToKg = 0.45359237
+
+// parsedSet подход из внешнего журнала
+type parsedSet struct {
+	Reps   int
+	Weight float64 // Всегда в килограммах
+}
+
+// parsedExercise упражнение из внешнего журнала в порядке появления в тренировке
+type parsedExercise struct {
+	Name       string
+	SupersetID string // Идентификатор суперсета (Hevy), пусто если упражнение вне группы
+	Sets       []parsedSet
+}
+
+// parsedWorkout тренировка, собранная из строк CSV
+type parsedWorkout struct {
+	Start     time.Time
+	Title     string
+	Exercises []parsedExercise
+}
+
+// exercise возвращает упражнение тренировки по имени, создавая его при первом обращении
+func (w *parsedWorkout) exercise(name, supersetID string) *parsedExercise {
+	for i := range w.Exercises {
+		if w.Exercises[i].Name == name {
+			return &w.Exercises[i]
+		}
+	}
+	w.Exercises = append(w.Exercises, parsedExercise{Name: name, SupersetID: supersetID})
+	return &w.Exercises[len(w.Exercises)-1]
+}
+
+// csvTable CSV-файл с доступом к колонкам по имени заголовка
+type csvTable struct {
+	columns map[string]int
+	rows    [][]string
+}
+
+func readCSV(r io.Reader) (*csvTable, error) {
+	br := bufio.NewReader(r)
+
+	// Strong в некоторых локалях выгружает CSV с разделителем ";"
+	head, err := br.Peek(4096)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	firstLine := head
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		firstLine = head[:i]
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("csv file is empty")
+	}
+
+	t := &csvTable{columns: make(map[string]int), rows: records[1:]}
+	for i, name := range records[0] {
+		name = strings.TrimPrefix(name, "\ufeff")
+		t.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return t, nil
+}
+
+// has проверяет наличие колонки
+func (t *csvTable) has(name string) bool {
+	_, ok := t.columns[name]
+	return ok
+}
+
+// get возвращает значение первой найденной колонки из списка
+func (t *csvTable) get(row []string, names ...string) string {
+	for _, name := range names {
+		if i, ok := t.columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+	}
+	return ""
+}
+
+func (t *csvTable) require(names ...string) error {
+	for _, name := range names {
+		if !t.has(name) {
+			return fmt.Errorf("csv column %q is missing", name)
+		}
+	}
+	return nil
+}
+
+func parseNumber(s string) float64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
+
+// ParseCSV разбирает выгрузку истории тренировок из Strong, Hevy или FitNotes.
+// weightInLbs используется для Strong, где единица веса не указана в заголовке.
+func ParseCSV(r io.Reader, source Source, weightInLbs bool) ([]parsedWorkout, error) {
+	t, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch source {
+	case SourceStrong:
+		return parseStrong(t, weightInLbs)
+	case SourceHevy:
+		return parseHevy(t)
+	case SourceFitNotes:
+		return parseFitNotes(t)
+	default:
+		return nil, fmt.Errorf("unknown source %q", source)
+	}
+}
+
+// collector группирует строки CSV в тренировки по ключу, сохраняя порядок
+type collector struct {
+	byKey    map[string]*parsedWorkout
+	workouts []*parsedWorkout
+}
+
+func newCollector() *collector {
+	return &collector{byKey: make(map[string]*parsedWorkout)}
+}
+
+func (c *collector) workout(key string, start time.Time, title string) *parsedWorkout {
+	if w, ok := c.byKey[key]; ok {
+		return w
+	}
+	w := &parsedWorkout{Start: start, Title: title}
+	c.byKey[key] = w
+	c.workouts = append(c.workouts, w)
+	return w
+}
+
+func (c *collector) result() []parsedWorkout {
+	result := make([]parsedWorkout, 0, len(c.workouts))
+	for _, w := range c.workouts {
+		if len(w.Exercises) > 0 {
+			result = append(result, *w)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result
+}
+
+// parseStrong: Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE
+func parseStrong(t *csvTable, weightInLbs bool) ([]parsedWorkout, error) {
+	if err := t.require("date", "exercise name", "reps"); err != nil {
+		return nil, err
+	}
+
+	c := newCollector()
+	for _, row := range t.rows {
+		name := t.get(row, "exercise name")
+		if name == "" || strings.EqualFold(t.get(row, "set order"), "rest timer") {
+			continue
+		}
+		dateStr := t.get(row, "date")
+		start, err := time.Parse("2006-01-02 15:04:05", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: %w", dateStr, err)
+		}
+
+		weight := parseNumber(t.get(row, "weight"))
+		if weightInLbs {
+			weight = math.Round(weight*lbsToKg*100) / 100
+		}
+
+		w := c.workout(dateStr, start, t.get(row, "workout name"))
+		ex := w.exercise(name, "")
+		ex.Sets = append(ex.Sets, parsedSet{Reps: int(parseNumber(t.get(row, "reps"))), Weight: weight})
+	}
+
+	return c.result(), nil
+}
+
+// parseHevy: title,start_time,end_time,description,exercise_title,superset_id,exercise_notes,set_index,set_type,weight_kg|weight_lbs,reps,...
+func parseHevy(t *csvTable) ([]parsedWorkout, error) {
+	if err := t.require("start_time", "exercise_title", "reps"); err != nil {
+		return nil, err
+	}
+	inLbs := t.has("weight_lbs") && !t.has("weight_kg")
+
+	c := newCollector()
+	for _, row := range t.rows {
+		name := t.get(row, "exercise_title")
+		if name == "" {
+			continue
+		}
+		startStr := t.get(row, "start_time")
+		start, err := time.Parse("2 Jan 2006, 15:04", startStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_time %q: %w", startStr, err)
+		}
+
+		weight := parseNumber(t.get(row, "weight_kg", "weight_lbs"))
+		if inLbs {
+			weight = math.Round(weight*lbsToKg*100) / 100
+		}
+
+		w := c.workout(startStr, start, t.get(row, "title"))
+		ex := w.exercise(name, t.get(row, "superset_id"))
+		ex.Sets = append(ex.Sets, parsedSet{Reps: int(parseNumber(t.get(row, "reps"))), Weight: weight})
+	}
+
+	return c.result(), nil
+}
+
+// parseFitNotes: Date,Exercise,Category,Weight (kgs)|Weight (lbs),Reps,Distance,Distance Unit,Time,Comment
+func parseFitNotes(t *csvTable) ([]parsedWorkout, error) {
+	if err := t.require("date", "exercise", "reps"); err != nil {
+		return nil, err
+	}
+	inLbs := t.has("weight (lbs)") && !t.has("weight (kgs)")
+
+	c := newCollector()
+	for _, row := range t.rows {
+		name := t.get(row, "exercise")
+		if name == "" {
+			continue
+		}
+		// FitNotes хранит только дату, поэтому все подходы за день — одна тренировка
+		dateStr := t.get(row, "date")
+		start, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: %w", dateStr, err)
+		}
+
+		weight := parseNumber(t.get(row, "weight (kgs)", "weight (lbs)", "weight"))
+		if inLbs {
+			weight = math.Round(weight*lbsToKg*100) / 100
+		}
+
+		w := c.workout(dateStr, start, "")
+		ex := w.exercise(name, "")
+		ex.Sets = append(ex.Sets, parsedSet{Reps: int(parseNumber(t.get(row, "reps"))), Weight: weight})
+	}
+
+	return c.result(), nil
+}
@@ -0,0 +1,114 @@
+package imports
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseCSVFixture(t *testing.T, name string, source Source, weightInLbs bool) []parsedWorkout {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	workouts, err := ParseCSV(f, source, weightInLbs)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return workouts
+}
+
+// summary сводит тренировки к строкам вида "Squat[]: 5x45.36 5x45.36" для сравнения
+func summary(workouts []parsedWorkout) []string {
+	var lines []string
+	for _, w := range workouts {
+		lines = append(lines, w.Start.Format(time.DateTime)+" "+w.Title)
+		for _, ex := range w.Exercises {
+			sets := make([]string, len(ex.Sets))
+			for i, s := range ex.Sets {
+				sets[i] = fmt.Sprintf("%dx%g", s.Reps, s.Weight)
+			}
+			lines = append(lines, fmt.Sprintf("%s[%s]: %s", ex.Name, ex.SupersetID, strings.Join(sets, " ")))
+		}
+	}
+	return lines
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		file        string
+		source      Source
+		weightInLbs bool
+		want        []string
+	}{
+		{
+			// Разделитель ";", BOM, строки таймера отдыха и вес в фунтах; тренировки сортируются по времени
+			file: "strong.csv", source: SourceStrong, weightInLbs: true,
+			want: []string{
+				"2024-03-01 09:30:00 Push",
+				"Bench Press[]: 8x61.23",
+				"Overhead Press[]: 6x43.32",
+				"2024-03-02 18:00:00 Legs",
+				"Squat[]: 5x45.36 5x45.36",
+			},
+		},
+		{
+			// Подходы одного упражнения собираются вместе, суперсет сохраняется
+			file: "hevy.csv", source: SourceHevy,
+			want: []string{
+				"2024-04-05 07:15:00 Upper",
+				"Pull Up[0]: 10x0 8x0",
+				"Dip[0]: 12x10",
+				"Barbell Row[]: 10x60.5",
+			},
+		},
+		{
+			// Единица веса берётся из заголовка, все подходы за день — одна тренировка
+			file: "fitnotes.csv", source: SourceFitNotes,
+			want: []string{
+				"2024-06-10 00:00:00 ",
+				"Deadlift[]: 5x102.06 5x102.06",
+				"Plank[]: 0x0",
+				"2024-06-11 00:00:00 ",
+				"Curl[]: 12x11.34",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got := summary(parseCSVFixture(t, tt.file, tt.source, tt.weightInLbs))
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ParseCSV =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source Source
+		input  string
+		want   string
+	}{
+		{"empty", SourceStrong, "", "csv file is empty"},
+		{"missing column", SourceHevy, "title,start_time,reps\n", `csv column "exercise_title" is missing`},
+		{"invalid date", SourceFitNotes, "Date,Exercise,Reps\n10.06.2024,Curl,12\n", `invalid date "10.06.2024"`},
+		{"unknown source", Source("jefit"), "Date\n", `unknown source "jefit"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.input), tt.source, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseCSV error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
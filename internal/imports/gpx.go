@@ -0,0 +1,66 @@
+package imports
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type gpxFile struct {
+	Tracks []struct {
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat        float64  `xml:"lat,attr"`
+	Lon        float64  `xml:"lon,attr"`
+	Elevation  *float64 `xml:"ele"`
+	Time       string   `xml:"time"`
+	Extensions struct {
+		// Garmin TrackPointExtension; пространство имён не учитывается, поэтому подходят gpxtpx:, ns3: и т.п.
+		TrackPoint struct {
+			HR int `xml:"hr"`
+		} `xml:"TrackPointExtension"`
+	} `xml:"extensions"`
+}
+
+// ParseGPX разбирает GPX-файл с треком активности
+func ParseGPX(r io.Reader) (Track, error) {
+	var f gpxFile
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return Track{}, fmt.Errorf("invalid gpx: %w", err)
+	}
+
+	var track Track
+	for _, trk := range f.Tracks {
+		if track.Activity == "" && trk.Type != "" {
+			track.Activity = activityFromSport(trk.Type)
+		}
+		for _, seg := range trk.Segments {
+			for _, p := range seg.Points {
+				ts, err := time.Parse(time.RFC3339, p.Time)
+				if err != nil {
+					continue
+				}
+				track.Points = append(track.Points, Trackpoint{
+					Time:      ts,
+					Lat:       p.Lat,
+					Lon:       p.Lon,
+					HasPos:    true,
+					Elevation: p.Elevation,
+					HR:        p.Extensions.TrackPoint.HR,
+				})
+			}
+		}
+	}
+	if track.Activity == "" {
+		track.Activity = activityFromSport("")
+	}
+
+	return track, nil
+}
@@ -0,0 +1,584 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/auth"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/internal/entities/user"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/internal/export"
+	"fit-journal/internal/handlers"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/metrics"
+	"fit-journal/pkg/uow"
+	"fit-journal/pkg/validate"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	gpxURL        = "/imports/gpx"
+	tcxURL        = "/imports/tcx"
+	csvURL        = "/imports/csv"
+	jobURL        = "/imports/jobs/:job_id"
+	jobMappingURL = "/imports/jobs/:job_id/mapping"
+	jobStartURL   = "/imports/jobs/:job_id/start"
+	exportURL     = "/imports/export"
+
+	// maxUploadSize ограничение размера загружаемого файла
+	maxUploadSize = 32 << 20
+	// uploadField имя поля multipart-формы с файлом
+	uploadField = "file"
+)
+
+// UploadURLs маршруты загрузки файлов: размер их тела ограничивают сами обработчики (maxUploadSize),
+// остальные маршруты imports подчиняются общему ограничению сервера
+var UploadURLs = []string{gpxURL, tcxURL, csvURL, exportURL}
+
+type handler struct {
+	workoutRepository  workout.Repository
+	userRepository     user.Repository
+	exerciseRepository exercise.Repository
+	metricRepository   metric.Repository
+	uow                uow.UnitOfWork
+	jobs               *JobStore
+	audit              *audit.Recorder
+	auth               *auth.Service
+}
+
+func NewHandler(workoutRepo workout.Repository, userRepo user.Repository, exerciseRepo exercise.Repository, metricRepo metric.Repository, unitOfWork uow.UnitOfWork, jobs *JobStore, recorder *audit.Recorder, authService *auth.Service) handlers.Handler {
+	return &handler{
+		workoutRepository:  workoutRepo,
+		userRepository:     userRepo,
+		exerciseRepository: exerciseRepo,
+		metricRepository:   metricRepo,
+		uow:                unitOfWork,
+		jobs:               jobs,
+		audit:              recorder,
+		auth:               authService,
+	}
+}
+
+func (h *handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, gpxURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.ImportGPX)))
+	router.HandlerFunc(http.MethodPost, tcxURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.ImportTCX)))
+	router.HandlerFunc(http.MethodPost, csvURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.UploadCSV)))
+	router.HandlerFunc(http.MethodGet, jobURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.GetJob)))
+	router.HandlerFunc(http.MethodPut, jobMappingURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.UpdateMapping)))
+	router.HandlerFunc(http.MethodPost, jobStartURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.StartJob)))
+	router.HandlerFunc(http.MethodPost, exportURL, apperror.Middleware(h.auth.TokenAuthMiddleware(h.ImportExport)))
+}
+
+// currentUser возвращает пользователя, от имени которого выполняется запрос
+func (h *handler) currentUser(r *http.Request) (user.User, error) {
+	username, ok := r.Context().Value("username").(string)
+	if !ok {
+		logging.FromContext(r.Context()).Error("Ошибка извлечения username из контекста")
+		return user.User{}, apperror.NewAppError(nil, "Ошибка аутентификации", "Не удалось получить пользователя", http.StatusUnauthorized)
+	}
+
+	usr, err := h.userRepository.FindOne(r.Context(), username)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения пользователя по username: %v", err)
+		return user.User{}, apperror.NewAppError(err, "Ошибка при импорте", "Ошибка получения пользователя", http.StatusInternalServerError)
+	}
+	return usr, nil
+}
+
+// ImportGPX создаёт кардио-тренировку из загруженного GPX-файла
+func (h *handler) ImportGPX(w http.ResponseWriter, r *http.Request) error {
+	return h.importTrack(w, r, ParseGPX)
+}
+
+// ImportTCX создаёт кардио-тренировку из загруженного TCX-файла
+func (h *handler) ImportTCX(w http.ResponseWriter, r *http.Request) error {
+	return h.importTrack(w, r, ParseTCX)
+}
+
+func (h *handler) importTrack(w http.ResponseWriter, r *http.Request, parse func(io.Reader) (Track, error)) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	file, _, err := r.FormFile(uploadField)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка чтения загруженного файла: %v", err)
+		return apperror.NewAppError(err, "Не удалось прочитать файл", "Ожидается multipart/form-data с полем file", http.StatusBadRequest)
+	}
+	defer file.Close()
+
+	track, err := parse(file)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка разбора трека: %v", err)
+		return apperror.NewAppError(err, "Неверный формат файла", err.Error(), http.StatusBadRequest)
+	}
+
+	start, cardio, err := track.ToCardio()
+	if err != nil {
+		return apperror.NewAppError(err, "Файл не содержит точек трека", err.Error(), http.StatusBadRequest)
+	}
+	if err := cardio.Validate(); err != nil {
+		return apperror.NewAppError(err, err.Error(), "Некорректные данные трека", http.StatusBadRequest)
+	}
+
+	ctx := r.Context()
+	usr, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	if age, err := usr.Age(time.Now()); err == nil {
+		cardio.Compute(age)
+	} else {
+		cardio.Compute(0)
+	}
+
+	wk := workout.Workout{
+		UserID:    usr.ID,
+		StartTime: start.Unix(),
+		Kind:      workout.KindCardio,
+		Exercises: []exercise.Exercise{},
+		Groups:    []exercise.Group{},
+		Cardio:    &cardio,
+	}
+
+	// Повторная загрузка того же файла не должна создавать дубликат. Проверка и создание выполняются
+	// в одной единице работы, чтобы две одновременные загрузки не создали тренировку дважды.
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		exists, err := h.workoutRepository.ExistsByStartTime(ctx, usr.ID, wk.StartTime)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка проверки дубликата тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при импорте тренировки", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		if exists {
+			return apperror.NewAppError(apperror.ErrConflict, "Тренировка с таким временем начала уже существует", "Дубликат по start_time", http.StatusConflict)
+		}
+
+		if wk.ID, err = h.workoutRepository.Create(ctx, wk); err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка создания тренировки: %v", err)
+			return apperror.NewAppError(err, "Ошибка при импорте тренировки", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.audit.Record(r, audit.ActionCreate, audit.ResourceWorkout, wk.ID, wk.UserID, nil, wk)
+	metrics.WorkoutsCreated.WithLabelValues(metrics.SourceImport).Inc()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(wk); err != nil {
+		return apperror.NewAppError(err, "Ошибка при отправке ответа", "Ошибка кодирования JSON", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// UploadCSV принимает выгрузку Strong, Hevy или FitNotes и создаёт задачу импорта с предложенным сопоставлением упражнений.
+// Параметры: source=strong|hevy|fitnotes, unit=kg|lbs (только для Strong).
+func (h *handler) UploadCSV(w http.ResponseWriter, r *http.Request) error {
+	source := Source(r.URL.Query().Get("source"))
+	switch source {
+	case SourceStrong, SourceHevy, SourceFitNotes:
+	default:
+		return apperror.NewAppError(nil, "Неизвестный источник", "Параметр source: strong, hevy или fitnotes", http.StatusBadRequest)
+	}
+
+	usr, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	file, _, err := r.FormFile(uploadField)
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка чтения загруженного файла: %v", err)
+		return apperror.NewAppError(err, "Не удалось прочитать файл", "Ожидается multipart/form-data с полем file", http.StatusBadRequest)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return apperror.NewAppError(err, "Не удалось прочитать файл", err.Error(), http.StatusBadRequest)
+	}
+	sum := sha256.Sum256(content)
+
+	workouts, err := ParseCSV(bytes.NewReader(content), source, r.URL.Query().Get("unit") == "lbs")
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка разбора CSV: %v", err)
+		return apperror.NewAppError(err, "Неверный формат файла", err.Error(), http.StatusBadRequest)
+	}
+	if len(workouts) == 0 {
+		return apperror.NewAppError(nil, "Файл не содержит тренировок", "", http.StatusBadRequest)
+	}
+
+	catalog, err := h.exerciseRepository.FindAll(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка получения каталога упражнений: %v", err)
+		return apperror.NewAppError(err, "Ошибка при импорте", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+	}
+
+	now := time.Now()
+	job, created, err := h.jobs.Add(&Job{
+		ID:        newJobID(),
+		UserID:    usr.ID,
+		Source:    source,
+		Checksum:  hex.EncodeToString(sum[:]),
+		Status:    JobAwaitingReview,
+		Mapping:   proposeMapping(workouts, catalog),
+		Total:     len(workouts),
+		CreatedAt: now,
+		UpdatedAt: now,
+		workouts:  workouts,
+	})
+	if errors.Is(err, errTooManyJobs) {
+		return apperror.NewAppError(err, "Слишком много задач импорта", "Дождитесь завершения начатых импортов", http.StatusTooManyRequests)
+	}
+
+	status := http.StatusCreated
+	if !created {
+		// Повторная загрузка того же файла возвращает уже существующую задачу
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(job)
+}
+
+// GetJob возвращает состояние и прогресс задачи импорта
+func (h *handler) GetJob(w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	job, ok := h.jobs.Get(usr.ID, httprouter.ParamsFromContext(r.Context()).ByName("job_id"))
+	if !ok {
+		return apperror.NewAppError(nil, "Задача импорта не найдена", "", http.StatusNotFound)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(job)
+}
+
+// UpdateMapping исправляет предложенное сопоставление упражнений до запуска импорта
+func (h *handler) UpdateMapping(w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+	jobID := httprouter.ParamsFromContext(r.Context()).ByName("job_id")
+	if _, ok := h.jobs.Get(usr.ID, jobID); !ok {
+		return apperror.NewAppError(nil, "Задача импорта не найдена", "", http.StatusNotFound)
+	}
+
+	var changes []MappingEntry
+	if err := handlers.Decode(r, &changes); err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка декодирования тела запроса: %v", err)
+		return apperror.NewAppError(err, "Неверный формат данных", "Ошибка декодирования JSON", http.StatusBadRequest)
+	}
+
+	// Названия из каталога подставляются для сопоставлений, заданных вручную
+	for i, c := range changes {
+		switch c.Action {
+		case ActionMatch:
+			catalogExercise, err := h.exerciseRepository.FindOne(r.Context(), strconv.FormatInt(c.CatalogID, 10))
+			if err != nil {
+				return apperror.NewAppError(err, "Упражнение каталога не найдено", fmt.Sprintf("catalog_id %d", c.CatalogID), http.StatusBadRequest)
+			}
+			changes[i].CatalogName = catalogExercise.Name
+		case ActionCreate, ActionSkip:
+			changes[i].CatalogID, changes[i].CatalogName = 0, ""
+		default:
+			return apperror.NewAppError(nil, fmt.Sprintf("Неизвестное действие %q", c.Action), "Допустимо: match, create, skip", http.StatusBadRequest)
+		}
+	}
+
+	job, err := h.jobs.Update(jobID, func(job *Job) error {
+		if job.Status != JobAwaitingReview {
+			return apperror.NewAppError(nil, "Сопоставление можно менять только до запуска импорта", string(job.Status), http.StatusConflict)
+		}
+		for _, c := range changes {
+			found := false
+			for i := range job.Mapping {
+				if job.Mapping[i].SourceName == c.SourceName {
+					job.Mapping[i].Action = c.Action
+					job.Mapping[i].CatalogID = c.CatalogID
+					job.Mapping[i].CatalogName = c.CatalogName
+					found = true
+					break
+				}
+			}
+			if !found {
+				return apperror.NewAppError(nil, fmt.Sprintf("Упражнение %q отсутствует в файле", c.SourceName), "", http.StatusBadRequest)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(job)
+}
+
+// StartJob подтверждает сопоставление и запускает импорт в фоне
+func (h *handler) StartJob(w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+	jobID := httprouter.ParamsFromContext(r.Context()).ByName("job_id")
+	if _, ok := h.jobs.Get(usr.ID, jobID); !ok {
+		return apperror.NewAppError(nil, "Задача импорта не найдена", "", http.StatusNotFound)
+	}
+
+	job, err := h.jobs.Update(jobID, func(job *Job) error {
+		if job.Status != JobAwaitingReview {
+			return apperror.NewAppError(nil, "Импорт уже запущен", string(job.Status), http.StatusConflict)
+		}
+		job.Status = JobRunning
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Импорт продолжается после ответа клиенту, поэтому отмена запроса на него не влияет.
+	// Созданные тренировки записываются в журнал аудита от имени автора запроса.
+	auditReq := r.WithContext(context.WithoutCancel(r.Context()))
+	h.jobs.Go(r.Context(), func(ctx context.Context) {
+		h.runJob(ctx, auditReq, job.ID, usr.ID, job.Mapping)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(job)
+}
+
+// ExportImportResult итог восстановления данных из выгрузки
+type ExportImportResult struct {
+	WorkoutsCreated int `json:"workouts_created"`
+	WorkoutsSkipped int `json:"workouts_skipped"`
+	MetricsCreated  int `json:"metrics_created"`
+	MetricsSkipped  int `json:"metrics_skipped"`
+}
+
+// ImportExport восстанавливает тренировки и метрики из выгрузки GET /export (json или zip).
+// Файл передаётся полем file multipart-формы либо телом запроса.
+// Тренировки с уже существующим временем начала и метрики за уже заполненные дни пропускаются.
+// Документ проверяется целиком до записи и записывается в одной единице работы: ошибка
+// в середине файла не оставляет импорт выполненным наполовину.
+func (h *handler) ImportExport(w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.currentUser(r)
+	if err != nil {
+		return err
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	var body io.Reader = r.Body
+	if file, _, err := r.FormFile(uploadField); err == nil {
+		defer file.Close()
+		body = file
+	}
+
+	doc, err := export.ReadDocument(body)
+	if errors.Is(err, export.ErrDocumentTooLarge) {
+		return apperror.NewAppError(err, "Выгрузка слишком большая", err.Error(), http.StatusRequestEntityTooLarge)
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Errorf("Ошибка чтения выгрузки: %v", err)
+		return apperror.NewAppError(err, "Неверный формат выгрузки", err.Error(), http.StatusBadRequest)
+	}
+	if err := validateDocument(doc); err != nil {
+		return apperror.NewAppError(err, "Некорректные данные выгрузки", err.Error(), http.StatusBadRequest)
+	}
+
+	// Выгрузка могла содержать удалённые в корзину записи и зоны, рассчитанные по прежнему возрасту
+	age := 0
+	if a, err := usr.Age(time.Now()); err == nil {
+		age = a
+	}
+	for i := range doc.Workouts {
+		prepareWorkout(&doc.Workouts[i], usr.ID, age)
+	}
+
+	var result ExportImportResult
+	var createdWorkouts []workout.Workout
+	var createdMetrics []metric.Metric
+	err = h.uow.Do(r.Context(), func(ctx context.Context) error {
+		// Функция может выполняться повторно при конфликте транзакций
+		result, createdWorkouts, createdMetrics = ExportImportResult{}, nil, nil
+
+		for _, wk := range doc.Workouts {
+			exists, err := h.workoutRepository.ExistsByStartTime(ctx, usr.ID, wk.StartTime)
+			if err != nil {
+				logging.FromContext(r.Context()).Errorf("Ошибка проверки дубликата тренировки: %v", err)
+				return apperror.NewAppError(err, "Ошибка при импорте", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+			}
+			if exists {
+				result.WorkoutsSkipped++
+				continue
+			}
+			if wk.ID, err = h.workoutRepository.Create(ctx, wk); err != nil {
+				logging.FromContext(r.Context()).Errorf("Ошибка создания тренировки: %v", err)
+				return apperror.NewAppError(err, "Ошибка при импорте", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+			}
+			createdWorkouts = append(createdWorkouts, wk)
+			result.WorkoutsCreated++
+		}
+
+		existing, err := h.metricRepository.FindAllByUserID(ctx, usr.ID)
+		if err != nil {
+			logging.FromContext(r.Context()).Errorf("Ошибка получения метрик: %v", err)
+			return apperror.NewAppError(err, "Ошибка при импорте", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+		}
+		days := make(map[string]struct{}, len(existing))
+		for _, m := range existing {
+			days[m.Day] = struct{}{}
+		}
+		for _, m := range doc.Metrics {
+			if _, ok := days[m.Day]; ok {
+				result.MetricsSkipped++
+				continue
+			}
+			m.ID, m.UserID = 0, usr.ID
+			if m.ID, err = h.metricRepository.Create(ctx, m); err != nil {
+				logging.FromContext(r.Context()).Errorf("Ошибка создания метрики: %v", err)
+				return apperror.NewAppError(err, "Ошибка при импорте", "Ошибка взаимодействия с базой данных", http.StatusInternalServerError)
+			}
+			createdMetrics = append(createdMetrics, m)
+			days[m.Day] = struct{}{}
+			result.MetricsCreated++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, wk := range createdWorkouts {
+		h.audit.Record(r, audit.ActionCreate, audit.ResourceWorkout, wk.ID, wk.UserID, nil, wk)
+	}
+	for _, m := range createdMetrics {
+		h.audit.Record(r, audit.ActionCreate, audit.ResourceMetric, m.ID, m.UserID, nil, m)
+	}
+	metrics.WorkoutsCreated.WithLabelValues(metrics.SourceImport).Add(float64(len(createdWorkouts)))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(result)
+}
+
+// validateDocument проверяет все тренировки и метрики выгрузки по тем же правилам, что и API,
+// и возвращает apperror.ValidationErrors со всеми нарушениями
+func validateDocument(doc export.Document) error {
+	var fields apperror.ValidationErrors
+	add := func(field, message string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: message})
+	}
+
+	var violations validate.Errors
+	for i, wk := range doc.Workouts {
+		field := fmt.Sprintf("workouts[%d]", i)
+		if errors.As(validate.Struct(wk), &violations) {
+			// Cardio.Validate повторил бы нарушения тегов кардио, поэтому дальше тренировка не проверяется
+			for _, v := range violations {
+				add(field+"."+v.Field, v.Message)
+			}
+			continue
+		}
+		if wk.StartTime < 0 {
+			add(field+".start_time", "must be at least 0")
+		}
+		switch wk.Kind {
+		case "", workout.KindStrength:
+			if wk.Cardio != nil {
+				add(field+".cardio", "is allowed only for cardio workouts")
+			}
+		case workout.KindCardio:
+			if wk.Cardio == nil {
+				add(field+".cardio", "is required")
+			} else if err := wk.Cardio.Validate(); err != nil {
+				add(field+".cardio", err.Error())
+			}
+		default:
+			add(field+".kind", "must be one of: strength, cardio")
+		}
+
+		ids := make(map[int64]struct{}, len(wk.Exercises))
+		for _, ex := range wk.Exercises {
+			ids[ex.ID] = struct{}{}
+		}
+		for j, g := range wk.Groups {
+			if err := g.Validate(); err != nil {
+				add(fmt.Sprintf("%s.groups[%d]", field, j), err.Error())
+				continue
+			}
+			for _, id := range g.ExerciseIDs {
+				if _, ok := ids[id]; !ok {
+					add(fmt.Sprintf("%s.groups[%d]", field, j), fmt.Sprintf("exercise %d is not in the workout", id))
+					break
+				}
+			}
+		}
+	}
+
+	for i, m := range doc.Metrics {
+		dto := metric.CreateMetricDTO{Weight: m.Weight, CaloriesConsumed: m.CaloriesConsumed, Day: m.Day}
+		if errors.As(validate.Struct(dto), &violations) {
+			for _, v := range violations {
+				add(fmt.Sprintf("metrics[%d].%s", i, v.Field), v.Message)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+	return nil
+}
+
+// prepareWorkout готовит тренировку из выгрузки к созданию у пользователя userID: восстанавливает
+// из корзины тренировку, её упражнения и подходы и пересчитывает кардио по возрасту age
+func prepareWorkout(wk *workout.Workout, userID int64, age int) {
+	wk.ID = 0
+	wk.UserID = userID
+	wk.DeletedAt = nil
+	if wk.Kind == "" {
+		wk.Kind = workout.KindStrength
+	}
+	if wk.Exercises == nil {
+		wk.Exercises = []exercise.Exercise{}
+	}
+	for i := range wk.Exercises {
+		ex := &wk.Exercises[i]
+		ex.DeletedAt = nil
+		if ex.Sets == nil {
+			ex.Sets = []exercise.ExerciseSet{}
+		}
+		for j := range ex.Sets {
+			ex.Sets[j].DeletedAt = nil
+		}
+	}
+	if wk.Groups == nil {
+		wk.Groups = []exercise.Group{}
+	}
+	if wk.Cardio != nil {
+		wk.Cardio.Compute(age)
+	}
+}
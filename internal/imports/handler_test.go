@@ -0,0 +1,102 @@
+package imports
+
+import (
+	"errors"
+	"fit-journal/internal/apperror"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/internal/entities/metric"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/internal/export"
+	"testing"
+)
+
+func TestValidateDocument(t *testing.T) {
+	valid := workout.Workout{
+		StartTime: 1700000000,
+		Kind:      workout.KindStrength,
+		Exercises: []exercise.Exercise{
+			{ID: 1, Name: "Squat", Sets: []exercise.ExerciseSet{{ID: 1, Reps: 5, Weight: 100}}},
+			{ID: 2, Name: "Lunge"},
+		},
+		Groups: []exercise.Group{{ID: 1, Type: exercise.GroupSuperset, ExerciseIDs: []int64{1, 2}}},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(doc *export.Document)
+		want   []string // Поля с нарушениями
+	}{
+		{"valid", func(doc *export.Document) {}, nil},
+		{"set out of range", func(doc *export.Document) {
+			doc.Workouts[0].Exercises[0].Sets[0].Reps = -1
+		}, []string{"workouts[0].exercises[0].sets[0].reps"}},
+		{"unknown kind", func(doc *export.Document) {
+			doc.Workouts[0].Kind = "yoga"
+		}, []string{"workouts[0].kind"}},
+		{"cardio without data", func(doc *export.Document) {
+			doc.Workouts[0].Kind = workout.KindCardio
+		}, []string{"workouts[0].cardio"}},
+		{"invalid cardio", func(doc *export.Document) {
+			doc.Workouts[0].Kind = workout.KindCardio
+			doc.Workouts[0].Cardio = &workout.Cardio{Activity: workout.ActivityRun, MovingTimeSec: 60, AvgHR: 180, MaxHR: 170}
+		}, []string{"workouts[0].cardio"}},
+		{"group with foreign exercise", func(doc *export.Document) {
+			doc.Workouts[0].Groups[0].ExerciseIDs = []int64{1, 3}
+		}, []string{"workouts[0].groups[0]"}},
+		{"all violations at once", func(doc *export.Document) {
+			doc.Workouts[0].Exercises[1].Name = ""
+			doc.Metrics = []metric.Metric{{Day: "2024-02-30", Weight: "heavy"}}
+		}, []string{"workouts[0].exercises[1].name", "metrics[0].weight", "metrics[0].day"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wk := valid
+			wk.Exercises = []exercise.Exercise{valid.Exercises[0], valid.Exercises[1]}
+			wk.Exercises[0].Sets = []exercise.ExerciseSet{valid.Exercises[0].Sets[0]}
+			wk.Groups = []exercise.Group{valid.Groups[0]}
+			doc := export.Document{SchemaVersion: export.SchemaVersion, Workouts: []workout.Workout{wk}}
+			tt.modify(&doc)
+
+			err := validateDocument(doc)
+			var fields apperror.ValidationErrors
+			errors.As(err, &fields)
+			if len(fields) != len(tt.want) {
+				t.Fatalf("validateDocument = %v, want violations in %v", err, tt.want)
+			}
+			for i, f := range fields {
+				if f.Field != tt.want[i] {
+					t.Errorf("violation %d in %q, want %q", i, f.Field, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPrepareWorkout(t *testing.T) {
+	deletedAt := int64(1700000000)
+	wk := workout.Workout{
+		ID:        42,
+		UserID:    7,
+		Kind:      workout.KindCardio,
+		DeletedAt: &deletedAt,
+		Exercises: []exercise.Exercise{{ID: 1, Name: "Row", DeletedAt: &deletedAt, Sets: []exercise.ExerciseSet{{ID: 1, DeletedAt: &deletedAt}}}},
+		Cardio:    &workout.Cardio{Activity: workout.ActivityRun, DistanceMeters: 5000, MovingTimeSec: 1500, PaceSec: 1},
+	}
+
+	prepareWorkout(&wk, 3, 30)
+
+	if wk.ID != 0 || wk.UserID != 3 {
+		t.Errorf("id/user_id = %d/%d, want 0/3", wk.ID, wk.UserID)
+	}
+	if wk.DeletedAt != nil || wk.Exercises[0].DeletedAt != nil || wk.Exercises[0].Sets[0].DeletedAt != nil {
+		t.Error("workout, exercise or set is still in the trash")
+	}
+	// Вычисляемые поля из файла заменяются пересчитанными
+	if wk.Cardio.PaceSec != 300 || wk.Cardio.PaceUnit != "km" {
+		t.Errorf("pace = %v per %q, want 300 per km", wk.Cardio.PaceSec, wk.Cardio.PaceUnit)
+	}
+	if wk.Groups == nil {
+		t.Error("groups must be an empty slice, not nil")
+	}
+}
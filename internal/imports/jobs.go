@@ -0,0 +1,432 @@
+package imports
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fit-journal/internal/audit"
+	"fit-journal/internal/config"
+	"fit-journal/internal/entities/exercise"
+	"fit-journal/internal/entities/workout"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/metrics"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JobStatus состояние фоновой задачи импорта
+type JobStatus string
+
+const (
+	JobAwaitingReview JobStatus = "awaiting_review" // Ждёт подтверждения сопоставления упражнений
+	JobRunning        JobStatus = "running"
+	JobCompleted      JobStatus = "completed"
+	JobFailed         JobStatus = "failed"
+)
+
+// Job задача импорта истории тренировок из CSV
+type Job struct {
+	ID        string         `json:"id"`
+	UserID    int64          `json:"user_id"`
+	Source    Source         `json:"source"`
+	Checksum  string         `json:"checksum"` // SHA-256 файла, по нему повторная загрузка находит существующую задачу
+	Status    JobStatus      `json:"status"`
+	Mapping   []MappingEntry `json:"mapping"`
+	Total     int            `json:"total"`     // Тренировок в файле
+	Processed int            `json:"processed"` // Обработано тренировок
+	Created   int            `json:"created"`   // Создано новых тренировок
+	Skipped   int            `json:"skipped"`   // Пропущено: дубликаты и тренировки без упражнений
+	Errors    []string       `json:"errors,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+
+	workouts []parsedWorkout
+}
+
+// clone копия задачи для отдачи наружу без гонок с фоновым обработчиком
+func (j *Job) clone() Job {
+	c := *j
+	c.Mapping = append([]MappingEntry(nil), j.Mapping...)
+	c.Errors = append([]string(nil), j.Errors...)
+	return c
+}
+
+// errTooManyJobs у пользователя достигнут лимит задач, и освободить место нечем
+var errTooManyJobs = errors.New("too many import jobs")
+
+// errJobInterrupted причина ошибки задачи, прерванной остановкой сервера; повторная загрузка файла заменяет такую задачу
+var errJobInterrupted = errors.New("импорт прерван остановкой сервера, загрузите файл повторно")
+
+// JobStore хранит задачи импорта в памяти процесса. Завершённые задачи удаляются через JobTTL,
+// не подтверждённые за ReviewTimeout — вместе с разобранными тренировками, см. Evict.
+type JobStore struct {
+	mu         sync.RWMutex
+	jobs       map[string]*Job
+	byChecksum map[string]string
+	cfg        config.ImportsConfig
+
+	// Время жизни фоновых импортов (см. Go); задаётся в Start
+	lifetime   context.Context
+	background *sync.WaitGroup
+}
+
+func NewJobStore(cfg config.ImportsConfig) *JobStore {
+	return &JobStore{
+		jobs:       make(map[string]*Job),
+		byChecksum: make(map[string]string),
+		cfg:        cfg,
+		lifetime:   context.Background(),
+		background: &sync.WaitGroup{},
+	}
+}
+
+func checksumKey(userID int64, checksum string) string {
+	return fmt.Sprintf("%d:%s", userID, checksum)
+}
+
+func newJobID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// finished задача больше не изменится
+func (j *Job) finished() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed
+}
+
+// Add регистрирует задачу. Если этот же файл уже загружался пользователем и задача не упала,
+// возвращается существующая задача и false; упавшая задача заменяется новой.
+// Когда у пользователя MaxJobsPerUser задач, удаляется самая старая завершённая,
+// а если все задачи ещё в работе, возвращается errTooManyJobs.
+func (s *JobStore) Add(job *Job) (Job, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := checksumKey(job.UserID, job.Checksum)
+	if id, ok := s.byChecksum[key]; ok {
+		existing := s.jobs[id]
+		if existing.Status != JobFailed {
+			return existing.clone(), false, nil
+		}
+		s.remove(existing)
+	}
+
+	var count int
+	var oldest *Job
+	for _, j := range s.jobs {
+		if j.UserID != job.UserID {
+			continue
+		}
+		count++
+		if j.finished() && (oldest == nil || j.UpdatedAt.Before(oldest.UpdatedAt)) {
+			oldest = j
+		}
+	}
+	if count >= s.cfg.MaxJobsPerUser {
+		if oldest == nil {
+			return Job{}, false, errTooManyJobs
+		}
+		s.remove(oldest)
+	}
+
+	s.jobs[job.ID] = job
+	s.byChecksum[key] = job.ID
+	return job.clone(), true, nil
+}
+
+// remove удаляет задачу; вызывается под блокировкой
+func (s *JobStore) remove(job *Job) {
+	key := checksumKey(job.UserID, job.Checksum)
+	if s.byChecksum[key] == job.ID {
+		delete(s.byChecksum, key)
+	}
+	delete(s.jobs, job.ID)
+}
+
+// Evict удаляет завершённые задачи старше JobTTL и задачи, не подтверждённые за ReviewTimeout.
+// Выполняющиеся задачи не трогаются. Возвращает число удалённых задач.
+func (s *JobStore) Evict(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, job := range s.jobs {
+		age := now.Sub(job.UpdatedAt)
+		if (job.finished() && age > s.cfg.JobTTL) || (job.Status == JobAwaitingReview && age > s.cfg.ReviewTimeout) {
+			s.remove(job)
+			n++
+		}
+	}
+	return n
+}
+
+// Start периодически вызывает Evict до отмены ctx. С этого момента фоновые импорты (см. Go)
+// тоже отменяются вместе с ctx; очистка и импорты учитываются в wg, чтобы остановка сервера их дождалась.
+func (s *JobStore) Start(ctx context.Context, wg *sync.WaitGroup, logger *logging.Logger) {
+	s.mu.Lock()
+	s.lifetime, s.background = ctx, wg
+	s.mu.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.cfg.EvictInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if n := s.Evict(time.Now()); n > 0 {
+				logger.Infof("Удалено устаревших задач импорта: %d", n)
+			}
+		}
+	}()
+}
+
+// Go запускает фоновый импорт. Его контекст несёт логгер запроса reqCtx, но отменяется
+// не с запросом, а при остановке сервера. Во время остановки fn выполняется сразу с отменённым
+// контекстом, чтобы задача завершилась ошибкой, а не осталась в состоянии running.
+func (s *JobStore) Go(reqCtx context.Context, fn func(ctx context.Context)) {
+	s.mu.RLock()
+	lifetime, wg := s.lifetime, s.background
+	s.mu.RUnlock()
+
+	ctx := logging.WithContext(lifetime, logging.FromContext(reqCtx))
+	if lifetime.Err() != nil {
+		fn(ctx)
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fn(ctx)
+	}()
+}
+
+// Get возвращает копию задачи пользователя
+func (s *JobStore) Get(userID int64, id string) (Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.UserID != userID {
+		return Job{}, false
+	}
+	return job.clone(), true
+}
+
+// Update изменяет задачу под блокировкой
+func (s *JobStore) Update(id string, fn func(job *Job) error) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("import job %s not found", id)
+	}
+	if err := fn(job); err != nil {
+		return Job{}, err
+	}
+	job.UpdatedAt = time.Now()
+	return job.clone(), nil
+}
+
+// DeleteByUser удаляет все задачи пользователя вместе с загруженными данными
+func (s *JobStore) DeleteByUser(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		if job.UserID == userID {
+			s.remove(job)
+		}
+	}
+}
+
+// parsedWorkouts возвращает разобранные тренировки задачи
+func (s *JobStore) parsedWorkouts(id string) []parsedWorkout {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if job, ok := s.jobs[id]; ok {
+		return job.workouts
+	}
+	return nil
+}
+
+// runJob импортирует тренировки задачи. Выполняется в фоне после подтверждения сопоставления (см. JobStore.Go);
+// при остановке сервера оставшиеся тренировки не импортируются и задача завершается ошибкой.
+// Уже существующие тренировки (по времени начала) пропускаются, поэтому повторный запуск безопасен.
+// Созданные тренировки записываются в журнал аудита от имени автора запроса r.
+func (h *handler) runJob(ctx context.Context, r *http.Request, id string, userID int64, mapping []MappingEntry) {
+	resolved, err := h.resolveMapping(ctx, mapping)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("Ошибка подготовки каталога для импорта %s: %v", id, err)
+		h.jobs.Update(id, func(job *Job) error {
+			job.Status = JobFailed
+			job.Errors = append(job.Errors, err.Error())
+			return nil
+		})
+		return
+	}
+
+	var failed bool
+	for _, pw := range h.jobs.parsedWorkouts(id) {
+		if ctx.Err() != nil {
+			logging.FromContext(ctx).Warnf("Импорт %s прерван остановкой сервера", id)
+			h.jobs.Update(id, func(job *Job) error {
+				job.Errors = append(job.Errors, errJobInterrupted.Error())
+				return nil
+			})
+			failed = true
+			break
+		}
+		created, err := h.importWorkout(ctx, r, userID, pw, resolved)
+		h.jobs.Update(id, func(job *Job) error {
+			job.Processed++
+			switch {
+			case err != nil:
+				failed = true
+				job.Errors = append(job.Errors, fmt.Sprintf("%s: %v", pw.Start.Format(time.RFC3339), err))
+			case created:
+				job.Created++
+			default:
+				job.Skipped++
+			}
+			return nil
+		})
+	}
+
+	h.jobs.Update(id, func(job *Job) error {
+		job.Status = JobCompleted
+		if failed {
+			job.Status = JobFailed
+		}
+		job.Mapping = resolved
+		// Разобранные строки больше не нужны
+		job.workouts = nil
+		return nil
+	})
+}
+
+// resolveMapping создаёт в каталоге упражнения с действием create.
+// Если упражнение с таким названием уже есть в каталоге, используется оно.
+func (h *handler) resolveMapping(ctx context.Context, mapping []MappingEntry) ([]MappingEntry, error) {
+	catalog, err := h.exerciseRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]exercise.Exercise, len(catalog))
+	for _, c := range catalog {
+		byName[strings.ToLower(c.Name)] = c
+	}
+
+	resolved := append([]MappingEntry(nil), mapping...)
+	for i, m := range resolved {
+		if m.Action != ActionCreate {
+			continue
+		}
+		if c, ok := byName[strings.ToLower(m.SourceName)]; ok {
+			resolved[i].CatalogID, resolved[i].CatalogName = c.ID, c.Name
+			continue
+		}
+		id, err := h.exerciseRepository.Create(ctx, exercise.Exercise{Name: m.SourceName, Sets: []exercise.ExerciseSet{}})
+		if err != nil {
+			return nil, err
+		}
+		resolved[i].CatalogID, resolved[i].CatalogName = id, m.SourceName
+	}
+
+	return resolved, nil
+}
+
+// importWorkout создаёт тренировку из разобранных строк и записывает её в журнал аудита.
+// Возвращает false, если тренировка пропущена.
+func (h *handler) importWorkout(ctx context.Context, r *http.Request, userID int64, pw parsedWorkout, mapping []MappingEntry) (bool, error) {
+	bySource := make(map[string]MappingEntry, len(mapping))
+	for _, m := range mapping {
+		bySource[m.SourceName] = m
+	}
+
+	wk := workout.Workout{
+		UserID:    userID,
+		StartTime: pw.Start.Unix(),
+		Kind:      workout.KindStrength,
+		Exercises: []exercise.Exercise{},
+		Groups:    []exercise.Group{},
+	}
+
+	supersets := make(map[string][]int64)
+	var supersetOrder []string
+	for _, pe := range pw.Exercises {
+		m, ok := bySource[pe.Name]
+		if !ok || m.Action == ActionSkip {
+			continue
+		}
+
+		ex := exercise.Exercise{
+			ID:        mathrand.Int63(),
+			CatalogID: m.CatalogID,
+			Name:      m.CatalogName,
+			Sets:      make([]exercise.ExerciseSet, 0, len(pe.Sets)),
+		}
+		for _, s := range pe.Sets {
+			ex.Sets = append(ex.Sets, exercise.ExerciseSet{ID: mathrand.Int63(), Reps: s.Reps, Weight: s.Weight})
+		}
+		wk.Exercises = append(wk.Exercises, ex)
+
+		if pe.SupersetID != "" {
+			if _, ok := supersets[pe.SupersetID]; !ok {
+				supersetOrder = append(supersetOrder, pe.SupersetID)
+			}
+			supersets[pe.SupersetID] = append(supersets[pe.SupersetID], ex.ID)
+		}
+	}
+	if len(wk.Exercises) == 0 {
+		return false, nil
+	}
+
+	for _, key := range supersetOrder {
+		ids := supersets[key]
+		group := exercise.Group{ID: mathrand.Int63(), Type: exercise.GroupSuperset, ExerciseIDs: ids}
+		if len(ids) > 2 {
+			group.Type = exercise.GroupGiantSet
+		}
+		if group.Validate() == nil {
+			wk.Groups = append(wk.Groups, group)
+		}
+	}
+
+	// Проверка дубликата и создание в одной единице работы: иначе две одновременные загрузки
+	// одного файла обе увидят, что тренировки нет, и создадут её дважды
+	var created bool
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		exists, err := h.workoutRepository.ExistsByStartTime(ctx, userID, wk.StartTime)
+		if err != nil || exists {
+			created = false
+			return err
+		}
+		id, err := h.workoutRepository.Create(ctx, wk)
+		if err != nil {
+			return err
+		}
+		wk.ID, created = id, true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if created {
+		h.audit.Record(r, audit.ActionCreate, audit.ResourceWorkout, wk.ID, wk.UserID, nil, wk)
+		metrics.WorkoutsCreated.WithLabelValues(metrics.SourceImport).Inc()
+	}
+	return created, nil
+}
@@ -0,0 +1,219 @@
+package imports
+
+import (
+	"context"
+	"errors"
+	"fit-journal/internal/audit"
+	auditMemory "fit-journal/internal/audit/memory"
+	"fit-journal/internal/config"
+	exerciseMemory "fit-journal/internal/entities/exercise/memory"
+	workoutMemory "fit-journal/internal/entities/workout/memory"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/uow"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testJobsConfig = config.ImportsConfig{
+	JobTTL:         24 * time.Hour,
+	ReviewTimeout:  time.Hour,
+	MaxJobsPerUser: 2,
+	EvictInterval:  time.Minute,
+}
+
+func newTestJob(userID int64, checksum string, status JobStatus, updatedAt time.Time) *Job {
+	return &Job{
+		ID:        newJobID(),
+		UserID:    userID,
+		Checksum:  checksum,
+		Status:    status,
+		CreatedAt: updatedAt,
+		UpdatedAt: updatedAt,
+		workouts:  []parsedWorkout{{Start: updatedAt}},
+	}
+}
+
+func TestJobStoreAdd(t *testing.T) {
+	now := time.Now()
+
+	t.Run("same file returns existing job", func(t *testing.T) {
+		s := NewJobStore(testJobsConfig)
+		first, _, _ := s.Add(newTestJob(1, "a", JobAwaitingReview, now))
+		got, created, err := s.Add(newTestJob(1, "a", JobAwaitingReview, now))
+		if err != nil || created || got.ID != first.ID {
+			t.Fatalf("Add = %s, %v, %v; want existing %s", got.ID, created, err, first.ID)
+		}
+		// Тот же файл другого пользователя — отдельная задача
+		if _, created, _ := s.Add(newTestJob(2, "a", JobAwaitingReview, now)); !created {
+			t.Fatal("job of another user was not created")
+		}
+	})
+
+	t.Run("failed job is replaced", func(t *testing.T) {
+		s := NewJobStore(testJobsConfig)
+		failed, _, _ := s.Add(newTestJob(1, "a", JobFailed, now))
+		got, created, err := s.Add(newTestJob(1, "a", JobAwaitingReview, now))
+		if err != nil || !created || got.ID == failed.ID {
+			t.Fatalf("Add = %s, %v, %v; want new job", got.ID, created, err)
+		}
+		if _, ok := s.Get(1, failed.ID); ok {
+			t.Error("failed job was left in the store")
+		}
+	})
+
+	t.Run("limit evicts oldest finished job", func(t *testing.T) {
+		s := NewJobStore(testJobsConfig)
+		old, _, _ := s.Add(newTestJob(1, "a", JobCompleted, now.Add(-2*time.Hour)))
+		recent, _, _ := s.Add(newTestJob(1, "b", JobCompleted, now.Add(-time.Hour)))
+		if _, created, err := s.Add(newTestJob(1, "c", JobAwaitingReview, now)); err != nil || !created {
+			t.Fatalf("Add = %v, %v; want created", created, err)
+		}
+		if _, ok := s.Get(1, old.ID); ok {
+			t.Error("oldest finished job was not evicted")
+		}
+		if _, ok := s.Get(1, recent.ID); !ok {
+			t.Error("recent finished job was evicted")
+		}
+	})
+
+	t.Run("limit with active jobs", func(t *testing.T) {
+		s := NewJobStore(testJobsConfig)
+		s.Add(newTestJob(1, "a", JobAwaitingReview, now))
+		s.Add(newTestJob(1, "b", JobRunning, now))
+		if _, _, err := s.Add(newTestJob(1, "c", JobAwaitingReview, now)); !errors.Is(err, errTooManyJobs) {
+			t.Fatalf("Add error = %v, want errTooManyJobs", err)
+		}
+		// Лимит считается для каждого пользователя отдельно
+		if _, _, err := s.Add(newTestJob(2, "c", JobAwaitingReview, now)); err != nil {
+			t.Fatalf("Add for another user: %v", err)
+		}
+	})
+}
+
+func TestJobStoreEvict(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		status  JobStatus
+		age     time.Duration
+		evicted bool
+	}{
+		{"fresh completed", JobCompleted, time.Hour, false},
+		{"expired completed", JobCompleted, 25 * time.Hour, true},
+		{"expired failed", JobFailed, 25 * time.Hour, true},
+		{"awaiting review", JobAwaitingReview, 30 * time.Minute, false},
+		{"review timed out", JobAwaitingReview, 2 * time.Hour, true},
+		{"long running", JobRunning, 48 * time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewJobStore(testJobsConfig)
+			job, _, _ := s.Add(newTestJob(1, "a", tt.status, now.Add(-tt.age)))
+
+			n := s.Evict(now)
+			_, ok := s.Get(1, job.ID)
+			if ok == tt.evicted || (n == 1) != tt.evicted {
+				t.Fatalf("Evict = %d, job kept = %v; want evicted = %v", n, ok, tt.evicted)
+			}
+			if tt.evicted && s.parsedWorkouts(job.ID) != nil {
+				t.Error("parsed workouts of evicted job are still reachable")
+			}
+			// Освободившееся место позволяет загрузить тот же файл заново
+			if _, created, _ := s.Add(newTestJob(1, "a", JobAwaitingReview, now)); created != tt.evicted {
+				t.Errorf("re-upload created = %v, want %v", created, tt.evicted)
+			}
+		})
+	}
+}
+
+func TestJobStoreDeleteByUser(t *testing.T) {
+	s := NewJobStore(testJobsConfig)
+	own, _, _ := s.Add(newTestJob(1, "a", JobAwaitingReview, time.Now()))
+	other, _, _ := s.Add(newTestJob(2, "a", JobAwaitingReview, time.Now()))
+
+	s.DeleteByUser(1)
+	if _, ok := s.Get(1, own.ID); ok {
+		t.Error("job of deleted user is still stored")
+	}
+	if _, ok := s.Get(2, other.ID); !ok {
+		t.Error("job of another user was deleted")
+	}
+	if _, created, _ := s.Add(newTestJob(1, "a", JobAwaitingReview, time.Now())); !created {
+		t.Error("checksum of deleted job still points to it")
+	}
+}
+
+func TestJobStoreGo(t *testing.T) {
+	store := NewJobStore(testJobsConfig)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	store.Start(ctx, &wg, logging.GetLogger())
+
+	// Отмена запроса не прерывает импорт, остановка сервера прерывает
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	cancelReq()
+	started := make(chan struct{})
+	var jobErr error
+	store.Go(reqCtx, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		jobErr = ctx.Err()
+	})
+	<-started
+
+	cancel()
+	wg.Wait()
+	if !errors.Is(jobErr, context.Canceled) {
+		t.Errorf("job context error = %v, want context.Canceled", jobErr)
+	}
+
+	// После остановки задача выполняется сразу с отменённым контекстом
+	var ran bool
+	store.Go(context.Background(), func(ctx context.Context) {
+		ran = ctx.Err() != nil
+	})
+	if !ran {
+		t.Error("job was not run with a cancelled context after shutdown")
+	}
+}
+
+func TestRunJobRecordsAudit(t *testing.T) {
+	auditLog := auditMemory.NewRepository()
+	h := &handler{
+		workoutRepository:  workoutMemory.NewRepository(),
+		exerciseRepository: exerciseMemory.NewRepository(),
+		uow:                uow.Serial(),
+		jobs:               NewJobStore(testJobsConfig),
+		audit:              audit.NewRecorder(auditLog),
+	}
+
+	workouts := parseCSVFixture(t, "strong.csv", SourceStrong, false)
+	job, _, err := h.jobs.Add(&Job{ID: newJobID(), UserID: 1, Checksum: "a", Status: JobRunning, Mapping: proposeMapping(workouts, nil), workouts: workouts})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/imports/jobs/"+job.ID+"/start", nil)
+	r = r.WithContext(context.WithValue(r.Context(), "username", "alice"))
+	h.runJob(context.Background(), r, job.ID, 1, job.Mapping)
+
+	done, _ := h.jobs.Get(1, job.ID)
+	if done.Status != JobCompleted || done.Created == 0 {
+		t.Fatalf("job = %+v, want completed with created workouts", done)
+	}
+	entries, err := auditLog.Find(context.Background(), audit.Filter{OwnerID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != done.Created {
+		t.Fatalf("audit entries = %d, want one per created workout (%d)", len(entries), done.Created)
+	}
+	for _, e := range entries {
+		if e.Action != audit.ActionCreate || e.Resource != audit.ResourceWorkout || e.Actor != "alice" || e.ResourceID == 0 {
+			t.Errorf("unexpected audit entry %+v", e)
+		}
+	}
+}
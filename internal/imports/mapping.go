@@ -0,0 +1,118 @@
+package imports
+
+import (
+	"fit-journal/internal/entities/exercise"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MappingAction что сделать с упражнением из внешнего журнала
+type MappingAction string
+
+const (
+	ActionMatch  MappingAction = "match"  // Использовать упражнение из каталога
+	ActionCreate MappingAction = "create" // Добавить упражнение в каталог
+	ActionSkip   MappingAction = "skip"   // Не импортировать подходы этого упражнения
+)
+
+// Candidate упражнение каталога, похожее на импортируемое
+type Candidate struct {
+	CatalogID int64   `json:"catalog_id"`
+	Name      string  `json:"name"`
+	Score     float64 `json:"score"`
+}
+
+// MappingEntry сопоставление названия упражнения из файла с каталогом
+type MappingEntry struct {
+	SourceName  string        `json:"source_name" validate:"required"`
+	Action      MappingAction `json:"action" validate:"required,oneof=match create skip"`
+	CatalogID   int64         `json:"catalog_id,omitempty"`
+	CatalogName string        `json:"catalog_name,omitempty"`
+	Sets        int           `json:"sets"` // Сколько подходов затронет это сопоставление
+	Candidates  []Candidate   `json:"candidates,omitempty"`
+}
+
+// autoMatchScore порог похожести, начиная с которого упражнение сопоставляется автоматически
+const autoMatchScore = 0.75
+
+// maxCandidates количество предлагаемых вариантов для ручной проверки
+const maxCandidates = 3
+
+// normalizeName приводит название к набору слов без регистра и пунктуации
+func normalizeName(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// similarity коэффициент Жаккара по словам названий
+func similarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]struct{}, len(a))
+	for _, w := range a {
+		set[w] = struct{}{}
+	}
+	var common int
+	union := len(set)
+	seen := make(map[string]struct{}, len(b))
+	for _, w := range b {
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+		if _, ok := set[w]; ok {
+			common++
+		} else {
+			union++
+		}
+	}
+	return float64(common) / float64(union)
+}
+
+// proposeMapping строит предложение сопоставления для всех упражнений из файла
+func proposeMapping(workouts []parsedWorkout, catalog []exercise.Exercise) []MappingEntry {
+	counts := make(map[string]int)
+	var names []string
+	for _, w := range workouts {
+		for _, ex := range w.Exercises {
+			if _, ok := counts[ex.Name]; !ok {
+				names = append(names, ex.Name)
+			}
+			counts[ex.Name] += len(ex.Sets)
+		}
+	}
+	sort.Strings(names)
+
+	normalized := make([][]string, len(catalog))
+	for i, c := range catalog {
+		normalized[i] = normalizeName(c.Name)
+	}
+
+	entries := make([]MappingEntry, 0, len(names))
+	for _, name := range names {
+		words := normalizeName(name)
+		candidates := make([]Candidate, 0, len(catalog))
+		for i, c := range catalog {
+			if score := similarity(words, normalized[i]); score > 0 {
+				candidates = append(candidates, Candidate{CatalogID: c.ID, Name: c.Name, Score: score})
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+		if len(candidates) > maxCandidates {
+			candidates = candidates[:maxCandidates]
+		}
+
+		entry := MappingEntry{SourceName: name, Action: ActionCreate, Sets: counts[name], Candidates: candidates}
+		if len(candidates) > 0 && candidates[0].Score >= autoMatchScore {
+			entry.Action = ActionMatch
+			entry.CatalogID = candidates[0].CatalogID
+			entry.CatalogName = candidates[0].Name
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
@@ -0,0 +1,68 @@
+package imports
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Laps  []struct {
+			Tracks []struct {
+				Points []tcxPoint `xml:"Trackpoint"`
+			} `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+type tcxPoint struct {
+	Time     string `xml:"Time"`
+	Position *struct {
+		Lat float64 `xml:"LatitudeDegrees"`
+		Lon float64 `xml:"LongitudeDegrees"`
+	} `xml:"Position"`
+	Altitude  *float64 `xml:"AltitudeMeters"`
+	Distance  *float64 `xml:"DistanceMeters"`
+	HeartRate struct {
+		Value int `xml:"Value"`
+	} `xml:"HeartRateBpm"`
+}
+
+// ParseTCX разбирает TCX-файл (Garmin Training Center). Берётся первая активность файла.
+func ParseTCX(r io.Reader) (Track, error) {
+	var f tcxFile
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return Track{}, fmt.Errorf("invalid tcx: %w", err)
+	}
+	if len(f.Activities) == 0 {
+		return Track{}, ErrEmptyTrack
+	}
+
+	activity := f.Activities[0]
+	track := Track{Activity: activityFromSport(activity.Sport)}
+	for _, lap := range activity.Laps {
+		for _, trk := range lap.Tracks {
+			for _, p := range trk.Points {
+				ts, err := time.Parse(time.RFC3339, p.Time)
+				if err != nil {
+					continue
+				}
+				point := Trackpoint{
+					Time:      ts,
+					Elevation: p.Altitude,
+					Distance:  p.Distance,
+					HR:        p.HeartRate.Value,
+				}
+				if p.Position != nil {
+					point.Lat, point.Lon, point.HasPos = p.Position.Lat, p.Position.Lon, true
+				}
+				track.Points = append(track.Points, point)
+			}
+		}
+	}
+
+	return track, nil
+}
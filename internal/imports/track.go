@@ -0,0 +1,163 @@
+package imports
+
+import (
+	"errors"
+	"fit-journal/internal/entities/workout"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Trackpoint точка трека, общая для GPX и TCX
+type Trackpoint struct {
+	Time      time.Time
+	Lat, Lon  float64
+	HasPos    bool
+	Elevation *float64
+	Distance  *float64 // Накопленная дистанция, если её записало устройство (TCX)
+	HR        int
+}
+
+// Track разобранный трек активности
+type Track struct {
+	Activity workout.ActivityType
+	Points   []Trackpoint
+}
+
+var ErrEmptyTrack = errors.New("track contains no timestamped points")
+
+const (
+	earthRadiusMeters = 6371000.0
+	// minMovingSpeed скорость (м/с), ниже которой отрезок считается остановкой
+	minMovingSpeed = 0.5
+	// maxPointGapSec разрыв между точками, после которого считаем, что запись стояла на паузе
+	maxPointGapSec = 60
+	// elevationThreshold фильтр шума высоты при подсчёте набора
+	elevationThreshold = 2.0
+)
+
+// activityFromSport сопоставляет названия видов спорта из файлов устройств с типами активности
+func activityFromSport(sport string) workout.ActivityType {
+	s := strings.ToLower(strings.TrimSpace(sport))
+	switch {
+	case strings.Contains(s, "bik"), strings.Contains(s, "cycl"), strings.Contains(s, "ride"):
+		return workout.ActivityRide
+	case strings.Contains(s, "row"):
+		return workout.ActivityRow
+	case strings.Contains(s, "swim"):
+		return workout.ActivitySwim
+	default:
+		return workout.ActivityRun
+	}
+}
+
+// haversine расстояние между двумя координатами в метрах
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(d float64) float64 { return d * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// ToCardio считает дистанцию, время, набор высоты, пульс и сплиты по точкам трека.
+// Возвращает время старта активности и данные для кардио-тренировки.
+func (t Track) ToCardio() (time.Time, workout.Cardio, error) {
+	points := make([]Trackpoint, 0, len(t.Points))
+	for _, p := range t.Points {
+		if !p.Time.IsZero() {
+			points = append(points, p)
+		}
+	}
+	if len(points) == 0 {
+		return time.Time{}, workout.Cardio{}, ErrEmptyTrack
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+
+	start := points[0].Time
+	cardio := workout.Cardio{
+		Activity:       t.Activity,
+		ElapsedTimeSec: int64(points[len(points)-1].Time.Sub(start).Seconds()),
+	}
+
+	splitLen := t.Activity.SplitMeters()
+	split := workout.Split{Index: 1}
+	var splitHRSum, splitHRCount int
+	closeSplit := func() {
+		if splitHRCount > 0 {
+			split.AvgHR = splitHRSum / splitHRCount
+		}
+		split.DistanceMeters = math.Round(split.DistanceMeters*10) / 10
+		split.ElevationGainM = math.Round(split.ElevationGainM*10) / 10
+		cardio.Splits = append(cardio.Splits, split)
+		split = workout.Split{Index: split.Index + 1}
+		splitHRSum, splitHRCount = 0, 0
+	}
+
+	var total float64
+	var refElevation *float64
+	for i, p := range points {
+		if p.HR > 0 {
+			cardio.HRSamples = append(cardio.HRSamples, workout.HRSample{
+				OffsetSec: int64(p.Time.Sub(start).Seconds()),
+				BPM:       p.HR,
+			})
+			splitHRSum += p.HR
+			splitHRCount++
+		}
+
+		var gain float64
+		if p.Elevation != nil {
+			switch {
+			case refElevation == nil:
+				refElevation = p.Elevation
+			case *p.Elevation-*refElevation >= elevationThreshold:
+				gain = *p.Elevation - *refElevation
+				refElevation = p.Elevation
+			case *refElevation-*p.Elevation >= elevationThreshold:
+				refElevation = p.Elevation
+			}
+		}
+		cardio.ElevationGainM += gain
+		split.ElevationGainM += gain
+
+		if i == 0 {
+			continue
+		}
+		prev := points[i-1]
+
+		var step float64
+		switch {
+		case p.Distance != nil && prev.Distance != nil:
+			step = math.Max(0, *p.Distance-*prev.Distance)
+		case p.HasPos && prev.HasPos:
+			step = haversine(prev.Lat, prev.Lon, p.Lat, p.Lon)
+		}
+
+		dt := p.Time.Sub(prev.Time).Seconds()
+		moving := dt > 0 && dt <= maxPointGapSec && (step/dt >= minMovingSpeed || (!p.HasPos && p.Distance == nil))
+		if moving {
+			cardio.MovingTimeSec += int64(dt)
+			split.TimeSec += int64(dt)
+		}
+
+		total += step
+		split.DistanceMeters += step
+		if split.DistanceMeters >= splitLen {
+			closeSplit()
+		}
+	}
+	if split.DistanceMeters > 0 {
+		closeSplit()
+	}
+
+	cardio.DistanceMeters = math.Round(total*10) / 10
+	cardio.ElevationGainM = math.Round(cardio.ElevationGainM*10) / 10
+	if cardio.MovingTimeSec == 0 {
+		cardio.MovingTimeSec = cardio.ElapsedTimeSec
+	}
+
+	return start, cardio, nil
+}
@@ -0,0 +1,99 @@
+package imports
+
+import (
+	"fit-journal/internal/entities/workout"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseFixture(t *testing.T, name string, parse func(io.Reader) (Track, error)) Track {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	track, err := parse(f)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return track
+}
+
+func TestParseGPX(t *testing.T) {
+	track := parseFixture(t, "run.gpx", ParseGPX)
+	// Точка без корректного времени пропускается
+	if track.Activity != workout.ActivityRun || len(track.Points) != 3 {
+		t.Fatalf("ParseGPX = %s with %d points, want run with 3", track.Activity, len(track.Points))
+	}
+	if p := track.Points[1]; !p.HasPos || p.HR != 140 || p.Elevation == nil || *p.Elevation != 155 {
+		t.Fatalf("unexpected second point %+v", p)
+	}
+
+	start, cardio, err := track.ToCardio()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !start.Equal(time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("start = %s", start)
+	}
+	// Два отрезка по 0.0045° широты ≈ 500 м
+	if math.Abs(cardio.DistanceMeters-1000) > 5 {
+		t.Errorf("distance = %v, want about 1000", cardio.DistanceMeters)
+	}
+	if cardio.MovingTimeSec != 120 || cardio.ElapsedTimeSec != 120 {
+		t.Errorf("moving/elapsed = %d/%d, want 120/120", cardio.MovingTimeSec, cardio.ElapsedTimeSec)
+	}
+	// Набор считается только по подъёмам выше порога шума
+	if cardio.ElevationGainM != 5 {
+		t.Errorf("elevation gain = %v, want 5", cardio.ElevationGainM)
+	}
+	if len(cardio.HRSamples) != 3 || len(cardio.Splits) != 1 || cardio.Splits[0].AvgHR != 136 {
+		t.Errorf("unexpected HR samples %+v or splits %+v", cardio.HRSamples, cardio.Splits)
+	}
+}
+
+func TestParseTCX(t *testing.T) {
+	track := parseFixture(t, "ride.tcx", ParseTCX)
+	// Берётся только первая активность, точки всех кругов подряд
+	if track.Activity != workout.ActivityRide || len(track.Points) != 3 {
+		t.Fatalf("ParseTCX = %s with %d points, want ride with 3", track.Activity, len(track.Points))
+	}
+	if p := track.Points[1]; p.HasPos || p.Distance == nil || *p.Distance != 250 {
+		t.Fatalf("unexpected second point %+v", p)
+	}
+
+	_, cardio, err := track.ToCardio()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Дистанция берётся из записанной устройством, а не из координат
+	if cardio.DistanceMeters != 1250 || cardio.MovingTimeSec != 90 || cardio.ElevationGainM != 10 {
+		t.Errorf("unexpected cardio %+v", cardio)
+	}
+	if len(cardio.Splits) != 1 || cardio.Splits[0].DistanceMeters != 1250 || cardio.Splits[0].AvgHR != 130 {
+		t.Errorf("unexpected splits %+v", cardio.Splits)
+	}
+}
+
+func TestParseTrackErrors(t *testing.T) {
+	if _, err := ParseGPX(strings.NewReader("<gpx><trk>")); err == nil {
+		t.Error("ParseGPX must reject truncated XML")
+	}
+	if _, err := ParseTCX(strings.NewReader("<TrainingCenterDatabase/>")); err != ErrEmptyTrack {
+		t.Errorf("ParseTCX without activities = %v, want ErrEmptyTrack", err)
+	}
+
+	track, err := ParseGPX(strings.NewReader(`<gpx><trk><trkseg><trkpt lat="1" lon="1"/></trkseg></trk></gpx>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := track.ToCardio(); err != ErrEmptyTrack {
+		t.Errorf("ToCardio without timestamps = %v, want ErrEmptyTrack", err)
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fit-journal/internal/apperror"
+	"fmt"
+	"net/http"
+)
+
+// MaxBodySize ограничивает размер тела запроса. Пути из exempt (точное совпадение) проверяют размер
+// сами (например, загрузка файлов импорта), для них ограничение не ставится.
+func MaxBodySize(limit int64, exempt ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, path := range exempt {
+				if r.URL.Path == path {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			if r.ContentLength > limit {
+				apperror.Write(w, r, apperror.NewAppError(nil, "Request body too large", fmt.Sprintf("request body must not exceed %d bytes", limit), http.StatusRequestEntityTooLarge))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	const limit = 10
+	h := MaxBodySize(limit, "/imports/csv")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	}))
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		chunked bool // Без Content-Length размер проверяется при чтении
+		want    int
+	}{
+		{"within limit", "/workouts", "0123456789", false, http.StatusOK},
+		{"over limit", "/workouts", "0123456789x", false, http.StatusRequestEntityTooLarge},
+		{"over limit chunked", "/workouts", "0123456789x", true, http.StatusRequestEntityTooLarge},
+		{"exempt path", "/imports/csv", strings.Repeat("x", 100), false, http.StatusOK},
+		// Исключение действует только на сам путь, а не на соседние маршруты
+		{"path under exempt prefix", "/imports/csv/extra", strings.Repeat("x", 100), false, http.StatusRequestEntityTooLarge},
+		{"sibling route", "/imports/jobs/1/mapping", strings.Repeat("x", 100), true, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				r.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
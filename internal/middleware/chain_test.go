@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// tag middleware, дописывающая name в журнал calls до и после вызова следующего обработчика
+func tag(calls *[]string, name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, name+" before")
+			next.ServeHTTP(w, r)
+			*calls = append(*calls, name+" after")
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{"no middleware", nil, []string{"handler"}},
+		{"single", []string{"a"}, []string{"a before", "handler", "a after"}},
+		{"first runs first", []string{"a", "b", "c"}, []string{
+			"a before", "b before", "c before", "handler", "c after", "b after", "a after",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var middlewares []func(http.Handler) http.Handler
+			for _, name := range tt.names {
+				middlewares = append(middlewares, tag(&calls, name))
+			}
+			h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, "handler")
+			}), middlewares...)
+
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+			if !reflect.DeepEqual(calls, tt.want) {
+				t.Errorf("calls = %v, want %v", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBytes  int64
+	}{
+		{"nothing written", func(w http.ResponseWriter, r *http.Request) {}, http.StatusOK, 0},
+		{"implicit status", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("hello")) }, http.StatusOK, 5},
+		// Повторный WriteHeader не меняет отправленный статус
+		{"first status wins", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("{}"))
+		}, http.StatusCreated, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newResponseRecorder(httptest.NewRecorder())
+			tt.handler(rec, httptest.NewRequest("GET", "/", nil))
+			if rec.Status() != tt.wantStatus || rec.bytes != tt.wantBytes {
+				t.Errorf("status %d, bytes %d; want %d, %d", rec.Status(), rec.bytes, tt.wantStatus, tt.wantBytes)
+			}
+		})
+	}
+}
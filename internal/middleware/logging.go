@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fit-journal/internal/requestid"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/tracing"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+// RequestID берёт идентификатор запроса из заголовка X-Request-ID или генерирует новый,
+// сохраняет его в контексте (см. requestid.FromContext) и возвращает клиенту в том же заголовке.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithContext(r.Context(), id)))
+	})
+}
+
+// AccessLog создаёт для каждого запроса логгер с полями request_id, method и route,
+// а при активном трейсе ещё trace_id и span_id, сохраняет его в контексте (см. logging.FromContext)
+// и по завершении пишет строку access-лога со статусом, размером ответа и временем обработки.
+// Поля, добавленные во время обработки через logging.AddField (user и user_id), тоже попадают в строку.
+func AccessLog(router *httprouter.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			logger := logging.GetLogger().
+				GetLoggerWithField("request_id", requestid.FromContext(r.Context())).
+				GetLoggerWithField("method", r.Method).
+				GetLoggerWithField("route", Route(router, r))
+			if traceID, spanID := tracing.IDs(r.Context()); traceID != "" {
+				logger = logger.GetLoggerWithField("trace_id", traceID).GetLoggerWithField("span_id", spanID)
+			}
+
+			rec := newResponseRecorder(w)
+			ctx := logging.WithContext(r.Context(), logger)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			// Обработчики могли добавить поля, например пользователя после проверки токена
+			logging.FromContext(ctx).
+				GetLoggerWithField("status", rec.Status()).
+				GetLoggerWithField("bytes", rec.bytes).
+				GetLoggerWithField("latency_ms", float64(time.Since(start).Microseconds())/1000).
+				Info("request handled")
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"fit-journal/pkg/metrics"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metrics считает время обработки и статусы ответов по шаблону маршрута.
+// Запросы без маршрута попадают в route="", чтобы сканеры путей не раздували число серий.
+func Metrics(router *httprouter.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			route := Route(router, r)
+
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+			metrics.HTTPResponses.WithLabelValues(r.Method, route, strconv.Itoa(rec.Status())).Inc()
+		})
+	}
+}
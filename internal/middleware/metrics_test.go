@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"fit-journal/pkg/client/postgresql"
+	"fit-journal/pkg/metrics"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// scrape читает metrics.Handler и возвращает значения серий по строке "имя{метки}"
+func scrape(t *testing.T) map[string]float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics: status %d", rec.Code)
+	}
+
+	series := map[string]float64{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("invalid sample %q: %v", line, err)
+		}
+		series[line[:i]] = value
+	}
+	return series
+}
+
+func TestMetrics(t *testing.T) {
+	// Пул без подключения: статистика доступна, база не нужна
+	config, err := pgxpool.ParseConfig("postgres://test@127.0.0.1:1/test?pool_max_conns=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.LazyConnect = true
+	pool, err := pgxpool.ConnectConfig(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	collector := postgresql.NewPoolCollector(pool)
+	metrics.Registry.MustRegister(collector)
+	defer metrics.Registry.Unregister(collector)
+
+	router := httprouter.New()
+	router.GET("/workouts/:workout_id", func(w http.ResponseWriter, _ *http.Request, p httprouter.Params) {
+		if p.ByName("workout_id") == "404" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	handler := Metrics(router)(router)
+
+	const (
+		ok       = `fit_journal_http_responses_total{method="GET",route="/workouts/:workout_id",status="200"}`
+		notFound = `fit_journal_http_responses_total{method="GET",route="/workouts/:workout_id",status="404"}`
+		noRoute  = `fit_journal_http_responses_total{method="GET",route="",status="404"}`
+		duration = `fit_journal_http_request_duration_seconds_count{method="GET",route="/workouts/:workout_id"}`
+	)
+	before := scrape(t)
+	for _, path := range []string{"/workouts/7", "/workouts/8", "/workouts/404", "/wp-admin/setup.php"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	after := scrape(t)
+
+	for name, want := range map[string]float64{ok: 2, notFound: 1, noRoute: 1, duration: 3} {
+		if got := after[name] - before[name]; got != want {
+			t.Errorf("%s increased by %v, want %v", name, got, want)
+		}
+	}
+	// В метках только шаблон маршрута, без идентификаторов и неизвестных путей
+	for name := range after {
+		if strings.Contains(name, "/workouts/7") || strings.Contains(name, "wp-admin") {
+			t.Errorf("series with a raw path: %s", name)
+		}
+	}
+
+	for name, want := range map[string]float64{
+		"fit_journal_pgxpool_max_conns":      4,
+		"fit_journal_pgxpool_total_conns":    0,
+		"fit_journal_pgxpool_acquires_total": 0,
+	} {
+		if got, found := after[name]; !found || got != want {
+			t.Errorf("%s = %v (found %v), want %v", name, got, found, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"fit-journal/internal/apperror"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover перехватывает панику обработчика, пишет её в лог запроса со стеком
+// и отвечает 500 в формате apperror, если заголовки ещё не отправлены.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := newResponseRecorder(w)
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			// http.ErrAbortHandler используется для намеренного обрыва соединения
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			logging.FromContext(r.Context()).
+				GetLoggerWithField("panic", fmt.Sprint(v)).
+				GetLoggerWithField("stack", string(debug.Stack())).
+				Error("handler panicked")
+			if !rec.wroteHeader {
+				apperror.Write(rec, r, apperror.NewAppError(fmt.Errorf("panic: %v", v), "internal system error", "", http.StatusInternalServerError))
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fit-journal/internal/apperror"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantStatus  int
+		wantProblem bool
+	}{
+		{"no panic", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}, http.StatusNoContent, false},
+		{"panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}, http.StatusInternalServerError, true},
+		{"panic with error", func(w http.ResponseWriter, r *http.Request) {
+			var m map[string]int
+			m["x"]++
+		}, http.StatusInternalServerError, true},
+		// Заголовки уже отправлены, второй ответ не пишется
+		{"panic after write", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		}, http.StatusAccepted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Recover(tt.handler).ServeHTTP(w, httptest.NewRequest("GET", "/workouts", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			isProblem := w.Header().Get("Content-Type") == apperror.ProblemContentType
+			if isProblem != tt.wantProblem {
+				t.Fatalf("problem response = %v, want %v", isProblem, tt.wantProblem)
+			}
+			if !tt.wantProblem {
+				return
+			}
+			var problem apperror.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			// Текст паники не раскрывается клиенту
+			if problem.Status != http.StatusInternalServerError || problem.Detail != "internal system error" || problem.DeveloperMessage != "" {
+				t.Errorf("problem = %+v", problem)
+			}
+		})
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+
+	Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	t.Error("http.ErrAbortHandler was swallowed")
+}
@@ -0,0 +1,45 @@
+// Package middleware содержит обёртки http.Handler, общие для всех маршрутов.
+package middleware
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strings"
+)
+
+// Route восстанавливает шаблон маршрута httprouter (например, /workouts/:workout_id) по пути запроса.
+// Для путей без маршрута возвращается пустая строка, чтобы не плодить значения в логах и метриках.
+func Route(router *httprouter.Router, r *http.Request) string {
+	handle, params, _ := router.Lookup(r.Method, r.URL.Path)
+	if handle == nil {
+		return ""
+	}
+	if len(params) == 0 {
+		return r.URL.Path
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	next := 0
+	for _, p := range params {
+		for i := next; i < len(segments); i++ {
+			if segments[i] == p.Value && isParam(router, r.Method, segments, i, p.Key) {
+				segments[i] = ":" + p.Key
+				next = i + 1
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// routeProbe значение, подставляемое вместо сегмента пути при проверке в isParam
+const routeProbe = "\x00route-probe"
+
+// isParam проверяет, что сегмент i пути является параметром key, а не статической частью
+// маршрута с тем же значением (например, /workouts/workouts)
+func isParam(router *httprouter.Router, method string, segments []string, i int, key string) bool {
+	probe := append([]string(nil), segments...)
+	probe[i] = routeProbe
+	_, params, _ := router.Lookup(method, strings.Join(probe, "/"))
+	return params.ByName(key) == routeProbe
+}
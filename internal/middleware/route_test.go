@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	router := httprouter.New()
+	noop := func(http.ResponseWriter, *http.Request, httprouter.Params) {}
+	for _, path := range []string{
+		"/workouts",
+		"/workouts/:workout_id",
+		"/workouts/:workout_id/exercises/:exercise_id",
+		"/workouts/:workout_id/exercises/:exercise_id/sets/:set_id",
+		"/trash/workouts/:workout_id/restore",
+	} {
+		router.GET(path, noop)
+	}
+	router.POST("/users/login", noop)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{"static", "GET", "/workouts", "/workouts"},
+		{"static post", "POST", "/users/login", "/users/login"},
+		{"one param", "GET", "/workouts/7", "/workouts/:workout_id"},
+		{"nested params", "GET", "/workouts/7/exercises/3/sets/12", "/workouts/:workout_id/exercises/:exercise_id/sets/:set_id"},
+		// Одинаковые значения параметров подставляются по порядку
+		{"equal param values", "GET", "/workouts/5/exercises/5/sets/5", "/workouts/:workout_id/exercises/:exercise_id/sets/:set_id"},
+		// Значение параметра совпадает со статическим сегментом маршрута
+		{"param equals static segment", "GET", "/workouts/workouts", "/workouts/:workout_id"},
+		{"param equals later segment", "GET", "/workouts/exercises/exercises/1", "/workouts/:workout_id/exercises/:exercise_id"},
+		{"param after static prefix", "GET", "/trash/workouts/9/restore", "/trash/workouts/:workout_id/restore"},
+		// Неизвестные пути не попадают в метки как есть
+		{"unknown path", "GET", "/wp-admin/setup.php", ""},
+		{"wrong method", "DELETE", "/workouts", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Route(router, httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+				t.Errorf("Route(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"fit-journal/pkg/tracing"
+	"github.com/julienschmidt/httprouter"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordSpans подменяет глобальный провайдер трейсов и пропагатор на время теста
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func TestTracing(t *testing.T) {
+	router := httprouter.New()
+	var handlerTraceID string
+	router.GET("/workouts/:workout_id", func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		handlerTraceID, _ = tracing.IDs(r.Context())
+		if p.ByName("workout_id") == "500" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	handler := Tracing(router)(router)
+
+	const (
+		incomingTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+		incomingSpan  = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantName    string
+		wantRoute   string
+		wantStatus  int
+		wantCode    codes.Code
+	}{
+		{"route template", "/workouts/7", "", "GET /workouts/:workout_id", "/workouts/:workout_id", http.StatusOK, codes.Unset},
+		{"server error", "/workouts/500", "", "GET /workouts/:workout_id", "/workouts/:workout_id", http.StatusInternalServerError, codes.Error},
+		// Неизвестный путь не попадает в имя спана
+		{"unknown path", "/wp-admin/setup.php", "", "GET", "", http.StatusNotFound, codes.Unset},
+		{"incoming traceparent", "/workouts/7", "00-" + incomingTrace + "-" + incomingSpan + "-01",
+			"GET /workouts/:workout_id", "/workouts/:workout_id", http.StatusOK, codes.Unset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := recordSpans(t)
+			handlerTraceID = ""
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Attributes() {
+				attrs[kv.Key] = kv.Value
+			}
+			if span.Name() != tt.wantName || span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("span = %q (%v), want %q server span", span.Name(), span.SpanKind(), tt.wantName)
+			}
+			if attrs["http.route"].AsString() != tt.wantRoute || attrs["url.path"].AsString() != tt.path ||
+				attrs["http.request.method"].AsString() != http.MethodGet {
+				t.Errorf("attributes = %v, want route %q and path %q", attrs, tt.wantRoute, tt.path)
+			}
+			if got := attrs["http.response.status_code"].AsInt64(); got != int64(tt.wantStatus) {
+				t.Errorf("http.response.status_code = %d, want %d", got, tt.wantStatus)
+			}
+			if span.Status().Code != tt.wantCode {
+				t.Errorf("status = %v, want %v", span.Status().Code, tt.wantCode)
+			}
+
+			// Ответ возвращает trace-context спана запроса
+			sc := span.SpanContext()
+			if want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String(); !strings.HasPrefix(rec.Header().Get("traceparent"), want) {
+				t.Errorf("response traceparent = %q, want prefix %q", rec.Header().Get("traceparent"), want)
+			}
+			if tt.wantRoute != "" && handlerTraceID != sc.TraceID().String() {
+				t.Errorf("handler trace id = %q, want %q", handlerTraceID, sc.TraceID())
+			}
+			if tt.traceparent != "" {
+				if sc.TraceID().String() != incomingTrace || span.Parent().SpanID().String() != incomingSpan || !span.Parent().IsRemote() {
+					t.Errorf("span %s continues %s, want the incoming trace %s/%s", sc.TraceID(), span.Parent().SpanID(), incomingTrace, incomingSpan)
+				}
+			} else if span.Parent().IsValid() {
+				t.Errorf("span has parent %v, want a root span", span.Parent())
+			}
+		})
+	}
+}
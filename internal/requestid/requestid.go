@@ -0,0 +1,47 @@
+// Package requestid присваивает запросам идентификаторы для сквозной трассировки.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header заголовок, в котором идентификатор передаётся клиентом и возвращается в ответе
+const Header = "X-Request-ID"
+
+// maxLength ограничение длины идентификатора, принятого от клиента
+const maxLength = 128
+
+type contextKey struct{}
+
+// New генерирует новый идентификатор запроса
+func New() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid проверяет, что идентификатор от клиента можно безопасно писать в логи и заголовки
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// WithContext сохраняет идентификатор запроса в контексте
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext возвращает идентификатор запроса или пустую строку
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
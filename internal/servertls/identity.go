@@ -0,0 +1,53 @@
+package servertls
+
+import (
+	"context"
+	"fit-journal/internal/apperror"
+	"fit-journal/pkg/logging"
+	"net/http"
+	"slices"
+)
+
+type contextKey struct{}
+
+// ServiceFromContext возвращает идентификатор сервиса, предъявившего клиентский сертификат,
+// или пустую строку для обычных клиентов
+func ServiceFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Identity сохраняет в контексте идентификатор сервиса из проверенного клиентского сертификата (CommonName)
+// и добавляет его в лог запроса. Если allowed не пуст, сертификаты других сервисов отклоняются с 403.
+func Identity(allowed []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			service := r.TLS.VerifiedChains[0][0].Subject.CommonName
+			if service == "" || (len(allowed) > 0 && !slices.Contains(allowed, service)) {
+				apperror.Write(w, r, apperror.NewAppError(nil, "Service is not allowed", "client certificate is not mapped to an allowed service", http.StatusForbidden))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), contextKey{}, service)
+			ctx = logging.WithContext(ctx, logging.FromContext(ctx).GetLoggerWithField("service", service))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireService пропускает к next только запросы сервисов, опознанных Identity по клиентскому сертификату.
+// Обычные клиенты, в том числе с токеном пользователя, получают 403.
+func RequireService(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ServiceFromContext(r.Context()) == "" {
+			apperror.Write(w, r, apperror.NewAppError(nil, "Service certificate required", "route is available only to services with a client certificate", http.StatusForbidden))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
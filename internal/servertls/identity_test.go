@@ -0,0 +1,80 @@
+package servertls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	chain := func(cn string) *tls.ConnectionState {
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}}}
+	}
+
+	tests := []struct {
+		name        string
+		state       *tls.ConnectionState
+		allowed     []string
+		wantStatus  int
+		wantService string
+	}{
+		{"plain http", nil, []string{"billing"}, http.StatusOK, ""},
+		{"tls without client certificate", &tls.ConnectionState{}, []string{"billing"}, http.StatusOK, ""},
+		{"any service", chain("billing"), nil, http.StatusOK, "billing"},
+		{"allowed service", chain("billing"), []string{"reports", "billing"}, http.StatusOK, "billing"},
+		{"service not allowed", chain("scanner"), []string{"billing"}, http.StatusForbidden, ""},
+		// Сертификат без CommonName не сопоставляется с сервисом
+		{"empty common name", chain(""), nil, http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotService string
+			called := false
+			h := Identity(tt.allowed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				gotService = ServiceFromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest("GET", "/workouts", nil)
+			r.TLS = tt.state
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus || gotService != tt.wantService {
+				t.Errorf("status %d, service %q; want %d, %q", w.Code, gotService, tt.wantStatus, tt.wantService)
+			}
+			if called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+func TestRequireService(t *testing.T) {
+	tests := []struct {
+		name       string
+		state      *tls.ConnectionState
+		wantStatus int
+	}{
+		{"service", &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "prometheus"}}}}}, http.StatusOK},
+		{"tls without client certificate", &tls.ConnectionState{}, http.StatusForbidden},
+		{"plain http", nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Identity(nil)(RequireService(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+			r := httptest.NewRequest("GET", "/metrics", nil)
+			r.TLS = tt.state
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package servertls настраивает TLS для HTTP-сервера: перечитывание сертификатов без перезапуска
+// и проверку клиентских сертификатов (mTLS) для межсервисных вызовов.
+package servertls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fit-journal/pkg/logging"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientAuth режимы проверки клиентского сертификата
+const (
+	ClientAuthNone     = "none"     // Сертификат не запрашивается
+	ClientAuthOptional = "optional" // Проверяется, если клиент его прислал
+	ClientAuthRequire  = "require"  // Без действительного сертификата соединение отклоняется
+)
+
+// Options пути к файлам и режим mTLS, обычно берутся из config.TLSConfig
+type Options struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string // CA для проверки клиентских сертификатов; обязателен для optional и require
+	ClientAuth   string
+}
+
+// Reloader хранит текущие сертификат и пул CA и подменяет их при изменении файлов
+type Reloader struct {
+	opts   Options
+	logger *logging.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// NewReloader загружает сертификаты; ошибка означает, что сервер запускать нельзя
+func NewReloader(opts Options, logger *logging.Logger) (*Reloader, error) {
+	switch opts.ClientAuth {
+	case "", ClientAuthNone:
+		opts.ClientAuth = ClientAuthNone
+	case ClientAuthOptional, ClientAuthRequire:
+		if opts.ClientCAFile == "" {
+			return nil, fmt.Errorf("client auth %q requires a client CA file", opts.ClientAuth)
+		}
+	default:
+		return nil, fmt.Errorf("unknown client auth mode %q", opts.ClientAuth)
+	}
+
+	r := &Reloader{opts: opts, logger: logger}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.opts.CertFile, r.opts.KeyFile}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+	return files
+}
+
+// Reload перечитывает сертификат, ключ и CA. При ошибке продолжают использоваться прежние.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("client CA file contains no certificates")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.clientCA, r.modTimes = &cert, pool, r.stat()
+	return nil
+}
+
+// stat возвращает время изменения файлов; отсутствующие файлы пропускаются
+func (r *Reloader) stat() map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, f := range r.files() {
+		if info, err := os.Stat(f); err == nil {
+			times[f] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// changed сообщает, изменился ли какой-либо файл с последней загрузки
+func (r *Reloader) changed() bool {
+	current := r.stat()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, f := range r.files() {
+		if !current[f].Equal(r.modTimes[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch проверяет файлы с заданным интервалом и перечитывает их при изменении, а также по сигналу из reload
+// (например, SIGHUP). Работает до отмены ctx.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, reload <-chan os.Signal) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !r.changed() {
+					continue
+				}
+			case <-reload:
+			}
+
+			if err := r.Reload(); err != nil {
+				r.logger.Errorf("TLS reload failed, keeping previous certificates: %v", err)
+				continue
+			}
+			r.logger.Info("TLS certificates reloaded")
+		}
+	}()
+}
+
+// TLSConfig возвращает конфигурацию сервера, которая на каждое соединение берёт текущие сертификаты.
+// HTTP/2 согласуется через ALPN.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2", "http/1.1"},
+				Certificates: []tls.Certificate{*r.cert},
+				ClientCAs:    r.clientCA,
+			}
+			switch r.opts.ClientAuth {
+			case ClientAuthOptional:
+				cfg.ClientAuth = tls.VerifyClientCertIfGiven
+			case ClientAuthRequire:
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
@@ -0,0 +1,305 @@
+package servertls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fit-journal/pkg/logging"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert сертификат с ключом; parent == nil означает самоподписанный CA
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+var serial int64
+
+func newCert(t *testing.T, cn string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+}
+
+func (c *testCert) keyPEM(t *testing.T) []byte {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(c.certPEM(), c.keyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeFile пишет файл и сдвигает время изменения, чтобы Watch заметил замену даже в пределах одной секунды
+func writeFile(t *testing.T, path string, data []byte, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// pki файлы серверного сертификата и клиентского CA во временном каталоге
+type pki struct {
+	ca   *testCert
+	opts Options
+}
+
+func newPKI(t *testing.T, clientAuth string) *pki {
+	t.Helper()
+	dir := t.TempDir()
+	p := &pki{
+		ca: newCert(t, "test-ca", nil, x509.ExtKeyUsageAny),
+		opts: Options{
+			CertFile:     filepath.Join(dir, "server.crt"),
+			KeyFile:      filepath.Join(dir, "server.key"),
+			ClientCAFile: filepath.Join(dir, "ca.crt"),
+			ClientAuth:   clientAuth,
+		},
+	}
+	p.writeServerCert(t, "server-1", time.Now().Add(-time.Minute))
+	writeFile(t, p.opts.ClientCAFile, p.ca.certPEM(), time.Now().Add(-time.Minute))
+	return p
+}
+
+func (p *pki) writeServerCert(t *testing.T, cn string, mtime time.Time) {
+	t.Helper()
+	server := newCert(t, cn, p.ca, x509.ExtKeyUsageServerAuth)
+	writeFile(t, p.opts.CertFile, server.certPEM(), mtime)
+	writeFile(t, p.opts.KeyFile, server.keyPEM(t), mtime)
+}
+
+// servedCN возвращает CommonName сертификата, который сервер отдаёт сейчас
+func servedCN(t *testing.T, r *Reloader) string {
+	t.Helper()
+	cert, err := r.TLSConfig().GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestNewReloaderOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		clientAuth string
+		noCA       bool
+		wantErr    string
+	}{
+		{"default", "", false, ""},
+		{"none without CA", ClientAuthNone, true, ""},
+		{"optional", ClientAuthOptional, false, ""},
+		{"require", ClientAuthRequire, false, ""},
+		{"require without CA", ClientAuthRequire, true, "requires a client CA file"},
+		{"unknown mode", "sometimes", false, "unknown client auth mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPKI(t, tt.clientAuth)
+			if tt.noCA {
+				p.opts.ClientCAFile = ""
+			}
+			_, err := NewReloader(p.opts, logging.GetLogger())
+			if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("NewReloader error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	p := newPKI(t, ClientAuthNone)
+	r, err := NewReloader(p.opts, logging.GetLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cn := servedCN(t, r); cn != "server-1" {
+		t.Fatalf("served %s, want server-1", cn)
+	}
+
+	p.writeServerCert(t, "server-2", time.Now())
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cn := servedCN(t, r); cn != "server-2" {
+		t.Errorf("served %s after reload, want server-2", cn)
+	}
+
+	// Повреждённые файлы не заменяют рабочий сертификат
+	writeFile(t, p.opts.KeyFile, []byte("not a key"), time.Now())
+	if err := r.Reload(); err == nil {
+		t.Error("Reload accepted a broken key")
+	}
+	writeFile(t, p.opts.ClientCAFile, []byte("not a certificate"), time.Now())
+	if err := r.Reload(); err == nil {
+		t.Error("Reload accepted a broken client CA")
+	}
+	if cn := servedCN(t, r); cn != "server-2" {
+		t.Errorf("served %s after failed reload, want server-2", cn)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	p := newPKI(t, ClientAuthNone)
+	r, err := NewReloader(p.opts, logging.GetLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	waitFor := func(cn string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for servedCN(t, r) != cn {
+			if time.Now().After(deadline) {
+				t.Fatalf("served %s, want %s", servedCN(t, r), cn)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Изменение файлов замечается по времени изменения
+	hup := make(chan os.Signal, 1)
+	r.Watch(ctx, 10*time.Millisecond, hup)
+	p.writeServerCert(t, "server-2", time.Now())
+	waitFor("server-2")
+
+	// По сигналу файлы перечитываются, даже если время изменения не сдвинулось
+	p.writeServerCert(t, "server-3", r.modTimes[p.opts.CertFile])
+	r.mu.Lock()
+	r.modTimes = r.stat()
+	r.mu.Unlock()
+	hup <- os.Interrupt
+	waitFor("server-3")
+}
+
+func TestMutualTLS(t *testing.T) {
+	tests := []struct {
+		name          string
+		clientAuth    string
+		clientCN      string // Пусто, если клиент не предъявляет сертификат
+		untrusted     bool   // Сертификат клиента выпущен чужим CA
+		allowed       []string
+		wantHandshake bool
+		wantStatus    int
+		wantService   string
+	}{
+		{"require with certificate", ClientAuthRequire, "billing", false, nil, true, http.StatusOK, "billing"},
+		{"require without certificate", ClientAuthRequire, "", false, nil, false, 0, ""},
+		{"require untrusted certificate", ClientAuthRequire, "billing", true, nil, false, 0, ""},
+		{"optional without certificate", ClientAuthOptional, "", false, nil, true, http.StatusOK, ""},
+		{"optional with certificate", ClientAuthOptional, "billing", false, nil, true, http.StatusOK, "billing"},
+		{"allowed service", ClientAuthRequire, "billing", false, []string{"billing", "reports"}, true, http.StatusOK, "billing"},
+		{"service not allowed", ClientAuthRequire, "scanner", false, []string{"billing"}, true, http.StatusForbidden, ""},
+		{"none ignores certificate", ClientAuthNone, "billing", false, nil, true, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPKI(t, tt.clientAuth)
+			r, err := NewReloader(p.opts, logging.GetLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotService string
+			srv := httptest.NewUnstartedServer(Identity(tt.allowed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotService = ServiceFromContext(r.Context())
+			})))
+			srv.TLS = r.TLSConfig()
+			srv.StartTLS()
+			defer srv.Close()
+
+			roots := x509.NewCertPool()
+			roots.AddCert(p.ca.cert)
+			clientTLS := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+			if tt.clientCN != "" {
+				issuer := p.ca
+				if tt.untrusted {
+					issuer = newCert(t, "other-ca", nil, x509.ExtKeyUsageAny)
+				}
+				clientTLS.Certificates = []tls.Certificate{newCert(t, tt.clientCN, issuer, x509.ExtKeyUsageClientAuth).tlsCertificate(t)}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+
+			resp, err := client.Get(srv.URL)
+			if !tt.wantHandshake {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("request succeeded with status %d, want handshake failure", resp.StatusCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus || gotService != tt.wantService {
+				t.Errorf("status %d, service %q; want %d, %q", resp.StatusCode, gotService, tt.wantStatus, tt.wantService)
+			}
+		})
+	}
+}
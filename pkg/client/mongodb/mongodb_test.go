@@ -0,0 +1,20 @@
+package mongodb
+
+import "testing"
+
+func TestURI(t *testing.T) {
+	cases := []struct {
+		name                                   string
+		host, port, username, password, authDB string
+		want                                   string
+	}{
+		{"without auth", "localhost", "27017", "", "", "admin", "mongodb://localhost:27017/"},
+		{"with auth", "db", "27018", "app", "p@ss:word", "admin", "mongodb://app:p%40ss%3Aword@db:27018/?authSource=admin"},
+		{"ipv6", "::1", "27017", "app", "secret", "", "mongodb://app:secret@[::1]:27017/"},
+	}
+	for _, c := range cases {
+		if got := URI(c.host, c.port, c.username, c.password, c.authDB); got != c.want {
+			t.Errorf("%s: URI() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package postgresql
+
+import (
+	"errors"
+	"github.com/jackc/pgconn"
+)
+
+// uniqueViolation код SQLSTATE нарушения ограничения UNIQUE или PRIMARY KEY
+const uniqueViolation = "23505"
+
+// UniqueViolation сообщает, что запрос нарушил ограничение уникальности
+func UniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
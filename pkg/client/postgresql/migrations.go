@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"context"
+	"embed"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/migrate"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrations миграции схемы PostgreSQL, см. пакет migrate
+var migrations = mustLoadMigrations()
+
+func mustLoadMigrations() []migrate.Migration {
+	m, err := migrate.Load(migrationFiles, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// migrationStore хранит версии применённых миграций в таблице schema_migrations
+type migrationStore struct {
+	client Client
+}
+
+func (s migrationStore) Applied(ctx context.Context) (map[int]bool, error) {
+	q := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at BIGINT NOT NULL
+		)
+	`
+	if _, err := s.client.Exec(ctx, q); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.client.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func (s migrationStore) Apply(ctx context.Context, m migrate.Migration) error {
+	tx, err := s.client.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Несколько экземпляров, запущенных одновременно, применяют миграцию по очереди и один раз
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('schema_migrations'))`); err != nil {
+		return err
+	}
+	var done bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)`, m.Version).Scan(&done); err != nil || done {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, m.SQL); err != nil {
+		return err
+	}
+	q := `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`
+	if _, err := tx.Exec(ctx, q, m.Version, m.Name, time.Now().Unix()); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Migrate применяет неприменённые миграции схемы
+func Migrate(ctx context.Context, client Client) error {
+	applied, err := migrate.Up(ctx, migrationStore{client}, migrations)
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		logging.GetLogger().Infof("Applied %d PostgreSQL migrations", applied)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+-- Исходная схема. Таблицы и колонки создаются условно: базы, созданные до появления
+-- версионированных миграций, уже содержат их полностью или частично.
+
+-- Таблица пользователей
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	username TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	birth_date TEXT,
+	height TEXT,
+	is_deleted BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- Таблица упражнений
+CREATE TABLE IF NOT EXISTS exercises (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	sets JSONB NOT NULL,  -- Массив подходов сохраняется как JSON
+	description TEXT
+);
+
+-- Таблица метрик пользователя
+CREATE TABLE IF NOT EXISTS metrics (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id),
+	weight TEXT,
+	calories_consumed TEXT,
+	day TEXT NOT NULL
+);
+
+-- Таблица тренировок
+CREATE TABLE IF NOT EXISTS workouts (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id),
+	start_time BIGINT NOT NULL,
+	kind TEXT NOT NULL DEFAULT 'strength',  -- strength или cardio
+	exercises JSONB NOT NULL,  -- Список упражнений сохраняется как JSON
+	groups JSONB NOT NULL DEFAULT '[]'::jsonb,  -- Суперсеты, круги и блоки EMOM/AMRAP
+	cardio JSONB,  -- Дистанция, время и пульс кардио-тренировки
+	deleted_at BIGINT  -- Время перемещения в корзину, NULL для активных
+);
+
+-- Запросы на полное удаление аккаунта, ожидающие окончания срока отмены
+CREATE TABLE IF NOT EXISTS deletion_requests (
+	user_id INTEGER PRIMARY KEY REFERENCES users(id),
+	requested_at BIGINT NOT NULL,
+	erase_after BIGINT NOT NULL,
+	export_path TEXT NOT NULL DEFAULT ''  -- Архив с выгрузкой данных до удаления
+);
+
+-- Журнал аудита изменений, записи только добавляются
+CREATE TABLE IF NOT EXISTS audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	at BIGINT NOT NULL,  -- Время изменения в миллисекундах Unix
+	actor TEXT NOT NULL,
+	owner_id INTEGER NOT NULL,  -- Без внешнего ключа: записи переживают удаление ресурса
+	resource TEXT NOT NULL,
+	resource_id BIGINT NOT NULL,
+	action TEXT NOT NULL,
+	changes JSONB NOT NULL DEFAULT '[]'::jsonb,
+	request_id TEXT NOT NULL DEFAULT ''
+);
+
+-- Колонки, добавленные в уже существующие таблицы
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS groups JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'strength';
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS cardio JSONB;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS deleted_at BIGINT;
+CREATE INDEX IF NOT EXISTS audit_log_owner_at_idx ON audit_log (owner_id, at);
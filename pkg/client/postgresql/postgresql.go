@@ -3,6 +3,7 @@ package postgresql
 import (
 	"context"
 	"fit-journal/internal/config"
+	"fit-journal/pkg/migrate"
 	repeatable "fit-journal/pkg/utils"
 	"fmt"
 	"github.com/jackc/pgconn"
@@ -20,7 +21,7 @@ type Client interface {
 }
 
 func NewClient(ctx context.Context, maxAttempts int, sc config.StorageConfig) (pool *pgxpool.Pool, err error) {
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", sc.Username, sc.Password, sc.Host, sc.Port, sc.Database)
+	dsn := sc.DSN()
 	err = repeatable.DoWithTries(func() error {
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
@@ -37,58 +38,51 @@ func NewClient(ctx context.Context, maxAttempts int, sc config.StorageConfig) (p
 		log.Fatal("error do with tries postgresql")
 	}
 
-	// Создание таблиц
-	if err := createTables(ctx, pool); err != nil {
+	// Создание и обновление схемы
+	if err := Migrate(ctx, pool); err != nil {
+		pool.Close()
 		return nil, err
 	}
 
 	return pool, nil
 }
 
-func createTables(ctx context.Context, pool *pgxpool.Pool) error {
-	// Таблица пользователей
-	userTable := `
-	CREATE TABLE IF NOT EXISTS users (
-		id SERIAL PRIMARY KEY,
-		username TEXT NOT NULL UNIQUE,
-		password_hash TEXT NOT NULL,
-		birth_date TEXT,
-		height TEXT
-	);`
-
-	// Таблица упражнений
-	exerciseTable := `
-	CREATE TABLE IF NOT EXISTS exercises (
-		id SERIAL PRIMARY KEY,
-		name TEXT NOT NULL,
-		sets JSONB NOT NULL,  -- Массив подходов сохраняется как JSON
-		description TEXT
-	);`
+// schemaObjects таблицы, колонки и индексы из миграций; если они есть, схема на месте
+var schemaObjects = struct {
+	relations []string
+	columns   map[string][]string
+}{
+	relations: []string{"users", "exercises", "metrics", "workouts", "deletion_requests", "audit_log", "audit_log_owner_at_idx"},
+	columns: map[string][]string{
+		"workouts": {"groups", "kind", "cardio", "deleted_at"},
+		"users":    {"is_deleted"},
+	},
+}
 
-	// Таблица метрик пользователя
-	metricTable := `
-	CREATE TABLE IF NOT EXISTS metrics (
-		id SERIAL PRIMARY KEY,
-		user_id INTEGER REFERENCES users(id),
-		weight TEXT,
-		calories_consumed TEXT,
-		day TEXT NOT NULL
-	);`
+// CheckSchema проверяет, что таблицы созданы и миграции применены
+func CheckSchema(ctx context.Context, client Client) error {
+	if err := migrate.Check(ctx, migrationStore{client}, migrations); err != nil {
+		return err
+	}
 
-	// Таблица тренировок
-	workoutTable := `
-	CREATE TABLE IF NOT EXISTS workouts (
-		id SERIAL PRIMARY KEY,
-		user_id INTEGER REFERENCES users(id),
-		start_time BIGINT NOT NULL,
-		exercises JSONB NOT NULL  -- Список упражнений сохраняется как JSON
-	);`
+	for _, name := range schemaObjects.relations {
+		var exists bool
+		if err := client.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("relation %s is missing", name)
+		}
+	}
 
-	// Выполнение SQL-запросов на создание таблиц
-	tables := []string{userTable, exerciseTable, metricTable, workoutTable}
-	for _, table := range tables {
-		if _, err := pool.Exec(ctx, table); err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
+	for table, columns := range schemaObjects.columns {
+		var found int
+		q := `SELECT count(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 AND column_name = ANY($2)`
+		if err := client.QueryRow(ctx, q, table, columns).Scan(&found); err != nil {
+			return err
+		}
+		if found != len(columns) {
+			return fmt.Errorf("table %s is missing migrated columns", table)
 		}
 	}
 
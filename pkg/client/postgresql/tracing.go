@@ -0,0 +1,151 @@
+package postgresql
+
+import (
+	"context"
+	"fit-journal/pkg/tracing"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// WithTracing оборачивает клиент так, что каждый SQL-запрос, в том числе внутри транзакции,
+// выполняется в отдельном спане с текстом запроса
+func WithTracing(client Client) Client {
+	return &tracedClient{client: client}
+}
+
+type tracedClient struct {
+	client Client
+}
+
+// startSpan открывает спан запроса; текст запроса сжимается в одну строку, как в логах репозиториев
+func startSpan(ctx context.Context, operation, sql string) (context.Context, trace.Span) {
+	statement := strings.Join(strings.Fields(sql), " ")
+	name := operation
+	if fields := strings.Fields(statement); len(fields) > 0 {
+		name = strings.ToUpper(fields[0])
+	}
+	return tracing.Tracer().Start(ctx, "db "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", statement),
+		))
+}
+
+// endSpan завершает спан, отмечая ошибку; pgx.ErrNoRows ошибкой не считается
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != pgx.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func exec(ctx context.Context, c Client, sql string, args []interface{}) (pgconn.CommandTag, error) {
+	ctx, span := startSpan(ctx, "exec", sql)
+	tag, err := c.Exec(ctx, sql, args...)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", tag.RowsAffected()))
+	}
+	endSpan(span, err)
+	return tag, err
+}
+
+func query(ctx context.Context, c Client, sql string, args []interface{}) (pgx.Rows, error) {
+	ctx, span := startSpan(ctx, "query", sql)
+	rows, err := c.Query(ctx, sql, args...)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func queryRow(ctx context.Context, c Client, sql string, args []interface{}) pgx.Row {
+	ctx, span := startSpan(ctx, "query_row", sql)
+	return &tracedRow{row: c.QueryRow(ctx, sql, args...), span: span}
+}
+
+func (c *tracedClient) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return exec(ctx, c.client, sql, args)
+}
+
+func (c *tracedClient) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return query(ctx, c.client, sql, args)
+}
+
+func (c *tracedClient) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return queryRow(ctx, c.client, sql, args)
+}
+
+func (c *tracedClient) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := c.client.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{Tx: tx}, nil
+}
+
+// tracedTx трассирует запросы внутри транзакции; остальные методы pgx.Tx работают без изменений
+type tracedTx struct {
+	pgx.Tx
+}
+
+func (t *tracedTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return exec(ctx, t.Tx, sql, args)
+}
+
+func (t *tracedTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return query(ctx, t.Tx, sql, args)
+}
+
+func (t *tracedTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return queryRow(ctx, t.Tx, sql, args)
+}
+
+func (t *tracedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{Tx: tx}, nil
+}
+
+// tracedRows завершает спан запроса при закрытии выборки, чтобы в него попало время чтения строк
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+	done bool
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	if !r.done {
+		r.done = true
+		endSpan(r.span, r.Rows.Err())
+	}
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	endSpan(r.span, err)
+	return err
+}
@@ -0,0 +1,145 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+// fakeClient отвечает на запросы без базы: Exec с текстом "fail" возвращает ошибку,
+// Query отдаёт rows строк, QueryRow — pgx.ErrNoRows
+type fakeClient struct {
+	rows int
+}
+
+var errFake = errors.New("connection reset")
+
+func (c *fakeClient) Exec(_ context.Context, sql string, _ ...interface{}) (pgconn.CommandTag, error) {
+	if sql == "fail" {
+		return nil, errFake
+	}
+	return pgconn.CommandTag("UPDATE 2"), nil
+}
+
+func (c *fakeClient) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return &fakeRows{left: c.rows}, nil
+}
+
+func (c *fakeClient) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return fakeRow{}
+}
+
+func (c *fakeClient) Begin(context.Context) (pgx.Tx, error) {
+	return &fakeTx{client: c}, nil
+}
+
+type fakeRows struct {
+	pgx.Rows
+	left int
+}
+
+func (r *fakeRows) Next() bool {
+	r.left--
+	return r.left >= 0
+}
+
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return nil }
+
+type fakeRow struct{}
+
+func (fakeRow) Scan(...interface{}) error { return pgx.ErrNoRows }
+
+type fakeTx struct {
+	pgx.Tx
+	client *fakeClient
+}
+
+func (t *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return t.client.Exec(ctx, sql, args...)
+}
+
+// recordSpans подменяет глобальный провайдер трейсов на время теста
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	result := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		result[kv.Key] = kv.Value
+	}
+	return result
+}
+
+func TestWithTracing(t *testing.T) {
+	recorder := recordSpans(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "GET /workouts")
+	client := WithTracing(&fakeClient{rows: 2})
+
+	if _, err := client.Exec(ctx, "UPDATE workouts\n\t\tSET deleted_at = $1"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := client.Query(ctx, "select id from workouts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	if err := client.QueryRow(ctx, "SELECT id FROM users").Scan(); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("Scan = %v, want pgx.ErrNoRows", err)
+	}
+	tx, err := client.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(ctx, "fail"); !errors.Is(err, errFake) {
+		t.Fatalf("Exec = %v, want %v", err, errFake)
+	}
+	parent.End()
+
+	want := []struct {
+		name, operation, statement string
+		status                     codes.Code
+	}{
+		{"db UPDATE", "exec", "UPDATE workouts SET deleted_at = $1", codes.Unset},
+		{"db SELECT", "query", "select id from workouts", codes.Unset},
+		// Пустой результат QueryRow не ошибка
+		{"db SELECT", "query_row", "SELECT id FROM users", codes.Unset},
+		{"db FAIL", "exec", "fail", codes.Error},
+	}
+	spans := recorder.Ended()
+	if len(spans) != len(want)+1 {
+		t.Fatalf("got %d spans, want %d DB spans and the parent", len(spans), len(want))
+	}
+	for i, w := range want {
+		span := spans[i]
+		attrs := attributes(span)
+		if span.Name() != w.name || attrs["db.operation"].AsString() != w.operation ||
+			attrs["db.statement"].AsString() != w.statement || attrs["db.system"].AsString() != "postgresql" {
+			t.Errorf("span %d = %q %v, want %q with operation %q and statement %q", i, span.Name(), attrs, w.name, w.operation, w.statement)
+		}
+		if span.Status().Code != w.status {
+			t.Errorf("span %q status = %v, want %v", span.Name(), span.Status().Code, w.status)
+		}
+		// Каждый запрос дочерний к спану вызывающего кода
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() || span.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+			t.Errorf("span %q is not a child of the request span", span.Name())
+		}
+	}
+	if got := attributes(spans[0])["db.rows_affected"].AsInt64(); got != 2 {
+		t.Errorf("db.rows_affected = %d, want 2", got)
+	}
+}
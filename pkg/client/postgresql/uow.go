@@ -0,0 +1,118 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"math/rand"
+	"time"
+)
+
+type txKey struct{}
+
+// WithContextTx оборачивает клиент так, что запросы с контекстом единицы работы (см. UnitOfWork)
+// выполняются в её транзакции, а остальные напрямую через client
+func WithContextTx(client Client) Client {
+	return &contextTxClient{client: client}
+}
+
+type contextTxClient struct {
+	client Client
+}
+
+func (c *contextTxClient) conn(ctx context.Context) Client {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return c.client
+}
+
+func (c *contextTxClient) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return c.conn(ctx).Exec(ctx, sql, args...)
+}
+
+func (c *contextTxClient) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.conn(ctx).Query(ctx, sql, args...)
+}
+
+func (c *contextTxClient) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.conn(ctx).QueryRow(ctx, sql, args...)
+}
+
+// Begin внутри единицы работы открывает вложенную транзакцию (SAVEPOINT)
+func (c *contextTxClient) Begin(ctx context.Context) (pgx.Tx, error) {
+	return c.conn(ctx).Begin(ctx)
+}
+
+// Коды SQLSTATE, после которых транзакцию можно повторить целиком
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// retryBaseDelay пауза перед первым повтором; удваивается с каждой попыткой
+const retryBaseDelay = 10 * time.Millisecond
+
+// UnitOfWork выполняет единицы работы в транзакциях SERIALIZABLE и повторяет их
+// при конфликтах сериализации и взаимоблокировках, реализует uow.UnitOfWork
+type UnitOfWork struct {
+	client   Client
+	attempts int
+}
+
+// NewUnitOfWork создаёт UnitOfWork поверх клиента; attempts общее число попыток, не меньше одной.
+// Репозитории должны использовать тот же клиент, обёрнутый WithContextTx.
+func NewUnitOfWork(client Client, attempts int) *UnitOfWork {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &UnitOfWork{client: client, attempts: attempts}
+}
+
+// Do выполняет fn в транзакции; внутри уже начатой единицы работы fn выполняется в ней же
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := u.run(ctx, fn)
+		if err == nil || attempt >= u.attempts || !Retryable(err) {
+			return err
+		}
+
+		// Случайная пауза разводит повторы конкурирующих транзакций
+		delay := retryBaseDelay << (attempt - 1)
+		delay += time.Duration(rand.Int63n(int64(delay)))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (u *UnitOfWork) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := u.client.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// После Commit откат ничего не делает
+	defer tx.Rollback(context.Background())
+
+	if _, err := tx.Exec(ctx, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE"); err != nil {
+		return err
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Retryable сообщает, что ошибка вызвана конфликтом сериализации или взаимоблокировкой
+// и транзакцию можно повторить
+func Retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected)
+}
@@ -0,0 +1,66 @@
+-- Исходная схема, повторяет схему PostgreSQL. JSON хранится в TEXT, время в секундах
+-- (audit_log.at в миллисекундах) Unix, флаги в INTEGER 0/1.
+
+-- Таблица пользователей
+CREATE TABLE users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	username TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	birth_date TEXT,
+	height TEXT,
+	is_deleted INTEGER NOT NULL DEFAULT 0
+);
+
+-- Таблица упражнений
+CREATE TABLE exercises (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	sets TEXT NOT NULL,  -- Массив подходов сохраняется как JSON
+	description TEXT
+);
+
+-- Таблица метрик пользователя
+CREATE TABLE metrics (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER REFERENCES users(id),
+	weight TEXT,
+	calories_consumed TEXT,
+	day TEXT NOT NULL
+);
+
+-- Таблица тренировок
+CREATE TABLE workouts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER REFERENCES users(id),
+	start_time INTEGER NOT NULL,
+	kind TEXT NOT NULL DEFAULT 'strength',  -- strength или cardio
+	exercises TEXT NOT NULL,  -- Список упражнений сохраняется как JSON
+	"groups" TEXT NOT NULL DEFAULT '[]',  -- Суперсеты, круги и блоки EMOM/AMRAP
+	cardio TEXT,  -- Дистанция, время и пульс кардио-тренировки
+	deleted_at INTEGER  -- Время перемещения в корзину, NULL для активных
+);
+
+-- Запросы на полное удаление аккаунта, ожидающие окончания срока отмены
+CREATE TABLE deletion_requests (
+	user_id INTEGER PRIMARY KEY REFERENCES users(id),
+	requested_at INTEGER NOT NULL,
+	erase_after INTEGER NOT NULL,
+	export_path TEXT NOT NULL DEFAULT ''  -- Архив с выгрузкой данных до удаления
+);
+
+-- Журнал аудита изменений, записи только добавляются
+CREATE TABLE audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	at INTEGER NOT NULL,  -- Время изменения в миллисекундах Unix
+	actor TEXT NOT NULL,
+	owner_id INTEGER NOT NULL,  -- Без внешнего ключа: записи переживают удаление ресурса
+	resource TEXT NOT NULL,
+	resource_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	changes TEXT NOT NULL DEFAULT '[]',
+	request_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX audit_log_owner_at_idx ON audit_log (owner_id, at);
+CREATE INDEX workouts_user_id_idx ON workouts (user_id);
+CREATE INDEX metrics_user_id_idx ON metrics (user_id);
@@ -0,0 +1,147 @@
+// Package sqlite клиент SQLite для установки без PostgreSQL, например на Raspberry Pi.
+// Используется драйвер modernc.org/sqlite без cgo.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fit-journal/pkg/logging"
+	"fit-journal/pkg/migrate"
+	"fmt"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Client подмножество *sql.DB, которым пользуются репозитории
+type Client interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// pragmas включают внешние ключи, журнал WAL и ожидание блокировки вместо ошибки SQLITE_BUSY
+var pragmas = []string{"foreign_keys(1)", "journal_mode(WAL)", "busy_timeout(5000)"}
+
+// NewClient открывает базу по пути, создавая каталог при необходимости, и применяет миграции
+func NewClient(ctx context.Context, path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	query := url.Values{"_pragma": pragmas}
+	db, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	// SQLite допускает одного писателя; одно соединение исключает SQLITE_BUSY внутри процесса
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// UniqueViolation сообщает, что запрос нарушил ограничение UNIQUE или PRIMARY KEY
+func UniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrations миграции схемы SQLite, см. пакет migrate
+var migrations = mustLoadMigrations()
+
+func mustLoadMigrations() []migrate.Migration {
+	m, err := migrate.Load(migrationFiles, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// migrationStore хранит версии применённых миграций в таблице schema_migrations
+type migrationStore struct {
+	client Client
+}
+
+func (s migrationStore) Applied(ctx context.Context) (map[int]bool, error) {
+	q := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)
+	`
+	if _, err := s.client.ExecContext(ctx, q); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.client.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func (s migrationStore) Apply(ctx context.Context, m migrate.Migration) error {
+	tx, err := s.client.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	q := `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, q, m.Version, m.Name, time.Now().Unix()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Migrate применяет неприменённые миграции схемы
+func Migrate(ctx context.Context, client Client) error {
+	applied, err := migrate.Up(ctx, migrationStore{client}, migrations)
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		logging.GetLogger().Infof("Applied %d SQLite migrations", applied)
+	}
+	return nil
+}
+
+// CheckSchema проверяет, что все миграции применены
+func CheckSchema(ctx context.Context, client Client) error {
+	return migrate.Check(ctx, migrationStore{client}, migrations)
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type txKey struct{}
+
+// errNestedTx BeginTx внутри единицы работы: database/sql не поддерживает вложенные транзакции
+var errNestedTx = errors.New("sqlite: nested transactions are not supported inside a unit of work")
+
+// WithContextTx оборачивает клиент так, что запросы с контекстом единицы работы (см. UnitOfWork)
+// выполняются в её транзакции, а остальные напрямую через client
+func WithContextTx(client Client) Client {
+	return &contextTxClient{client: client}
+}
+
+type contextTxClient struct {
+	client Client
+}
+
+// conn подмножество методов, общее для *sql.DB и *sql.Tx
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (c *contextTxClient) conn(ctx context.Context) conn {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return c.client
+}
+
+func (c *contextTxClient) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.conn(ctx).ExecContext(ctx, query, args...)
+}
+
+func (c *contextTxClient) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.conn(ctx).QueryContext(ctx, query, args...)
+}
+
+func (c *contextTxClient) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.conn(ctx).QueryRowContext(ctx, query, args...)
+}
+
+func (c *contextTxClient) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return nil, errNestedTx
+	}
+	return c.client.BeginTx(ctx, opts)
+}
+
+// UnitOfWork выполняет единицы работы в транзакциях SQLite, реализует uow.UnitOfWork.
+// Соединение с базой одно (см. NewClient), поэтому транзакции не конфликтуют и не повторяются;
+// пока единица работы не завершена, остальные запросы ждут соединения.
+type UnitOfWork struct {
+	client Client
+}
+
+// NewUnitOfWork создаёт UnitOfWork поверх клиента. Репозитории должны использовать тот же клиент,
+// обёрнутый WithContextTx.
+func NewUnitOfWork(client Client) *UnitOfWork {
+	return &UnitOfWork{client: client}
+}
+
+// Do выполняет fn в транзакции; внутри уже начатой единицы работы fn выполняется в ней же
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.client.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -1,12 +1,15 @@
 package logging
 
 import (
+	"context"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"io"
 	"os"
 	"path"
 	"runtime"
+	"strings"
+	"sync"
 )
 
 type WriterHook struct {
@@ -15,12 +18,12 @@ type WriterHook struct {
 }
 
 func (hook *WriterHook) Fire(entry *logrus.Entry) error {
-	line, err := entry.String()
+	line, err := entry.Bytes()
 	if err != nil {
 		return err
 	}
 	for _, w := range hook.Writer {
-		w.Write([]byte(line))
+		w.Write(line)
 	}
 	return err
 }
@@ -29,13 +32,32 @@ func (hook *WriterHook) Levels() []logrus.Level {
 	return hook.LogLevels
 }
 
-var e *logrus.Entry
+// Options настройки логгера, обычно берутся из config.LoggingConfig
+type Options struct {
+	Format   string   // json или text
+	Level    string   // trace, debug, info, warn, error, fatal, panic
+	Outputs  []string // stdout, stderr и/или file
+	FilePath string   // Путь к файлу для вывода file
+	// ErrorFilePath при непустом значении записи уровня error и выше дополнительно пишутся в этот файл
+	ErrorFilePath string
+	Rotation      RotationOptions // Ротация файлов FilePath и ErrorFilePath
+}
+
+var (
+	mu sync.RWMutex
+	e  = logrus.NewEntry(newLogger(&logrus.TextFormatter{CallerPrettyfier: callerPrettyfier}, logrus.InfoLevel, os.Stdout))
+	// closers файлы, открытые последним вызовом Init
+	closers []io.Closer
+)
 
 type Logger struct {
 	*logrus.Entry
 }
 
+// GetLogger возвращает корневой логгер. До вызова Init он пишет текст в stdout с уровнем info.
 func GetLogger() *Logger {
+	mu.RLock()
+	defer mu.RUnlock()
 	return &Logger{e}
 }
 
@@ -43,35 +65,139 @@ func (l *Logger) GetLoggerWithField(key string, value interface{}) *Logger {
 	return &Logger{l.Entry.WithField(key, value)}
 }
 
-func init() {
+func callerPrettyfier(f *runtime.Frame) (string, string) {
+	filename := path.Base(f.File)
+	return fmt.Sprintf("%s()", f.Function), fmt.Sprintf("%s:%d", filename, f.Line)
+}
+
+func newLogger(formatter logrus.Formatter, level logrus.Level, writers ...io.Writer) *logrus.Logger {
+	return newLoggerWithHooks(formatter, level, &WriterHook{
+		Writer:    writers,
+		LogLevels: logrus.AllLevels,
+	})
+}
+
+func newLoggerWithHooks(formatter logrus.Formatter, level logrus.Level, hooks ...*WriterHook) *logrus.Logger {
 	l := logrus.New()
 	l.SetReportCaller(true)
-	l.Formatter = &logrus.TextFormatter{
-		CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-			filename := path.Base(f.File)
-			return fmt.Sprintf("%s()", f.Function), fmt.Sprintf("%s:%d", filename, f.Line)
-		},
+	l.Formatter = formatter
+	l.SetOutput(io.Discard)
+	for _, hook := range hooks {
+		l.AddHook(hook)
 	}
+	l.SetLevel(level)
+	return l
+}
 
-	err := os.MkdirAll("logs", 0755)
-	if err != nil {
-		panic(err)
+// errorLevels уровни, которые пишутся в ErrorFilePath
+var errorLevels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+
+// Init настраивает корневой логгер. Логгеры, полученные до вызова, продолжают писать по старым настройкам.
+func Init(opts Options) error {
+	var formatter logrus.Formatter
+	switch strings.ToLower(opts.Format) {
+	case "", "json":
+		formatter = &logrus.JSONFormatter{CallerPrettyfier: callerPrettyfier}
+	case "text":
+		formatter = &logrus.TextFormatter{CallerPrettyfier: callerPrettyfier}
+	default:
+		return fmt.Errorf("unknown log format %q", opts.Format)
 	}
 
-	allFile, err := os.OpenFile("logs/all.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic(err)
+	level := logrus.InfoLevel
+	if opts.Level != "" {
+		var err error
+		if level, err = logrus.ParseLevel(opts.Level); err != nil {
+			return err
+		}
 	}
 
-	l.SetOutput(io.Discard)
+	outputs := opts.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{"stdout"}
+	}
+	var writers []io.Writer
+	var files []io.Closer
+	closeFiles := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	for _, output := range outputs {
+		switch strings.ToLower(strings.TrimSpace(output)) {
+		case "stdout":
+			writers = append(writers, os.Stdout)
+		case "stderr":
+			writers = append(writers, os.Stderr)
+		case "file":
+			if opts.FilePath == "" {
+				closeFiles()
+				return fmt.Errorf("log output file requires a file path")
+			}
+			file, err := NewRotatingFile(opts.FilePath, opts.Rotation)
+			if err != nil {
+				closeFiles()
+				return err
+			}
+			files = append(files, file)
+			writers = append(writers, file)
+		default:
+			closeFiles()
+			return fmt.Errorf("unknown log output %q", output)
+		}
+	}
 
-	l.AddHook(&WriterHook{
-		Writer:    []io.Writer{allFile, os.Stdout},
-		LogLevels: logrus.AllLevels,
-	})
+	hooks := []*WriterHook{{Writer: writers, LogLevels: logrus.AllLevels}}
+	if opts.ErrorFilePath != "" {
+		file, err := NewRotatingFile(opts.ErrorFilePath, opts.Rotation)
+		if err != nil {
+			closeFiles()
+			return err
+		}
+		files = append(files, file)
+		hooks = append(hooks, &WriterHook{Writer: []io.Writer{file}, LogLevels: errorLevels})
+	}
 
-	l.SetLevel(logrus.TraceLevel)
+	mu.Lock()
+	defer mu.Unlock()
+	for _, c := range closers {
+		c.Close()
+	}
+	closers = files
+	e = logrus.NewEntry(newLoggerWithHooks(formatter, level, hooks...))
+	return nil
+}
 
-	e = logrus.NewEntry(l)
+type contextKey struct{}
 
+// requestLogger логгер запроса в контексте. AddField заменяет его на месте, поэтому поля,
+// которые становятся известны позже (например, пользователь после проверки токена),
+// попадают во все следующие записи запроса, в том числе в access-лог.
+type requestLogger struct {
+	mu     sync.RWMutex
+	logger *Logger
+}
+
+// WithContext сохраняет логгер запроса в контексте
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestLogger{logger: l})
+}
+
+// FromContext возвращает логгер запроса с его полями или корневой логгер, если логгера в контексте нет
+func FromContext(ctx context.Context) *Logger {
+	if rl, ok := ctx.Value(contextKey{}).(*requestLogger); ok {
+		rl.mu.RLock()
+		defer rl.mu.RUnlock()
+		return rl.logger
+	}
+	return GetLogger()
+}
+
+// AddField добавляет поле в логгер запроса, сохранённый WithContext. Без логгера в контексте ничего не делает.
+func AddField(ctx context.Context, key string, value interface{}) {
+	if rl, ok := ctx.Value(contextKey{}).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.logger = rl.logger.GetLoggerWithField(key, value)
+	}
 }
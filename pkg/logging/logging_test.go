@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"io"
+	"testing"
+)
+
+func TestRequestLoggerFields(t *testing.T) {
+	root := &Logger{logrus.NewEntry(newLogger(&logrus.JSONFormatter{}, logrus.InfoLevel, io.Discard))}
+
+	// Без логгера в контексте возвращается корневой, AddField ничего не делает
+	AddField(context.Background(), "user", "alice")
+	if l := FromContext(context.Background()); len(l.Data) != 0 {
+		t.Fatalf("root logger has fields %v", l.Data)
+	}
+
+	ctx := WithContext(context.Background(), root.GetLoggerWithField("request_id", "req-1"))
+	// Поле, добавленное в производном контексте, видно и через исходный, как в access-логе
+	AddField(context.WithValue(ctx, "username", "alice"), "user", "alice")
+	AddField(ctx, "user_id", int64(7))
+
+	got := FromContext(ctx).Data
+	if len(got) != 3 || got["request_id"] != "req-1" || got["user"] != "alice" || got["user_id"] != int64(7) {
+		t.Errorf("request logger fields = %v", got)
+	}
+}
@@ -0,0 +1,293 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeLayout формат времени (UTC) в имени ротированного файла: all-20240304T183000.000.log
+const backupTimeLayout = "20060102T150405.000"
+
+// RotationOptions настройки ротации файла лога. Нулевые значения отключают соответствующее ограничение.
+type RotationOptions struct {
+	MaxSizeMB int           // Ротация при превышении размера
+	Interval  time.Duration // Ротация при смене интервала, например раз в сутки (24h)
+	MaxAge    time.Duration // Удалять ротированные файлы старше
+	MaxFiles  int           // Хранить не больше стольких ротированных файлов
+	Compress  bool          // Сжимать ротированные файлы gzip
+	Now       func() time.Time
+}
+
+// rotateRetryDelay через сколько повторять неудавшуюся ротацию, чтобы не пытаться при каждой записи
+const rotateRetryDelay = time.Minute
+
+// RotatingFile файл лога с ротацией по размеру и времени. Безопасен для конкурентной записи.
+// Сжатие и удаление старых файлов выполняются в фоне, чтобы не задерживать запись.
+type RotatingFile struct {
+	path string
+	opts RotationOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	closed   bool
+	size     int64
+	openedAt time.Time
+	retryAt  time.Time // До этого момента ротация не повторяется после ошибки
+
+	cleanupMu sync.Mutex     // Упорядочивает фоновые сжатие и очистку
+	cleanupWg sync.WaitGroup // Фоновые задачи, которых ждёт Close
+}
+
+// NewRotatingFile открывает файл лога, создавая каталог при необходимости
+func NewRotatingFile(path string, opts RotationOptions) (*RotatingFile, error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	f := &RotatingFile{path: path, opts: opts}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open открывает текущий файл. Для существующего файла начало интервала берётся из времени последней записи.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file, f.size, f.openedAt = file, info.Size(), f.opts.Now()
+	if info.Size() > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// Write пишет строку лога, при необходимости предварительно ротируя файл.
+// Ошибка ротации не теряет строку: она дописывается в текущий файл, а ошибка выводится в stderr.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		// Предыдущая ротация не смогла открыть файл заново
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			f.report(err)
+			if f.file == nil {
+				return 0, err
+			}
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) shouldRotate(next int64) bool {
+	if f.size == 0 || f.opts.Now().Before(f.retryAt) {
+		return false
+	}
+	if f.opts.MaxSizeMB > 0 && f.size+next > int64(f.opts.MaxSizeMB)<<20 {
+		return true
+	}
+	if f.opts.Interval > 0 && !f.opts.Now().Truncate(f.opts.Interval).Equal(f.openedAt.Truncate(f.opts.Interval)) {
+		return true
+	}
+	return false
+}
+
+// Rotate принудительно ротирует файл
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	return f.rotate()
+}
+
+// rotate переименовывает текущий файл и открывает новый. Файл открывается заново при любой ошибке,
+// чтобы запись продолжалась; после неудачи ротация повторяется не раньше чем через rotateRetryDelay.
+func (f *RotatingFile) rotate() error {
+	openedAt := f.openedAt
+	closeErr := f.file.Close()
+	f.file = nil
+
+	ext := filepath.Ext(f.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), f.opts.Now().UTC().Format(backupTimeLayout), ext)
+	var renameErr error
+	if closeErr == nil {
+		renameErr = os.Rename(f.path, backup)
+	}
+
+	if err := f.open(); err != nil {
+		return errors.Join(closeErr, renameErr, err)
+	}
+	if err := errors.Join(closeErr, renameErr); err != nil {
+		// Файл остался прежним, как и начало его интервала
+		f.openedAt = openedAt
+		f.retryAt = f.opts.Now().Add(rotateRetryDelay)
+		return err
+	}
+	f.openedAt = f.opts.Now()
+
+	f.cleanupWg.Add(1)
+	go func() {
+		defer f.cleanupWg.Done()
+		f.cleanup(backup)
+	}()
+	return nil
+}
+
+// cleanup сжимает ротированный файл и удаляет лишние старые файлы
+func (f *RotatingFile) cleanup(backup string) {
+	f.cleanupMu.Lock()
+	defer f.cleanupMu.Unlock()
+
+	if f.opts.Compress {
+		if err := compress(backup); err != nil {
+			f.report(err)
+		}
+	}
+	if err := f.removeOld(); err != nil {
+		f.report(err)
+	}
+}
+
+// report выводит ошибку ротации в stderr: записать её в сам лог может быть невозможно
+func (f *RotatingFile) report(err error) {
+	fmt.Fprintf(os.Stderr, "log rotation of %s failed: %v\n", f.path, err)
+}
+
+// compress сжимает файл в .gz и удаляет исходный. При ошибке исходный файл остаётся.
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
+
+type backupFile struct {
+	path string
+	at   time.Time
+}
+
+// backups возвращает ротированные файлы, новые первыми
+func (f *RotatingFile) backups() ([]backupFile, error) {
+	dir := filepath.Dir(f.path)
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		at, err := time.Parse(backupTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, name), at: at})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].at.After(files[j].at) })
+	return files, nil
+}
+
+// removeOld удаляет ротированные файлы сверх MaxFiles и старше MaxAge
+func (f *RotatingFile) removeOld() error {
+	if f.opts.MaxFiles <= 0 && f.opts.MaxAge <= 0 {
+		return nil
+	}
+	files, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	cutoff := f.opts.Now().Add(-f.opts.MaxAge)
+	for i, b := range files {
+		tooMany := f.opts.MaxFiles > 0 && i >= f.opts.MaxFiles
+		tooOld := f.opts.MaxAge > 0 && b.at.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close закрывает текущий файл и ждёт завершения фонового сжатия и очистки
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.cleanupWg.Wait()
+	return err
+}
@@ -0,0 +1,288 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock управляемое время для RotationOptions.Now. При step > 0 каждый вызов сдвигает время,
+// чтобы имена ротированных файлов не совпадали.
+type clock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2024, 3, 4, 18, 30, 0, 0, time.UTC)}
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func openRotating(t *testing.T, opts RotationOptions) (*RotatingFile, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "all.log")
+	f, err := NewRotatingFile(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f, path
+}
+
+func write(t *testing.T, f *RotatingFile, s string) {
+	t.Helper()
+	if _, err := f.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitCleanup дожидается фонового сжатия и удаления старых файлов
+func waitCleanup(f *RotatingFile) {
+	f.cleanupWg.Wait()
+}
+
+// readAll возвращает содержимое файла, распаковывая .gz
+func readAll(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return string(data)
+}
+
+func backupPaths(t *testing.T, f *RotatingFile) []string {
+	t.Helper()
+	files, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, b := range files {
+		paths = append(paths, b.path)
+	}
+	return paths
+}
+
+func TestRotateBySize(t *testing.T) {
+	c := newClock()
+	f, path := openRotating(t, RotationOptions{MaxSizeMB: 1, Now: c.Now})
+
+	half := strings.Repeat("a", 600<<10)
+	write(t, f, half)
+	if got := backupPaths(t, f); len(got) != 0 {
+		t.Fatalf("rotated before the limit: %v", got)
+	}
+
+	c.Advance(time.Second)
+	write(t, f, strings.Repeat("b", 600<<10))
+	waitCleanup(f)
+
+	backups := backupPaths(t, f)
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want 1", backups)
+	}
+	if want := filepath.Join(filepath.Dir(path), "all-20240304T183001.000.log"); backups[0] != want {
+		t.Errorf("backup = %s, want %s", backups[0], want)
+	}
+	if readAll(t, backups[0]) != half {
+		t.Error("backup does not contain the first write")
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 600<<10 {
+		t.Errorf("current file after rotation: %v, %v", info, err)
+	}
+}
+
+func TestRotateByInterval(t *testing.T) {
+	c := newClock()
+	f, path := openRotating(t, RotationOptions{Interval: 24 * time.Hour, Compress: true, Now: c.Now})
+
+	write(t, f, "monday\n")
+	c.Advance(time.Hour)
+	write(t, f, "still monday\n")
+	if got := backupPaths(t, f); len(got) != 0 {
+		t.Fatalf("rotated within the interval: %v", got)
+	}
+
+	c.Advance(24 * time.Hour)
+	write(t, f, "tuesday\n")
+	waitCleanup(f)
+
+	backups := backupPaths(t, f)
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".log.gz") {
+		t.Fatalf("backups = %v, want one compressed file", backups)
+	}
+	if got := readAll(t, backups[0]); got != "monday\nstill monday\n" {
+		t.Errorf("compressed backup = %q", got)
+	}
+	if got := readAll(t, path); got != "tuesday\n" {
+		t.Errorf("current file = %q", got)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxFiles int
+		maxAge   time.Duration
+		want     int
+	}{
+		{"unlimited", 0, 0, 5},
+		{"max files", 2, 0, 2},
+		// Ротации идут раз в час, последняя только что
+		{"max age", 0, 150 * time.Minute, 3},
+		{"both", 2, 150 * time.Minute, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClock()
+			f, _ := openRotating(t, RotationOptions{MaxFiles: tt.maxFiles, MaxAge: tt.maxAge, Now: c.Now})
+
+			for i := 0; i < 5; i++ {
+				write(t, f, fmt.Sprintf("line %d\n", i))
+				c.Advance(time.Hour)
+				if err := f.Rotate(); err != nil {
+					t.Fatal(err)
+				}
+				waitCleanup(f)
+			}
+
+			backups := backupPaths(t, f)
+			if len(backups) != tt.want {
+				t.Fatalf("backups = %v, want %d", backups, tt.want)
+			}
+			// Удаляются самые старые файлы
+			if got := readAll(t, backups[0]); got != "line 4\n" {
+				t.Errorf("newest backup = %q", got)
+			}
+		})
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	c := newClock()
+	c.step = time.Millisecond
+	f, path := openRotating(t, RotationOptions{MaxSizeMB: 1, Compress: true, Now: c.Now})
+
+	const writers, lines = 8, 500
+	line := strings.Repeat("x", 1000)
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				if _, err := fmt.Fprintf(f, "%d %04d %s\n", w, i, line); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	waitCleanup(f)
+
+	backups := backupPaths(t, f)
+	if len(backups) < 3 {
+		t.Fatalf("backups = %v, want at least 3 rotations", backups)
+	}
+
+	// Каждая строка записана целиком ровно один раз
+	seen := map[string]bool{}
+	for _, p := range append(backups, path) {
+		sc := bufio.NewScanner(strings.NewReader(readAll(t, p)))
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			var w, i int
+			var rest string
+			if _, err := fmt.Sscanf(sc.Text(), "%d %d %s", &w, &i, &rest); err != nil || rest != line {
+				t.Fatalf("broken line in %s: %q", p, sc.Text())
+			}
+			key := fmt.Sprint(w, i)
+			if seen[key] {
+				t.Fatalf("duplicate line %s", key)
+			}
+			seen[key] = true
+		}
+	}
+	if len(seen) != writers*lines {
+		t.Errorf("found %d lines, want %d", len(seen), writers*lines)
+	}
+}
+
+func TestRotateFailureKeepsLogging(t *testing.T) {
+	c := newClock()
+	f, path := openRotating(t, RotationOptions{Interval: time.Hour, Now: c.Now})
+	write(t, f, "before\n")
+
+	// Каталог с именем ротированного файла не даёт переименовать текущий
+	c.Advance(time.Hour)
+	blocker := filepath.Join(filepath.Dir(path), "all-"+c.Now().Format(backupTimeLayout)+".log")
+	if err := os.Mkdir(blocker, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Rotate(); err == nil {
+		t.Fatal("Rotate succeeded despite the blocked name")
+	}
+	write(t, f, "after failure\n")
+	if got := readAll(t, path); got != "before\nafter failure\n" {
+		t.Fatalf("current file = %q", got)
+	}
+
+	// После задержки ротация повторяется
+	if err := os.Remove(blocker); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(rotateRetryDelay)
+	write(t, f, "rotated\n")
+	waitCleanup(f)
+
+	backups := backupPaths(t, f)
+	if len(backups) != 1 || readAll(t, backups[0]) != "before\nafter failure\n" {
+		t.Errorf("backups = %v", backups)
+	}
+	if got := readAll(t, path); got != "rotated\n" {
+		t.Errorf("current file = %q", got)
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	f, _ := openRotating(t, RotationOptions{})
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("late\n")); err != os.ErrClosed {
+		t.Errorf("Write after Close = %v, want os.ErrClosed", err)
+	}
+}
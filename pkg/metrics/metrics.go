@@ -0,0 +1,87 @@
+// Package metrics собирает метрики приложения в формате Prometheus.
+// Все метрики регистрируются в собственном реестре Registry, а не в глобальном реестре клиента,
+// поэтому их можно проверить в тестах через Handler без внешнего сборщика.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const namespace = "fit_journal"
+
+// Источники создания тренировок для WorkoutsCreated
+const (
+	SourceAPI    = "api"
+	SourceImport = "import"
+)
+
+// Registry реестр метрик приложения
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestDuration время обработки запросов по шаблону маршрута
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// HTTPResponses количество ответов по статусу
+	HTTPResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "responses_total",
+		Help:      "HTTP responses by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	// WorkoutsCreated количество созданных тренировок по источнику (api, import)
+	WorkoutsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "workouts_created_total",
+		Help:      "Workouts created, by source.",
+	}, []string{"source"})
+
+	// SetsLogged количество добавленных подходов
+	SetsLogged = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sets_logged_total",
+		Help:      "Exercise sets logged through the API.",
+	})
+
+	// MetricsRecorded количество сохранённых метрик тела (вес, калории)
+	MetricsRecorded = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "body_metrics_recorded_total",
+		Help:      "Daily body metrics recorded through the API.",
+	})
+
+	// UsersRegistered количество регистраций
+	UsersRegistered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_registered_total",
+		Help:      "Users registered.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		HTTPResponses,
+		WorkoutsCreated,
+		SetsLogged,
+		MetricsRecorded,
+		UsersRegistered,
+	)
+}
+
+// Handler отдаёт метрики реестра в текстовом формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
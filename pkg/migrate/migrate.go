@@ -0,0 +1,106 @@
+// Package migrate применяет версионированные миграции схемы базы данных.
+//
+// Миграции хранятся по диалектам рядом с клиентами (pkg/client/postgresql/migrations,
+// pkg/client/sqlite/migrations) в файлах вида 0001_init.sql. Применённые версии
+// записываются в таблицу schema_migrations; каждая миграция выполняется в своей транзакции.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration одна миграция схемы
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Store база, к которой применяются миграции
+type Store interface {
+	// Applied создаёт таблицу schema_migrations при необходимости и возвращает применённые версии
+	Applied(ctx context.Context) (map[int]bool, error)
+	// Apply выполняет миграцию и записывает её версию в одной транзакции
+	Apply(ctx context.Context, m Migration) error
+}
+
+// Load читает миграции из каталога dir: файлы <версия>_<название>.sql, по возрастанию версии
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: file name must start with a positive version", entry.Name())
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(data)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Pending возвращает ещё не применённые миграции
+func Pending(ctx context.Context, store Store, migrations []Migration) ([]Migration, error) {
+	applied, err := store.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up применяет ещё не применённые миграции по возрастанию версии и возвращает их количество
+func Up(ctx context.Context, store Store, migrations []Migration) (int, error) {
+	pending, err := Pending(ctx, store, migrations)
+	if err != nil {
+		return 0, err
+	}
+	for i, m := range pending {
+		if err := store.Apply(ctx, m); err != nil {
+			return i, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+	return len(pending), nil
+}
+
+// Check возвращает ошибку, если есть неприменённые миграции
+func Check(ctx context.Context, store Store, migrations []Migration) error {
+	pending, err := Pending(ctx, store, migrations)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migrations pending, first %04d_%s", len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
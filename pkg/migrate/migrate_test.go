@@ -0,0 +1,63 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+type fakeStore struct {
+	applied map[int]bool
+}
+
+func (s *fakeStore) Applied(context.Context) (map[int]bool, error) {
+	return s.applied, nil
+}
+
+func (s *fakeStore) Apply(_ context.Context, m Migration) error {
+	s.applied[m.Version] = true
+	return nil
+}
+
+func TestLoadSortsByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_trash.sql": {Data: []byte("B")},
+		"m/0001_init.sql":  {Data: []byte("A")},
+		"m/README.md":      {Data: []byte("skip")},
+	}
+	migrations, err := Load(fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "init" || migrations[1].Version != 2 || migrations[1].SQL != "B" {
+		t.Fatalf("Load = %+v", migrations)
+	}
+}
+
+func TestLoadRejectsBadNames(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"no version": {"m/init.sql": {}},
+		"duplicate":  {"m/0001_a.sql": {}, "m/1_b.sql": {}},
+	} {
+		if _, err := Load(fsys, "m"); err == nil {
+			t.Errorf("%s: Load must fail", name)
+		}
+	}
+}
+
+func TestUpAppliesOnlyPending(t *testing.T) {
+	ctx := context.Background()
+	migrations := []Migration{{Version: 1, Name: "init"}, {Version: 2, Name: "trash"}}
+	store := &fakeStore{applied: map[int]bool{1: true}}
+
+	if err := Check(ctx, store, migrations); err == nil {
+		t.Fatal("Check must report pending migration 0002_trash")
+	}
+	n, err := Up(ctx, store, migrations)
+	if err != nil || n != 1 {
+		t.Fatalf("Up = %d, %v, want 1, nil", n, err)
+	}
+	if err := Check(ctx, store, migrations); err != nil {
+		t.Fatalf("Check after Up: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// restoreGlobals возвращает глобальные провайдер и пропагатор после теста
+func restoreGlobals(t *testing.T) {
+	t.Helper()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		if otel.GetTracerProvider() != provider {
+			otel.SetTracerProvider(provider)
+		}
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+func TestInitErrors(t *testing.T) {
+	restoreGlobals(t)
+	for _, opts := range []Options{{Exporter: "jaeger"}, {Exporter: "file"}} {
+		if _, err := Init(context.Background(), opts); err == nil {
+			t.Errorf("Init(%+v) succeeded, want an error", opts)
+		}
+	}
+}
+
+func TestInitNonePropagatesTraceContext(t *testing.T) {
+	restoreGlobals(t)
+	shutdown, err := Init(context.Background(), Options{Exporter: "none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	// Экспорт выключен, но входящий traceparent передаётся дальше
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	propagator := otel.GetTextMapPropagator()
+	ctx := propagator.Extract(context.Background(), propagation.MapCarrier{"traceparent": traceparent})
+	out := propagation.MapCarrier{}
+	propagator.Inject(ctx, out)
+	if out["traceparent"] != traceparent {
+		t.Errorf("traceparent = %q, want %q", out["traceparent"], traceparent)
+	}
+}
+
+func TestInitFileExporter(t *testing.T) {
+	restoreGlobals(t)
+	path := filepath.Join(t.TempDir(), "traces", "spans.json")
+	shutdown, err := Init(context.Background(), Options{Exporter: "file", FilePath: path, ServiceName: "fit-journal-test", SampleRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := Tracer().Start(context.Background(), "GET /workouts")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Name":"GET /workouts"`) || !strings.Contains(string(data), "fit-journal-test") {
+		t.Errorf("trace file does not contain the span: %s", data)
+	}
+}
+
+func TestIDs(t *testing.T) {
+	if traceID, spanID := IDs(context.Background()); traceID != "" || spanID != "" {
+		t.Errorf("IDs without a span = %q, %q, want empty", traceID, spanID)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tracetest.NewSpanRecorder()))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+	traceID, spanID := IDs(ctx)
+	if traceID != span.SpanContext().TraceID().String() || spanID != span.SpanContext().SpanID().String() {
+		t.Errorf("IDs = %q, %q, want %s, %s", traceID, spanID, span.SpanContext().TraceID(), span.SpanContext().SpanID())
+	}
+}
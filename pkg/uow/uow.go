@@ -0,0 +1,41 @@
+// Package uow объединяет несколько вызовов репозиториев в одну единицу работы.
+//
+// Репозитории не получают транзакцию явно: реализация UnitOfWork кладёт её в контекст,
+// и клиент хранилища выполняет запросы с этим контекстом внутри неё
+// (см. postgresql.WithContextTx и sqlite.WithContextTx). Поэтому внутри fn все вызовы
+// репозиториев должны получать контекст, переданный в fn.
+package uow
+
+import (
+	"context"
+	"sync"
+)
+
+// UnitOfWork выполняет несколько вызовов репозиториев атомарно
+type UnitOfWork interface {
+	// Do выполняет fn в одной транзакции: ошибка fn откатывает её и возвращается без изменений.
+	// fn может быть вызвана повторно после конфликта, поэтому не должна писать ответ или
+	// менять что-либо вне хранилища. Вложенный Do присоединяется к внешней единице работы.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Serial возвращает UnitOfWork для хранилищ без транзакций (память процесса, MongoDB без набора реплик):
+// единицы работы выполняются по одной в пределах процесса, изменения при ошибке не откатываются
+func Serial() UnitOfWork {
+	return &serial{}
+}
+
+type serial struct {
+	mu sync.Mutex
+}
+
+type serialKey struct{}
+
+func (s *serial) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(serialKey{}) == s {
+		return fn(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(context.WithValue(ctx, serialKey{}, s))
+}
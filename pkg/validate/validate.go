@@ -0,0 +1,265 @@
+// Package validate проверяет значения по правилам из тега validate полей структуры.
+//
+// Правила перечисляются через запятую:
+//
+//	required   значение не пустое: строка без одних пробелов, ненулевое число, непустой срез, не nil
+//	min=N      число не меньше N; для строки с правилом number — её числовое значение,
+//	           для остальных строк — длина в символах, для срезов — количество элементов
+//	max=N      то же ограничение сверху
+//	oneof=a b  значение равно одному из перечисленных через пробел
+//	number     строка содержит конечное число
+//	date       строка содержит дату в формате 2006-01-02
+//	past       дата не позже сегодняшнего дня
+//
+// Числа NaN и ±Inf не проходят min и max. Пустые необязательные строки и nil пропускают все правила,
+// кроме required. Вложенные структуры, срезы и указатели проверяются рекурсивно, а поле в ошибке
+// записывается по JSON-именам: sets[0].reps.
+package validate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DateLayout формат дат для правила date
+const DateLayout = "2006-01-02"
+
+// FieldError нарушение правила в отдельном поле
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Errors нарушения во всех полях значения в порядке обхода
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, f := range e {
+		parts[i] = f.Field + " " + f.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// now текущее время для правила past, подменяется в тестах
+var now = time.Now
+
+// Struct проверяет v (структуру, срез или указатель на них) и возвращает Errors со всеми нарушениями или nil
+func Struct(v interface{}) error {
+	var errs Errors
+	walk(reflect.ValueOf(v), "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// walk обходит значение и добавляет в errs нарушения правил его полей
+func walk(v reflect.Value, path string, errs *Errors) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walk(v.Elem(), path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := fieldName(f)
+			if !ok {
+				continue
+			}
+			if f.Anonymous && name == "" {
+				// Поля встроенной структуры без JSON-имени находятся на уровне внешней
+				walk(v.Field(i), path, errs)
+				continue
+			}
+			fieldPath := joinPath(path, name)
+			if tag := f.Tag.Get("validate"); tag != "" {
+				if msg := check(v.Field(i), tag); msg != "" {
+					*errs = append(*errs, FieldError{Field: fieldPath, Message: msg})
+					continue
+				}
+			}
+			walk(v.Field(i), fieldPath, errs)
+		}
+	}
+}
+
+// fieldName возвращает JSON-имя поля; false для неэкспортируемых и пропускаемых JSON полей
+func fieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch {
+	case name == "-":
+		return "", false
+	case name != "":
+		return name, true
+	case f.Anonymous:
+		return "", true
+	}
+	return f.Name, true
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// check применяет правила tag к значению и возвращает сообщение о первом нарушении или пустую строку
+func check(v reflect.Value, tag string) string {
+	rules := strings.Split(tag, ",")
+	numeric := false
+	for _, rule := range rules {
+		if rule == "number" {
+			numeric = true
+		}
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if hasRule(rules, "required") {
+				return "is required"
+			}
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			if v.IsZero() || (v.Kind() == reflect.String && strings.TrimSpace(v.String()) == "") ||
+				(v.Kind() == reflect.Slice && v.Len() == 0) {
+				return "is required"
+			}
+			continue
+		}
+		if v.Kind() == reflect.String && v.String() == "" {
+			// Пустая необязательная строка: остальные правила не применяются
+			return ""
+		}
+		if msg := apply(v, name, arg, numeric); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// apply проверяет одно правило, кроме required
+func apply(v reflect.Value, name, arg string, numeric bool) string {
+	switch name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid %s=%q", name, arg))
+		}
+		return bound(v, name, limit, numeric)
+	case "oneof":
+		value := fmt.Sprint(v.Interface())
+		options := strings.Fields(arg)
+		for _, option := range options {
+			if value == option {
+				return ""
+			}
+		}
+		return "must be one of: " + strings.Join(options, ", ")
+	case "number":
+		if _, ok := parseNumber(v.String()); !ok {
+			return "must be a number"
+		}
+	case "date":
+		if _, err := time.Parse(DateLayout, v.String()); err != nil {
+			return "must be a date in YYYY-MM-DD format"
+		}
+	case "past":
+		date, err := time.Parse(DateLayout, v.String())
+		if err != nil {
+			return "must be a date in YYYY-MM-DD format"
+		}
+		y, m, d := now().Date()
+		if date.After(time.Date(y, m, d, 0, 0, 0, 0, time.UTC)) {
+			return "must not be in the future"
+		}
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", name))
+	}
+	return ""
+}
+
+// bound проверяет правило min или max
+func bound(v reflect.Value, name string, limit float64, numeric bool) string {
+	var value float64
+	var unit string
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return "must be a finite number"
+		}
+	case reflect.String:
+		if numeric {
+			n, ok := parseNumber(v.String())
+			if !ok {
+				return "must be a number"
+			}
+			value = n
+		} else {
+			value, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		value, unit = float64(v.Len()), " items"
+	default:
+		panic(fmt.Sprintf("validate: %s is not supported for %s", name, v.Kind()))
+	}
+
+	limitText := strconv.FormatFloat(limit, 'f', -1, 64)
+	if name == "min" && value < limit {
+		if unit != "" {
+			return "must contain at least " + limitText + unit
+		}
+		return "must be at least " + limitText
+	}
+	if name == "max" && value > limit {
+		if unit != "" {
+			return "must contain at most " + limitText + unit
+		}
+		return "must be at most " + limitText
+	}
+	return ""
+}
+
+// parseNumber разбирает строку как конечное число
+func parseNumber(s string) (float64, bool) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
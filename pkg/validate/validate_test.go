@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type set struct {
+	Reps   int     `json:"reps" validate:"min=0"`
+	Weight float64 `json:"weight" validate:"min=0"`
+}
+
+type exercise struct {
+	Name string `json:"name" validate:"required,max=5"`
+	Sets []set  `json:"sets"`
+}
+
+type profile struct {
+	BirthDate string    `json:"birth_date" validate:"date,past"`
+	Height    string    `json:"height,omitempty" validate:"number,min=1,max=300"`
+	Kind      string    `json:"kind" validate:"oneof=strength cardio"`
+	Exercise  *exercise `json:"exercise"`
+	Hidden    string    `json:"-" validate:"required"`
+}
+
+func TestStructReportsAllViolations(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	err := Struct(&profile{
+		BirthDate: "2024-05-02",
+		Height:    "tall",
+		Kind:      "yoga",
+		Exercise:  &exercise{Name: "Bench press", Sets: []set{{Reps: 5, Weight: 60}, {Reps: -1, Weight: math.NaN()}}},
+	})
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Struct = %v, want Errors", err)
+	}
+	want := Errors{
+		{"birth_date", "must not be in the future"},
+		{"height", "must be a number"},
+		{"kind", "must be one of: strength, cardio"},
+		{"exercise.name", "must contain at most 5 characters"},
+		{"exercise.sets[1].reps", "must be at least 0"},
+		{"exercise.sets[1].weight", "must be a finite number"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Struct = %v, want %v", errs, want)
+	}
+	for i := range want {
+		if errs[i] != want[i] {
+			t.Errorf("violation %d = %+v, want %+v", i, errs[i], want[i])
+		}
+	}
+}
+
+func TestStructSkipsEmptyOptionalFields(t *testing.T) {
+	if err := Struct(profile{Kind: "cardio"}); err != nil {
+		t.Fatalf("empty optional fields must pass, got %v", err)
+	}
+	if err := Struct(profile{Height: "180.5", BirthDate: "1990-05-01"}); err != nil {
+		t.Fatalf("valid values must pass, got %v", err)
+	}
+	if err := Struct([]exercise{{Name: "Squat"}, {Name: " "}}); err == nil || err.Error() != "[1].name is required" {
+		t.Fatalf("Struct = %v, want [1].name is required", err)
+	}
+}